@@ -0,0 +1,136 @@
+package wikilink
+
+import (
+	"bytes"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AssetSet collects the files, like images and PDFs,
+// that wikilinks rendered by a Renderer link to or embed.
+// Use it in build pipelines to copy exactly the referenced files
+// into the output directory.
+//
+//	assets := new(wikilink.AssetSet)
+//	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+//		Assets: assets,
+//	}))
+//	// ...convert all documents...
+//	for _, a := range assets.Assets() {
+//		copyFile(filepath.Join(vault, a.Target), filepath.Join(out, a.Destination))
+//	}
+//
+// A wikilink is to an asset if its target has an extension other than
+// ".md", and it resolved to a destination.
+// Links to URLs, unresolved links, and private links aren't collected.
+//
+// An AssetSet is safe for concurrent use.
+// The zero value is an empty set ready to use.
+type AssetSet struct {
+	mu     sync.Mutex
+	assets map[string]*Asset // by destination
+}
+
+// Asset is a file referenced by wikilinks.
+type Asset struct {
+	// Target of the first wikilink that referenced the asset.
+	Target string `json:"target"`
+
+	// Destination of the asset, without its query or fragment.
+	Destination string `json:"destination"`
+
+	// Embed reports whether any of the wikilinks was an embed.
+	Embed bool `json:"embed,omitempty"`
+
+	// Sources are the names of the documents that referenced the asset,
+	// as set with SetContextSource, in lexical order.
+	Sources []string `json:"sources,omitempty"`
+}
+
+// isAsset reports whether n is a wikilink to an asset
+// rather than to another page.
+func isAsset(n *Node) bool {
+	ext := path.Ext(string(n.Target))
+	return len(ext) > 0 && !strings.EqualFold(ext, ".md")
+}
+
+// assetBaseURL returns the entry in AssetBaseURLs
+// for the extension of the target of n.
+func (r *Renderer) assetBaseURL(n *Node) (string, bool) {
+	ext := path.Ext(string(n.Target))
+	if len(ext) == 0 {
+		return "", false
+	}
+	base, ok := r.AssetBaseURLs[strings.ToLower(ext)]
+	return base, ok && len(base) > 0
+}
+
+// joinBaseURL returns dest placed under base,
+// treating dest as relative to the root of the site.
+//
+//	joinBaseURL("https://cdn.example.com/", "/img/a.png")
+//	// => "https://cdn.example.com/img/a.png"
+func joinBaseURL(base string, dest []byte) []byte {
+	p, rest := dest, []byte(nil)
+	if idx := bytes.IndexAny(dest, "?#"); idx >= 0 {
+		p, rest = dest[:idx], dest[idx:]
+	}
+	p = []byte(strings.TrimPrefix(path.Clean("/"+string(p)), "/"))
+
+	out := make([]byte, 0, len(base)+1+len(p)+len(rest))
+	out = append(out, strings.TrimSuffix(base, "/")...)
+	out = append(out, '/')
+	out = append(out, p...)
+	return append(out, rest...)
+}
+
+func (s *AssetSet) add(n *Node, dest []byte) {
+	d := string(dest)
+	if idx := strings.IndexAny(d, "?#"); idx >= 0 {
+		d = d[:idx]
+	}
+	if len(d) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.assets == nil {
+		s.assets = make(map[string]*Asset)
+	}
+	a, ok := s.assets[d]
+	if !ok {
+		a = &Asset{Target: string(n.Target), Destination: d}
+		s.assets[d] = a
+	}
+	a.Embed = a.Embed || n.Embed
+	if len(n.source) > 0 {
+		idx := sort.SearchStrings(a.Sources, n.source)
+		if idx == len(a.Sources) || a.Sources[idx] != n.source {
+			a.Sources = append(a.Sources, "")
+			copy(a.Sources[idx+1:], a.Sources[idx:])
+			a.Sources[idx] = n.source
+		}
+	}
+}
+
+// Assets returns a copy of the assets collected so far,
+// sorted by destination.
+func (s *AssetSet) Assets() []Asset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	assets := make([]Asset, 0, len(s.assets))
+	for _, a := range s.assets {
+		c := *a
+		c.Sources = append([]string(nil), a.Sources...)
+		assets = append(assets, c)
+	}
+	sort.Slice(assets, func(i, j int) bool {
+		return assets[i].Destination < assets[j].Destination
+	})
+	return assets
+}
@@ -0,0 +1,186 @@
+package wikilink
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+)
+
+func TestAssetSet(t *testing.T) {
+	t.Parallel()
+
+	assets := new(AssetSet)
+	assert.Empty(t, assets.Assets())
+
+	md := goldmark.New(goldmark.WithExtensions(&Extender{
+		Assets:         assets,
+		FragmentPrefix: "p-",
+	}))
+
+	docs := []struct {
+		source string
+		body   string
+	}{
+		{
+			source: "b.md",
+			body: "![[photo.png]] [[report.pdf#page=2]] [[Notes]] [[Notes.md]] " +
+				"[[https://example.com/x.png]]",
+		},
+		{
+			source: "a.md",
+			body:   "[[photo.png]] [[photo.png]] [[data/table.csv]]",
+		},
+	}
+	for _, doc := range docs {
+		ctx := parser.NewContext()
+		SetContextSource(ctx, doc.source)
+		require.NoError(t, md.Convert([]byte(doc.body), new(bytes.Buffer), parser.WithContext(ctx)))
+	}
+
+	assert.Equal(t, []Asset{
+		{Target: "data/table.csv", Destination: "data/table.csv", Sources: []string{"a.md"}},
+		{Target: "photo.png", Destination: "photo.png", Embed: true, Sources: []string{"a.md", "b.md"}},
+		{Target: "report.pdf", Destination: "report.pdf", Sources: []string{"b.md"}},
+	}, assets.Assets())
+}
+
+func TestAssetSet_Unresolved(t *testing.T) {
+	t.Parallel()
+
+	assets := new(AssetSet)
+	md := goldmark.New(goldmark.WithExtensions(&Extender{
+		Assets: assets,
+		Resolver: detailedResolverFunc(func(n *Node) (Resolution, error) {
+			switch string(n.Target) {
+			case "missing.png":
+				return Resolution{Destination: []byte("upload?f=missing.png"), Missing: true}, nil
+			case "private.png":
+				return Resolution{Destination: []byte("private.png"), Private: true}, nil
+			}
+			return Resolution{}, nil
+		}),
+	}))
+	require.NoError(t, md.Convert([]byte("![[missing.png]] ![[private.png]] ![[none.png]]"), new(bytes.Buffer)))
+	assert.Empty(t, assets.Assets())
+}
+
+func TestRenderer_AssetBaseURLs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give string
+		want string
+	}{
+		{
+			desc: "embed",
+			give: "![[img/Cat.PNG]]",
+			want: `<p><img src="https://cdn.example.com/img/Cat.PNG"></p>`,
+		},
+		{
+			desc: "link with fragment",
+			give: "[[docs/report.pdf#page=2]]",
+			want: `<p><a href="https://files.example.com/assets/docs/report.pdf#page=2">docs/report.pdf#page=2</a></p>`,
+		},
+		{
+			desc: "page",
+			give: "[[Notes]]",
+			want: `<p><a href="/Notes/">Notes</a></p>`,
+		},
+		{
+			desc: "other extension",
+			give: "[[data.csv]]",
+			want: `<p><a href="/data.csv">data.csv</a></p>`,
+		},
+		{
+			desc: "url",
+			give: "[[https://example.com/cat.png]]",
+			want: `<p><a href="https://example.com/cat.png">https://example.com/cat.png</a></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assets := new(AssetSet)
+			md := goldmark.New(goldmark.WithExtensions(&Extender{
+				Resolver: NewRootResolver("/"),
+				Assets:   assets,
+				AssetBaseURLs: map[string]string{
+					".png": "https://cdn.example.com",
+					".pdf": "https://files.example.com/assets/",
+				},
+			}))
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want, strings.TrimSpace(buf.String()))
+			for _, a := range assets.Assets() {
+				assert.NotContains(t, a.Destination, "example.com", "assets must record local destinations")
+			}
+		})
+	}
+}
+
+func TestJoinBaseURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		base string
+		dest string
+		want string
+	}{
+		{base: "https://cdn.example.com/", dest: "/img/a.png", want: "https://cdn.example.com/img/a.png"},
+		{base: "https://cdn.example.com", dest: "img/a.png", want: "https://cdn.example.com/img/a.png"},
+		{base: "https://cdn.example.com/v1/", dest: "./img/../a.png?w=100#x", want: "https://cdn.example.com/v1/a.png?w=100#x"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.dest, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, string(joinBaseURL(tt.base, []byte(tt.dest))))
+		})
+	}
+}
+
+func TestRenderer_AssetBaseURLs_embedHandlers(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"snip.go":               {Data: []byte("package snip\n")},
+		"sketch.excalidraw.md":  {},
+		"sketch.excalidraw.png": {},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(&Extender{
+		Resolver: NewRootResolver("/"),
+		AssetBaseURLs: map[string]string{
+			".go":  "https://cdn.example.com",
+			".md":  "https://cdn.example.com",
+			".mp4": "https://cdn.example.com",
+		},
+		EmbedHandlers: map[string]EmbedHandler{
+			".go":            &CodeEmbed{FS: files},
+			".excalidraw.md": &ExcalidrawEmbed{FS: files},
+		},
+	}))
+
+	var buf bytes.Buffer
+	give := "![[snip.go]]\n\n![[sketch.excalidraw.md]]\n\n![[clip.mp4]]"
+	require.NoError(t, md.Convert([]byte(give), &buf))
+	assert.Equal(t, `<pre><code class="language-go">package snip
+</code></pre>
+<p><img src="https://cdn.example.com/sketch.excalidraw.png" alt="sketch"></p>
+<p><video controls src="https://cdn.example.com/clip.mp4"><a href="https://cdn.example.com/clip.mp4">clip.mp4</a></video></p>`,
+		strings.TrimSpace(buf.String()))
+}
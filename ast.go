@@ -1,7 +1,10 @@
 package wikilink
 
 import (
+	"context"
+
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
 )
 
 // Kind is the kind of the wikilink AST node.
@@ -36,12 +39,73 @@ type Node struct {
 	// after the "#".
 	Fragment []byte
 
+	// Query portion of the link, if any.
+	//
+	// For links in the form, [[search?tag=golang]], this is the portion
+	// after the "?". This is only parsed if Parser.AllowQuery is set.
+	Query []byte
+
 	// Whether this link starts with a bang (!).
 	//
 	//	![[foo.png]]
 	//
 	// This indicates that the resource should be embedded (e.g. images).
 	Embed bool
+
+	// LinkType is the type of a typed link, if any.
+	//
+	// For links in the form, "supports:: [[Claim]]",
+	// following the Foam and Juggl convention,
+	// this is the key before the "::".
+	// The key is left in the document as written.
+	LinkType []byte
+
+	// Whether this link was written as a hashtag, like #golang,
+	// and parsed by HashtagParser.
+	// The target of such links is the tag with HashtagParser.Prefix.
+	Hashtag bool
+
+	// resolver overrides the Renderer's Resolver for this node.
+	// This is set from the parser.Context by SetContextResolver.
+	resolver Resolver
+
+	// site is the site-wide value from the parser.Context.
+	site any
+
+	// source is the name of the document containing this node,
+	// as set by SetContextSource.
+	source string
+
+	// ctx is the context for resolving this node,
+	// as set by SetResolveContext.
+	ctx context.Context
+
+	// segment is the span of the wikilink in the source,
+	// from the opening "[[" or "![[" to the closing "]]".
+	segment text.Segment
+
+	// targetSegment is the span of the target in the source,
+	// without the fragment or query and with escapes intact.
+	// This is empty if the target spans multiple lines.
+	targetSegment text.Segment
+
+	// trace collects the steps taken to resolve this node
+	// if the Renderer's Trace option is set.
+	// It's shared with copies made by withTarget.
+	trace *resolveTrace
+
+	// metrics is the Renderer's Metrics, if any,
+	// for resolvers to count cache hits while resolving this node.
+	metrics *Metrics
+
+	// closer is the closing tag, if any, that the Renderer must add
+	// when it exits this node. This is </a> for nodes that had a
+	// destination when they were resolved.
+	closer string
+
+	// embedURL, if set, rewrites a local destination into the URL
+	// written to the page while an EmbedHandler renders this node.
+	embedURL func(dest []byte) []byte
 }
 
 var _ ast.Node = (*Node)(nil)
@@ -51,6 +115,84 @@ func (n *Node) Kind() ast.NodeKind {
 	return Kind
 }
 
+// Site returns the site-wide value that was in effect when this node was
+// parsed, or nil if there wasn't one.
+//
+// This is set from Parser.Site or SetSiteContext.
+// Use it to access site configuration from inside a Resolver.
+func (n *Node) Site() any {
+	return n.site
+}
+
+// Source returns the name of the document containing this node,
+// or an empty string if it's unknown.
+//
+// This is set with SetContextSource.
+func (n *Node) Source() string {
+	return n.source
+}
+
+// Context returns the context.Context that was in effect when this node
+// was parsed, or context.Background if there wasn't one.
+//
+// This is set with SetResolveContext.
+func (n *Node) Context() context.Context {
+	if n.ctx == nil {
+		return context.Background()
+	}
+	return n.ctx
+}
+
+// Segment returns the span of this wikilink in the source it was parsed
+// from, from the opening "[[" or "![[" to the closing "]]".
+// Use it with Raw in tools that rewrite documents,
+// like formatters, to copy links they don't change as-is.
+//
+// This is empty for nodes that weren't parsed by Parser.
+func (n *Node) Segment() text.Segment {
+	return n.segment
+}
+
+// Raw returns the text of this wikilink in src, exactly as written,
+// including escapes, spacing, and line breaks.
+//
+//	![[Foo\|bar#Baz | qux]]
+//
+// src must be the source that the node was parsed from.
+// Raw returns nil for nodes that weren't parsed by Parser.
+func (n *Node) Raw(src []byte) []byte {
+	if n.segment.IsEmpty() {
+		return nil
+	}
+	return n.segment.Value(src)
+}
+
+// EmbedURL returns the URL that the page should use to refer to the file
+// at dest, a destination passed to EmbedHandler.RenderEmbed
+// or a path derived from it, like the exported copy of a drawing.
+// It applies Renderer.AssetBaseURLs and Renderer.RelativeDestinations
+// the same way as they're applied to links.
+//
+//	// With AssetBaseURLs: {".png": "https://cdn.example.com"}
+//	n.EmbedURL([]byte("/img/cat.png")) // => "https://cdn.example.com/img/cat.png"
+//
+// EmbedURL returns dest as-is outside of RenderEmbed.
+func (n *Node) EmbedURL(dest []byte) []byte {
+	if n.embedURL == nil {
+		return dest
+	}
+	return n.embedURL(dest)
+}
+
+// withTarget returns a shallow copy of this node with a different target.
+// Resolvers that translate targets before delegating to another resolver
+// use this to avoid modifying the original node.
+func (n *Node) withTarget(target []byte) *Node {
+	c := *n
+	c.Target = target
+	return &c
+}
+
 // Dump dumps the Node to stdout.
 func (n *Node) Dump(src []byte, level int) {
 	ast.DumpHelper(n, src, level, map[string]string{
@@ -5,7 +5,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/text"
 )
@@ -38,3 +40,66 @@ func TestNodeDump(t *testing.T) {
 	}, "\n")
 	require.Equal(t, want, string(got), "dump output mismatch")
 }
+
+func TestNodeRaw(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give string
+	}{
+		{desc: "simple", give: "[[Foo]]"},
+		{desc: "embed", give: "![[cat.png|A cat]]"},
+		{desc: "spacing", give: "[[ Foo  bar # Baz |  qux ]]"},
+		{desc: "escapes", give: `[[C\# notes\|x#a\]b|l\|m]]`},
+		{desc: "multiple lines", give: "[[a page\nwith a long name|and\na label]]"},
+		{desc: "percent", give: "[[My%20Page]]"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			// Surround the wikilink with text and put it in a
+			// paragraph so that offsets aren't zero.
+			src := []byte("Before " + tt.give + " after.\n")
+			md := goldmark.New(goldmark.WithExtensions(&Extender{
+				AllowSoftLineBreaks: true,
+				DecodeTargets:       true,
+			}))
+			doc := md.Parser().Parse(text.NewReader(src))
+
+			var nodes []*Node
+			_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+				if wl, ok := n.(*Node); ok && entering {
+					nodes = append(nodes, wl)
+				}
+				return ast.WalkContinue, nil
+			})
+			require.Len(t, nodes, 1)
+
+			n := nodes[0]
+			assert.Equal(t, tt.give, string(n.Raw(src)), "raw")
+			assert.Equal(t, len("Before "), n.Segment().Start, "start")
+
+			// Writing the document back with the raw link
+			// must reproduce it byte-for-byte.
+			seg := n.Segment()
+			var out []byte
+			out = append(out, src[:seg.Start]...)
+			out = append(out, n.Raw(src)...)
+			out = append(out, src[seg.Stop:]...)
+			assert.Equal(t, string(src), string(out), "round trip")
+		})
+	}
+
+	t.Run("not parsed", func(t *testing.T) {
+		t.Parallel()
+
+		n := ParseTarget("[[Foo]]")
+		require.NotNil(t, n)
+		assert.Nil(t, n.Raw([]byte("[[Foo]]")))
+		assert.Zero(t, n.Segment())
+	})
+}
@@ -0,0 +1,69 @@
+package wikilink_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	wikilink "github.com/kentxxq/goldmark-wikilink"
+	"github.com/yuin/goldmark"
+)
+
+// linkHeavyDocument builds a document with the provided number of
+// wikilinks in a mix of forms.
+func linkHeavyDocument(links int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < links; i += 4 {
+		fmt.Fprintf(&buf, "See [[Page %d]], [[Page %d#Section|the section]], ", i, i)
+		fmt.Fprintf(&buf, "[[notes/数据 %d]], and ![[image %d.png]].\n\n", i, i)
+	}
+	return buf.Bytes()
+}
+
+func BenchmarkConvert(b *testing.B) {
+	for _, links := range []int{100, 1000, 10000} {
+		src := linkHeavyDocument(links)
+		b.Run(fmt.Sprintf("links=%d", links), func(b *testing.B) {
+			md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{}))
+
+			b.ReportAllocs()
+			b.SetBytes(int64(len(src)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := md.Convert(src, io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkResolvers(b *testing.B) {
+	resolvers := []struct {
+		name     string
+		resolver wikilink.Resolver
+	}{
+		{"Default", wikilink.DefaultResolver},
+		{"Pretty", wikilink.PrettyResolver},
+		{"Rel", wikilink.RelResolver},
+		{"Root", wikilink.RootResolver("/docs/")},
+		{"Slug", &wikilink.SlugResolver{}},
+		{"Cached", wikilink.NewCachedResolver(1024, &wikilink.SlugResolver{})},
+	}
+
+	n := &wikilink.Node{
+		Target:   []byte("Notes/Getting Started"),
+		Fragment: []byte("Installing the CLI"),
+	}
+	for _, r := range resolvers {
+		b.Run(r.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := r.resolver.ResolveWikilink(n); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
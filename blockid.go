@@ -0,0 +1,125 @@
+package wikilink
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// BlockIDTransformer is a goldmark AST transformer that finds Obsidian
+// block IDs (" ^id" at the end of a block) and turns them into id
+// attributes on the HTML element for the block.
+// This gives wikilinks to blocks, like [[page#^id]], a place to land.
+//
+//	A paragraph with an ID. ^intro
+//	// => <p id="^intro">A paragraph with an ID.</p>
+//
+// Block IDs on a line of their own after a list, quote, or other block
+// apply to that block.
+//
+//	> A quote.
+//
+//	^quote
+//	// => <blockquote id="^quote">...</blockquote>
+//
+// IDs include the "^" so that they match the fragments of wikilinks
+// to them as-is.
+//
+// Install it on your goldmark Markdown object with the BlockIDs option of
+// Extender, or directly on a goldmark Parser with WithASTTransformers.
+//
+//	goldmarkParser.AddOptions(parser.WithASTTransformers(
+//		util.Prioritized(&wikilink.BlockIDTransformer{}, 100),
+//	))
+type BlockIDTransformer struct {
+	// Prefix is added to the start of every id attribute.
+	// Set this to the same value as Renderer.FragmentPrefix.
+	Prefix string
+}
+
+var _ parser.ASTTransformer = (*BlockIDTransformer)(nil)
+
+// _blockID matches a block ID at the end of a line of text.
+var _blockID = regexp.MustCompile(`(?:^|[ \t])\^([A-Za-z0-9-]+)[ \t]*$`)
+
+var _id = []byte("id")
+
+// Transform finds block IDs in the document and moves them into
+// id attributes.
+func (t *BlockIDTransformer) Transform(doc *ast.Document, reader text.Reader, _ parser.Context) {
+	src := reader.Source()
+
+	// Collect the blocks first because we may remove nodes.
+	var blocks []ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case ast.KindParagraph, ast.KindTextBlock, ast.KindHeading:
+			blocks = append(blocks, n)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, block := range blocks {
+		t.transformBlock(block, src)
+	}
+}
+
+func (t *BlockIDTransformer) transformBlock(block ast.Node, src []byte) {
+	last, ok := block.LastChild().(*ast.Text)
+	if !ok {
+		return
+	}
+
+	m := _blockID.FindSubmatchIndex(last.Segment.Value(src))
+	if m == nil {
+		return
+	}
+	id := make([]byte, 0, len(t.Prefix)+1+m[3]-m[2])
+	id = append(id, t.Prefix...)
+	id = append(id, '^')
+	id = append(id, last.Segment.Value(src)[m[2]:m[3]]...)
+
+	value := bytes.TrimRight(last.Segment.Value(src)[:m[0]], " \t")
+	if len(value) == 0 && block.ChildCount() == 1 {
+		// A block ID on its own applies to the block before it.
+		prev := block.PreviousSibling()
+		if prev == nil {
+			return
+		}
+		block.Parent().RemoveChild(block.Parent(), block)
+		prev.SetAttribute(_id, id)
+		return
+	}
+
+	// Drop the marker from the text,
+	// and the text entirely if that's all it had.
+	if len(value) > 0 {
+		last.Segment = last.Segment.WithStop(last.Segment.Start + len(value))
+	} else {
+		block.RemoveChild(block, last)
+		if prev, ok := block.LastChild().(*ast.Text); ok {
+			// Text.SetSoftLineBreak can't clear the flag,
+			// so replace the text with a copy without a line break.
+			t := ast.NewTextSegment(prev.Segment)
+			t.SetRaw(prev.IsRaw())
+			block.ReplaceChild(block, prev, t)
+		}
+	}
+
+	target := block
+	if block.Kind() == ast.KindTextBlock {
+		// Text blocks, like the contents of tight list items,
+		// don't have an element of their own.
+		if item := block.Parent(); item != nil && item.Kind() == ast.KindListItem {
+			target = item
+		}
+	}
+	target.SetAttribute(_id, id)
+}
@@ -0,0 +1,90 @@
+package wikilink_test
+
+import (
+	"bytes"
+	"testing"
+
+	wikilink "github.com/kentxxq/goldmark-wikilink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestBlockIDTransformer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc   string
+		prefix string
+		give   string
+		want   string
+	}{
+		{
+			desc: "paragraph",
+			give: "A paragraph with an ID. ^intro\n",
+			want: `<p id="^intro">A paragraph with an ID.</p>` + "\n",
+		},
+		{
+			desc: "no space",
+			give: "Not an ID.^intro\n",
+			want: "<p>Not an ID.^intro</p>\n",
+		},
+		{
+			desc: "next line",
+			give: "First line\n^intro\n",
+			want: `<p id="^intro">First line</p>` + "\n",
+		},
+		{
+			desc: "heading",
+			give: "## Title ^top\n",
+			want: `<h2 id="^top">Title</h2>` + "\n",
+		},
+		{
+			desc: "tight list item",
+			give: "- foo ^a\n- bar\n",
+			want: "<ul>\n" + `<li id="^a">foo</li>` + "\n<li>bar</li>\n</ul>\n",
+		},
+		{
+			desc: "after list",
+			give: "- foo\n- bar\n\n^list\n",
+			want: `<ul id="^list">` + "\n<li>foo</li>\n<li>bar</li>\n</ul>\n",
+		},
+		{
+			desc: "after quote",
+			give: "> quote\n\n^q\n",
+			want: `<blockquote id="^q"><p>quote</p>` + "\n</blockquote>\n",
+		},
+		{
+			desc: "alone",
+			give: "^lonely\n",
+			want: "<p>^lonely</p>\n",
+		},
+		{
+			desc:   "prefix",
+			prefix: "user-content-",
+			give:   "Text ^id\n",
+			want:   `<p id="user-content-^id">Text</p>` + "\n",
+		},
+		{
+			desc: "link to block",
+			give: "See [[page#^intro]] ^see\n",
+			want: `<p id="^see">See <a href="page.html#%5Eintro">page#^intro</a></p>` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+				BlockIDs:       true,
+				FragmentPrefix: tt.prefix,
+			}))
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package wikilink
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/util"
+)
+
+// Canvas is a board in the JSON Canvas format used by Obsidian.
+// See https://jsoncanvas.org/ for the specification.
+type Canvas struct {
+	Nodes []CanvasNode `json:"nodes"`
+	Edges []CanvasEdge `json:"edges"`
+}
+
+// CanvasNode is a single node on a Canvas.
+type CanvasNode struct {
+	ID   string `json:"id"`
+	Type string `json:"type"` // text, file, link, or group
+
+	// Position and size of the node in pixels.
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+
+	Color string `json:"color,omitempty"`
+
+	// Text is the Markdown content of text nodes.
+	Text string `json:"text,omitempty"`
+
+	// File is the path of the file in file nodes,
+	// and Subpath is a heading or block in it, starting with "#".
+	File    string `json:"file,omitempty"`
+	Subpath string `json:"subpath,omitempty"`
+
+	// URL is the address of link nodes.
+	URL string `json:"url,omitempty"`
+
+	// Label is the name of group nodes.
+	Label string `json:"label,omitempty"`
+}
+
+// CanvasEdge is a connection between two nodes on a Canvas.
+type CanvasEdge struct {
+	ID       string `json:"id"`
+	FromNode string `json:"fromNode"`
+	FromSide string `json:"fromSide,omitempty"`
+	ToNode   string `json:"toNode"`
+	ToSide   string `json:"toSide,omitempty"`
+	Color    string `json:"color,omitempty"`
+	Label    string `json:"label,omitempty"`
+}
+
+// CanvasEmbed is an EmbedHandler for Obsidian canvas files (.canvas).
+//
+//	&wikilink.Extender{
+//		EmbedHandlers: map[string]wikilink.EmbedHandler{
+//			".canvas": &wikilink.CanvasEmbed{FS: os.DirFS("vault")},
+//		},
+//	}
+//
+// By default, it renders the nodes of the canvas as absolutely positioned
+// <div>s inside a container.
+//
+//	<div class="wikilink-canvas" style="width:400px;height:300px">
+//	  <div class="wikilink-canvas-node wikilink-canvas-text" style="left:0px;top:0px;width:200px;height:100px">Hello</div>
+//	  ...
+//	</div>
+//
+// Text nodes hold their Markdown source as plain text.
+// Edges are not rendered.
+type CanvasEmbed struct {
+	// FS holds the canvas files.
+	//
+	// Files are opened at the destination returned by the Resolver,
+	// without its query, fragment, or leading "/".
+	FS fs.FS
+
+	// Resolver determines destinations for file nodes.
+	//
+	// Defaults to DefaultResolver if unspecified.
+	Resolver Resolver
+
+	// Render, if set, renders the canvas instead of the default
+	// representation. Use this to render a placeholder, or to hand the
+	// canvas to client-side code.
+	Render func(w util.BufWriter, n *Node, c *Canvas) error
+}
+
+var _ BlockEmbedHandler = (*CanvasEmbed)(nil)
+
+// RenderEmbed reads the canvas file for n and renders it.
+func (e *CanvasEmbed) RenderEmbed(w util.BufWriter, _ []byte, n *Node, dest []byte) error {
+	name := embedPath(dest)
+	if !fs.ValidPath(name) {
+		return fmt.Errorf("invalid canvas path %q", name)
+	}
+
+	b, err := fs.ReadFile(e.FS, name)
+	if err != nil {
+		return err
+	}
+
+	var c Canvas
+	if err := json.Unmarshal(b, &c); err != nil {
+		return fmt.Errorf("parse canvas %q: %w", name, err)
+	}
+
+	if e.Render != nil {
+		return e.Render(w, n, &c)
+	}
+	return e.render(w, n, &c)
+}
+
+// BlockEmbed reports that canvases are rendered as blocks,
+// including by a custom Render function.
+func (e *CanvasEmbed) BlockEmbed([]byte, *Node) bool {
+	return true
+}
+
+func (e *CanvasEmbed) render(w util.BufWriter, n *Node, c *Canvas) error {
+	// Canvas coordinates may be negative.
+	// Shift everything so that the top-left node is at 0, 0.
+	var minX, minY, maxX, maxY int
+	for i, node := range c.Nodes {
+		if i == 0 || node.X < minX {
+			minX = node.X
+		}
+		if i == 0 || node.Y < minY {
+			minY = node.Y
+		}
+		if i == 0 || node.X+node.Width > maxX {
+			maxX = node.X + node.Width
+		}
+		if i == 0 || node.Y+node.Height > maxY {
+			maxY = node.Y + node.Height
+		}
+	}
+
+	_, _ = w.WriteString(`<div class="wikilink-canvas" style="width:`)
+	_, _ = w.WriteString(strconv.Itoa(maxX - minX))
+	_, _ = w.WriteString(`px;height:`)
+	_, _ = w.WriteString(strconv.Itoa(maxY - minY))
+	_, _ = w.WriteString(`px">`)
+
+	for _, node := range c.Nodes {
+		_, _ = w.WriteString(`<div class="wikilink-canvas-node wikilink-canvas-`)
+		_, _ = w.Write(util.EscapeHTML([]byte(node.Type)))
+		_, _ = fmt.Fprintf(w, `" style="left:%dpx;top:%dpx;width:%dpx;height:%dpx"`,
+			node.X-minX, node.Y-minY, node.Width, node.Height)
+		if len(node.Color) > 0 {
+			_, _ = w.WriteString(` data-color="`)
+			_, _ = w.Write(util.EscapeHTML([]byte(node.Color)))
+			_, _ = w.WriteString(`"`)
+		}
+		_, _ = w.WriteString(`>`)
+		if err := e.renderNode(w, n, node); err != nil {
+			return err
+		}
+		_, _ = w.WriteString(`</div>`)
+	}
+
+	_, _ = w.WriteString(`</div>`)
+	return nil
+}
+
+// renderNode renders a single node of the canvas embedded by n.
+func (e *CanvasEmbed) renderNode(w util.BufWriter, n *Node, node CanvasNode) error {
+	switch node.Type {
+	case "text":
+		_, _ = w.Write(util.EscapeHTML([]byte(node.Text)))
+
+	case "group":
+		_, _ = w.Write(util.EscapeHTML([]byte(node.Label)))
+
+	case "link":
+		if !hasURLScheme([]byte(node.URL), DefaultURLSchemes) {
+			_, _ = w.Write(util.EscapeHTML([]byte(node.URL)))
+			break
+		}
+		writeLink(w, []byte(node.URL), node.URL)
+
+	case "file":
+		resolver := e.Resolver
+		if resolver == nil {
+			resolver = DefaultResolver
+		}
+		// Resolve files as if they were linked from the document
+		// that embeds the canvas.
+		res, err := ResolveDetails(resolver, &Node{
+			Target:   []byte(node.File),
+			Fragment: []byte(strings.TrimPrefix(node.Subpath, "#")),
+			site:     n.site,
+			source:   n.source,
+			ctx:      n.ctx,
+		})
+		if err != nil {
+			return fmt.Errorf("resolve canvas file %q: %w", node.File, err)
+		}
+		if len(res.Destination) == 0 || res.Private {
+			_, _ = w.Write(util.EscapeHTML([]byte(node.File)))
+			break
+		}
+		writeLink(w, res.Destination, node.File)
+	}
+	return nil
+}
+
+// writeLink writes a link to dest with the given text.
+func writeLink(w util.BufWriter, dest []byte, label string) {
+	_, _ = w.WriteString(`<a href="`)
+	_, _ = w.Write(util.EscapeHTML(util.URLEscape(dest, true /* resolve references */)))
+	_, _ = w.WriteString(`">`)
+	_, _ = w.Write(util.EscapeHTML([]byte(label)))
+	_, _ = w.WriteString(`</a>`)
+}
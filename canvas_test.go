@@ -0,0 +1,115 @@
+package wikilink
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/util"
+)
+
+const _testCanvas = `{
+	"nodes": [
+		{"id": "1", "type": "text", "x": -100, "y": -50, "width": 200, "height": 100, "text": "# Hello <world>"},
+		{"id": "2", "type": "file", "x": 150, "y": 0, "width": 100, "height": 50, "file": "notes/Foo.md", "subpath": "#Bar", "color": "2"},
+		{"id": "3", "type": "link", "x": 0, "y": 100, "width": 100, "height": 50, "url": "https://example.com"},
+		{"id": "4", "type": "link", "x": 0, "y": 200, "width": 100, "height": 50, "url": "javascript:alert(1)"},
+		{"id": "5", "type": "group", "x": -100, "y": 300, "width": 50, "height": 50, "label": "Group"}
+	],
+	"edges": [
+		{"id": "e1", "fromNode": "1", "fromSide": "right", "toNode": "2", "label": "see"}
+	]
+}`
+
+func TestCanvasEmbed(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"board.canvas":  {Data: []byte(_testCanvas)},
+		"broken.canvas": {Data: []byte(`{"nodes": [`)},
+	}
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		md := goldmark.New(goldmark.WithExtensions(&Extender{
+			EmbedHandlers: map[string]EmbedHandler{
+				".canvas": &CanvasEmbed{FS: files, Resolver: PrettyResolver},
+			},
+		}))
+
+		var buf bytes.Buffer
+		require.NoError(t, md.Convert([]byte("![[board.canvas]]"), &buf))
+		assert.Equal(t, `<div class="wikilink-canvas" style="width:350px;height:400px">`+
+			`<div class="wikilink-canvas-node wikilink-canvas-text" style="left:0px;top:0px;width:200px;height:100px"># Hello &lt;world&gt;</div>`+
+			`<div class="wikilink-canvas-node wikilink-canvas-file" style="left:250px;top:50px;width:100px;height:50px" data-color="2"><a href="notes/Foo.md#Bar">notes/Foo.md</a></div>`+
+			`<div class="wikilink-canvas-node wikilink-canvas-link" style="left:100px;top:150px;width:100px;height:50px"><a href="https://example.com">https://example.com</a></div>`+
+			`<div class="wikilink-canvas-node wikilink-canvas-link" style="left:100px;top:250px;width:100px;height:50px">javascript:alert(1)</div>`+
+			`<div class="wikilink-canvas-node wikilink-canvas-group" style="left:0px;top:350px;width:50px;height:50px">Group</div>`+
+			`</div>`,
+			strings.TrimSpace(buf.String()))
+	})
+
+	t.Run("private file", func(t *testing.T) {
+		t.Parallel()
+
+		md := goldmark.New(goldmark.WithExtensions(&Extender{
+			EmbedHandlers: map[string]EmbedHandler{
+				".canvas": &CanvasEmbed{FS: files, Resolver: privateResolver},
+			},
+		}))
+
+		var buf bytes.Buffer
+		require.NoError(t, md.Convert([]byte("![[board.canvas]]"), &buf))
+		assert.Contains(t, buf.String(), `data-color="2">notes/Foo.md</div>`)
+		assert.NotContains(t, buf.String(), "secret")
+	})
+
+	t.Run("custom render", func(t *testing.T) {
+		t.Parallel()
+
+		var got *Canvas
+		md := goldmark.New(goldmark.WithExtensions(&Extender{
+			EmbedHandlers: map[string]EmbedHandler{
+				".canvas": &CanvasEmbed{
+					FS: files,
+					Render: func(w util.BufWriter, n *Node, c *Canvas) error {
+						got = c
+						_, _ = w.WriteString(`<div data-canvas="`)
+						_, _ = w.Write(n.Target)
+						_, _ = w.WriteString(`"></div>`)
+						return nil
+					},
+				},
+			},
+		}))
+
+		var buf bytes.Buffer
+		require.NoError(t, md.Convert([]byte("![[board.canvas]]"), &buf))
+		assert.Equal(t, `<div data-canvas="board.canvas"></div>`, strings.TrimSpace(buf.String()))
+
+		require.NotNil(t, got)
+		assert.Len(t, got.Nodes, 5)
+		assert.Equal(t, []CanvasEdge{
+			{ID: "e1", FromNode: "1", FromSide: "right", ToNode: "2", Label: "see"},
+		}, got.Edges)
+	})
+
+	t.Run("broken", func(t *testing.T) {
+		t.Parallel()
+
+		md := goldmark.New(goldmark.WithExtensions(&Extender{
+			EmbedHandlers: map[string]EmbedHandler{
+				".canvas": &CanvasEmbed{FS: files},
+			},
+		}))
+
+		err := md.Convert([]byte("![[broken.canvas]]"), new(bytes.Buffer))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "parse canvas")
+	})
+}
@@ -0,0 +1,217 @@
+package wikilink
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark/util"
+)
+
+// DefaultCodeLanguages maps the extensions of common source code files
+// to the names of their languages, as used in the info strings of
+// fenced code blocks.
+//
+// Use it to install CodeEmbed for these files.
+//
+//	code := &wikilink.CodeEmbed{FS: os.DirFS("notes")}
+//	for ext := range wikilink.DefaultCodeLanguages {
+//		handlers[ext] = code
+//	}
+var DefaultCodeLanguages = map[string]string{
+	".c":     "c",
+	".cpp":   "cpp",
+	".cs":    "csharp",
+	".css":   "css",
+	".go":    "go",
+	".h":     "c",
+	".hs":    "haskell",
+	".java":  "java",
+	".js":    "javascript",
+	".kt":    "kotlin",
+	".lua":   "lua",
+	".php":   "php",
+	".py":    "python",
+	".rb":    "ruby",
+	".rs":    "rust",
+	".sh":    "bash",
+	".sql":   "sql",
+	".swift": "swift",
+	".ts":    "typescript",
+}
+
+// CodeEmbed is an EmbedHandler that reads embedded source code files
+// and renders their contents as code blocks.
+//
+//	![[snippet.go]]
+//	// => <pre><code class="language-go">package main...</code></pre>
+//
+// Install it for the extensions of the files to embed.
+//
+//	&wikilink.Extender{
+//		EmbedHandlers: map[string]wikilink.EmbedHandler{
+//			".go": &wikilink.CodeEmbed{FS: os.DirFS("notes")},
+//		},
+//	}
+//
+// Use a fragment of the form "L10-L20" or "L10" to embed only those lines
+// of the file, as with GitHub permalinks.
+// Ranges past the end of the file are cut short; other fragments are
+// ignored.
+//
+//	![[snippet.go#L3-L5]]
+//
+// By default, the code is rendered the same way as fenced code blocks,
+// with a "language-*" class for client-side highlighters.
+// Set Highlight to highlight it when rendering,
+// e.g. with Chroma like goldmark-highlighting does.
+type CodeEmbed struct {
+	// FS holds the source code files.
+	//
+	// Files are opened at the destination returned by the Resolver,
+	// without its query, fragment, or leading "/".
+	FS fs.FS
+
+	// Languages maps file extensions to language names.
+	// The language of files with other extensions is their extension
+	// without the leading ".".
+	//
+	// Defaults to DefaultCodeLanguages.
+	Languages map[string]string
+
+	// Highlight, if set, renders the code instead of the default
+	// <pre><code> block.
+	//
+	//	Highlight: func(w util.BufWriter, lang string, code []byte) error {
+	//		return quick.Highlight(w, string(code), lang, "html", "github")
+	//	},
+	Highlight func(w util.BufWriter, lang string, code []byte) error
+}
+
+var _ BlockEmbedHandler = (*CodeEmbed)(nil)
+
+// RenderEmbed writes the contents of the source code file for n
+// as a code block.
+func (e *CodeEmbed) RenderEmbed(w util.BufWriter, _ []byte, n *Node, dest []byte) error {
+	name := embedPath(dest)
+	if !fs.ValidPath(name) {
+		return fmt.Errorf("invalid code file path %q", name)
+	}
+
+	code, err := fs.ReadFile(e.FS, name)
+	if err != nil {
+		return err
+	}
+
+	if start, end, ok := parseLineRange(n.Fragment); ok {
+		code = sliceLines(code, start, end)
+	}
+
+	lang := e.language(name)
+	if e.Highlight != nil {
+		return e.Highlight(w, lang, code)
+	}
+	writeCodeBlock(w, lang, code)
+	return nil
+}
+
+// BlockEmbed reports that code is rendered as a code block.
+func (e *CodeEmbed) BlockEmbed([]byte, *Node) bool {
+	return true
+}
+
+// language returns the language of the file with the given name.
+func (e *CodeEmbed) language(name string) string {
+	langs := e.Languages
+	if langs == nil {
+		langs = DefaultCodeLanguages
+	}
+
+	ext := strings.ToLower(path.Ext(name))
+	if lang, ok := langs[ext]; ok {
+		return lang
+	}
+	return strings.TrimPrefix(ext, ".")
+}
+
+// parseLineRange parses a fragment of the form "L10-L20" or "L10"
+// into a 1-indexed, inclusive range of lines.
+// The second "L" is optional.
+func parseLineRange(fragment []byte) (start, end int, ok bool) {
+	if len(fragment) < 2 || fragment[0] != 'L' {
+		return 0, 0, false
+	}
+
+	first, rest := fragment[1:], []byte(nil)
+	if idx := bytes.IndexByte(first, '-'); idx >= 0 {
+		first, rest = first[:idx], first[idx+1:]
+	}
+
+	start, ok = parseLineNumber(first)
+	if !ok {
+		return 0, 0, false
+	}
+	if rest == nil {
+		return start, start, true
+	}
+
+	if len(rest) > 0 && rest[0] == 'L' {
+		rest = rest[1:]
+	}
+	end, ok = parseLineNumber(rest)
+	if !ok || end < start {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// parseLineNumber parses a positive decimal line number.
+func parseLineNumber(b []byte) (int, bool) {
+	n, err := strconv.Atoi(string(b))
+	if err != nil || n < 1 || b[0] == '+' {
+		return 0, false
+	}
+	return n, true
+}
+
+// sliceLines returns lines start through end of code,
+// 1-indexed and inclusive, with their trailing newlines.
+func sliceLines(code []byte, start, end int) []byte {
+	var from, line int
+	for i := 0; i <= len(code); i++ {
+		if i < len(code) && code[i] != '\n' {
+			continue
+		}
+		line++
+		switch line {
+		case start - 1:
+			from = i + 1
+		case end:
+			if i < len(code) {
+				i++ // include the newline
+			}
+			return code[from:i]
+		}
+	}
+	if line < start {
+		return nil
+	}
+	return code[from:]
+}
+
+// writeCodeBlock writes code as a <pre><code> block
+// the same way goldmark renders fenced code blocks.
+func writeCodeBlock(w util.BufWriter, lang string, code []byte) {
+	_, _ = w.WriteString(`<pre><code`)
+	if len(lang) > 0 {
+		_, _ = w.WriteString(` class="language-`)
+		_, _ = w.Write(util.EscapeHTML([]byte(lang)))
+		_ = w.WriteByte('"')
+	}
+	_ = w.WriteByte('>')
+	_, _ = w.Write(util.EscapeHTML(code))
+	_, _ = w.WriteString("</code></pre>")
+}
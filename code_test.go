@@ -0,0 +1,195 @@
+package wikilink
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/util"
+)
+
+func TestCodeEmbed(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"snippet.go":   {Data: []byte("package main\n\nfunc main() { println(\"<hi>\") }\n")},
+		"lib/util.py":  {Data: []byte("def f(a, b):\n    return a < b\n")},
+		"config.nix":   {Data: []byte("{ }\n")},
+		"Makefile.TXT": {Data: []byte("all:\n")},
+	}
+
+	tests := []struct {
+		desc string
+		give string
+		code *CodeEmbed
+		want string
+	}{
+		{
+			desc: "go",
+			give: "![[snippet.go]]",
+			want: `<pre><code class="language-go">package main
+
+func main() { println(&quot;&lt;hi&gt;&quot;) }
+</code></pre>`,
+		},
+		{
+			desc: "nested",
+			give: "![[/lib/util.py#f]]",
+			want: `<pre><code class="language-python">def f(a, b):
+    return a &lt; b
+</code></pre>`,
+		},
+		{
+			desc: "line range",
+			give: "![[snippet.go#L1-L2]]",
+			want: `<pre><code class="language-go">package main
+
+</code></pre>`,
+		},
+		{
+			desc: "single line",
+			give: "![[snippet.go#L3]]",
+			want: `<pre><code class="language-go">func main() { println(&quot;&lt;hi&gt;&quot;) }
+</code></pre>`,
+		},
+		{
+			desc: "unknown extension",
+			give: "![[config.nix]]",
+			want: `<pre><code class="language-nix">{ }
+</code></pre>`,
+		},
+		{
+			desc: "custom languages",
+			give: "![[Makefile.TXT]]",
+			code: &CodeEmbed{Languages: map[string]string{".txt": "text"}},
+			want: `<pre><code class="language-text">all:
+</code></pre>`,
+		},
+		{
+			desc: "highlight",
+			give: "![[snippet.go]]",
+			code: &CodeEmbed{
+				Highlight: func(w util.BufWriter, lang string, code []byte) error {
+					_, err := fmt.Fprintf(w, `<div class="highlight" data-lang="%s">%d lines</div>`,
+						lang, bytes.Count(code, []byte("\n")))
+					return err
+				},
+			},
+			want: `<div class="highlight" data-lang="go">3 lines</div>`,
+		},
+		{
+			desc: "link",
+			give: "[[snippet.go]]",
+			want: `<p><a href="snippet.go">snippet.go</a></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			code := tt.code
+			if code == nil {
+				code = &CodeEmbed{}
+			}
+			code.FS = files
+
+			handlers := make(map[string]EmbedHandler)
+			for _, ext := range []string{".go", ".py", ".nix", ".txt"} {
+				handlers[ext] = code
+			}
+			md := goldmark.New(goldmark.WithExtensions(&Extender{
+				EmbedHandlers: handlers,
+			}))
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want, strings.TrimSpace(buf.String()))
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+
+		md := goldmark.New(goldmark.WithExtensions(&Extender{
+			EmbedHandlers: map[string]EmbedHandler{
+				".go": &CodeEmbed{FS: files},
+			},
+		}))
+		err := md.Convert([]byte("![[missing.go]]"), new(bytes.Buffer))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing.go")
+	})
+}
+
+func TestParseLineRange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give      string
+		wantStart int
+		wantEnd   int
+		wantOK    bool
+	}{
+		{give: "L10-L20", wantStart: 10, wantEnd: 20, wantOK: true},
+		{give: "L10-20", wantStart: 10, wantEnd: 20, wantOK: true},
+		{give: "L7", wantStart: 7, wantEnd: 7, wantOK: true},
+		{give: "L3-L3", wantStart: 3, wantEnd: 3, wantOK: true},
+		{give: ""},
+		{give: "L"},
+		{give: "Heading"},
+		{give: "L0"},
+		{give: "L+1"},
+		{give: "L-1"},
+		{give: "L5-L2"},
+		{give: "L5-"},
+		{give: "L5-Lx"},
+		{give: "l5"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			start, end, ok := parseLineRange([]byte(tt.give))
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantStart, start)
+			assert.Equal(t, tt.wantEnd, end)
+		})
+	}
+}
+
+func TestSliceLines(t *testing.T) {
+	t.Parallel()
+
+	code := []byte("one\ntwo\nthree\nfour")
+
+	tests := []struct {
+		desc       string
+		start, end int
+		want       string
+	}{
+		{desc: "first", start: 1, end: 1, want: "one\n"},
+		{desc: "middle", start: 2, end: 3, want: "two\nthree\n"},
+		{desc: "last", start: 4, end: 4, want: "four"},
+		{desc: "all", start: 1, end: 4, want: string(code)},
+		{desc: "past end", start: 3, end: 10, want: "three\nfour"},
+		{desc: "out of range", start: 5, end: 6, want: ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, string(sliceLines(code, tt.start, tt.end)))
+		})
+	}
+}
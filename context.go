@@ -0,0 +1,104 @@
+package wikilink
+
+import (
+	"context"
+
+	"github.com/yuin/goldmark/parser"
+)
+
+var (
+	_resolverKey = parser.NewContextKey()
+	_siteKey     = parser.NewContextKey()
+	_sourceKey   = parser.NewContextKey()
+	_ctxKey      = parser.NewContextKey()
+)
+
+// SetContextResolver overrides the Resolver for a single document.
+// Use it with a goldmark parser.Context passed to Convert.
+//
+//	ctx := parser.NewContext()
+//	wikilink.SetContextResolver(ctx, frenchResolver)
+//	md.Convert(src, &buf, parser.WithContext(ctx))
+//
+// Wikilinks parsed with this context will be resolved with the provided
+// Resolver instead of the Renderer's.
+func SetContextResolver(pc parser.Context, r Resolver) {
+	pc.Set(_resolverKey, r)
+}
+
+// ContextResolver returns the Resolver set on the provided parser.Context
+// with SetContextResolver, or nil if there isn't one.
+func ContextResolver(pc parser.Context) Resolver {
+	if pc == nil {
+		return nil
+	}
+	r, _ := pc.Get(_resolverKey).(Resolver)
+	return r
+}
+
+// SetSiteContext stores an arbitrary site-wide value, such as the site's
+// configuration or a registry of its pages, on the provided parser.Context.
+// This overrides Parser.Site for a single document.
+//
+// Wikilinks parsed with this context carry the value.
+// Resolvers and other hooks may retrieve it with Node.Site.
+func SetSiteContext(pc parser.Context, site any) {
+	pc.Set(_siteKey, site)
+}
+
+// SiteContext returns the site-wide value stored on the provided
+// parser.Context, or nil if there isn't one.
+//
+// The Parser stores Parser.Site on the context when it parses a document
+// if the context doesn't already have a value.
+func SiteContext(pc parser.Context) any {
+	if pc == nil {
+		return nil
+	}
+	return pc.Get(_siteKey)
+}
+
+// SetContextSource records the name of the document being parsed with the
+// provided parser.Context, usually its path.
+//
+// Wikilinks parsed with this context remember it
+// so that it can be included in reports like LinkReport.
+func SetContextSource(pc parser.Context, source string) {
+	pc.Set(_sourceKey, source)
+}
+
+// ContextSource returns the document name set on the provided
+// parser.Context with SetContextSource, or an empty string.
+func ContextSource(pc parser.Context) string {
+	if pc == nil {
+		return ""
+	}
+	s, _ := pc.Get(_sourceKey).(string)
+	return s
+}
+
+// SetResolveContext stores a context.Context on the provided
+// parser.Context for resolvers that make network or database requests.
+//
+//	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+//	defer cancel()
+//
+//	pc := parser.NewContext()
+//	wikilink.SetResolveContext(pc, ctx)
+//	md.Convert(src, &buf, parser.WithContext(pc))
+//
+// Wikilinks parsed with this context carry it,
+// and it's passed to resolvers that implement ResolverWithContext.
+func SetResolveContext(pc parser.Context, ctx context.Context) {
+	pc.Set(_ctxKey, ctx)
+}
+
+// ResolveContext returns the context.Context set on the provided
+// parser.Context with SetResolveContext, or nil if there isn't one.
+func ResolveContext(pc parser.Context) context.Context {
+	if pc == nil {
+		return nil
+	}
+	ctx, _ := pc.Get(_ctxKey).(context.Context)
+	return ctx
+}
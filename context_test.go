@@ -0,0 +1,152 @@
+package wikilink_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	wikilink "github.com/kentxxq/goldmark-wikilink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+)
+
+func TestContextResolver(t *testing.T) {
+	t.Parallel()
+
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{}))
+	src := []byte("[[Foo]]")
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, md.Convert(src, &buf))
+		assert.Equal(t, "<p><a href=\"Foo.html\">Foo</a></p>\n", buf.String())
+	})
+
+	t.Run("override", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := parser.NewContext()
+		wikilink.SetContextResolver(ctx, wikilink.PrettyResolver)
+		assert.Equal(t, wikilink.PrettyResolver, wikilink.ContextResolver(ctx))
+
+		var buf bytes.Buffer
+		require.NoError(t, md.Convert(src, &buf, parser.WithContext(ctx)))
+		assert.Equal(t, "<p><a href=\"Foo/\">Foo</a></p>\n", buf.String())
+	})
+
+	t.Run("unset", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Nil(t, wikilink.ContextResolver(parser.NewContext()))
+		assert.Nil(t, wikilink.ContextResolver(nil))
+	})
+}
+
+func TestSiteContext(t *testing.T) {
+	t.Parallel()
+
+	type site struct{ base string }
+
+	// Resolves links relative to the base of the site.
+	resolver := resolverFunc(func(n *wikilink.Node) ([]byte, error) {
+		s, ok := n.Site().(*site)
+		if !ok {
+			return nil, nil
+		}
+		return []byte(s.base + string(n.Target)), nil
+	})
+
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		Resolver: resolver,
+		Site:     &site{base: "/docs/"},
+	}))
+	src := []byte("[[Foo]]")
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := parser.NewContext()
+
+		var buf bytes.Buffer
+		require.NoError(t, md.Convert(src, &buf, parser.WithContext(ctx)))
+		assert.Equal(t, "<p><a href=\"/docs/Foo\">Foo</a></p>\n", buf.String())
+		assert.Equal(t, &site{base: "/docs/"}, wikilink.SiteContext(ctx),
+			"site must be stored on the context")
+	})
+
+	t.Run("override", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := parser.NewContext()
+		wikilink.SetSiteContext(ctx, &site{base: "/blog/"})
+
+		var buf bytes.Buffer
+		require.NoError(t, md.Convert(src, &buf, parser.WithContext(ctx)))
+		assert.Equal(t, "<p><a href=\"/blog/Foo\">Foo</a></p>\n", buf.String())
+	})
+}
+
+// ctxResolver is a ResolverWithContext that fails if its context is done.
+type ctxResolver struct{}
+
+func (ctxResolver) ResolveWikilink(n *wikilink.Node) ([]byte, error) {
+	return ctxResolver{}.ResolveWikilinkContext(context.Background(), n)
+}
+
+func (ctxResolver) ResolveWikilinkContext(ctx context.Context, n *wikilink.Node) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return wikilink.DefaultResolver.ResolveWikilink(n)
+}
+
+func TestResolveContext(t *testing.T) {
+	t.Parallel()
+
+	newMarkdown := func() goldmark.Markdown {
+		return goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+			// The cache must pass the context along.
+			Resolver: wikilink.NewCachedResolver(10, ctxResolver{}),
+		}))
+	}
+	src := []byte("[[Foo]]")
+
+	t.Run("unset", func(t *testing.T) {
+		t.Parallel()
+
+		md := newMarkdown()
+
+		assert.Nil(t, wikilink.ResolveContext(parser.NewContext()))
+		assert.Nil(t, wikilink.ResolveContext(nil))
+
+		var buf bytes.Buffer
+		require.NoError(t, md.Convert(src, &buf))
+		assert.Equal(t, "<p><a href=\"Foo.html\">Foo</a></p>\n", buf.String())
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		md := newMarkdown()
+		pc := parser.NewContext()
+		wikilink.SetResolveContext(pc, ctx)
+		assert.Equal(t, ctx, wikilink.ResolveContext(pc))
+
+		var buf bytes.Buffer
+		err := md.Convert(src, &buf, parser.WithContext(pc))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+type resolverFunc func(*wikilink.Node) ([]byte, error)
+
+func (f resolverFunc) ResolveWikilink(n *wikilink.Node) ([]byte, error) {
+	return f(n)
+}
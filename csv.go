@@ -0,0 +1,137 @@
+package wikilink
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/yuin/goldmark/util"
+)
+
+// CSVEmbed is an EmbedHandler that reads embedded CSV files
+// and renders them as HTML tables.
+//
+//	&wikilink.Extender{
+//		EmbedHandlers: map[string]wikilink.EmbedHandler{
+//			".csv": &wikilink.CSVEmbed{FS: os.DirFS("notes"), MaxRows: 100},
+//		},
+//	}
+//
+// The first record of the file is used as the header of the table.
+//
+//	<table class="wikilink-csv">
+//	<thead><tr><th>name</th><th>age</th></tr></thead>
+//	<tbody><tr><td>Alice</td><td>30</td></tr></tbody>
+//	</table>
+type CSVEmbed struct {
+	// FS holds the CSV files.
+	//
+	// Files are opened at the destination returned by the Resolver,
+	// without its query, fragment, or leading "/".
+	FS fs.FS
+
+	// MaxRows limits the number of rows in the body of the table.
+	// Files with more rows are truncated and the table gets the
+	// "wikilink-csv-truncated" class.
+	//
+	// Defaults to no limit.
+	MaxRows int
+
+	// NoHeader renders the first record of the file
+	// as a regular row instead of the header of the table.
+	NoHeader bool
+
+	// Comma is the field delimiter, e.g. '\t' for TSV files.
+	//
+	// Defaults to ','.
+	Comma rune
+}
+
+var _ BlockEmbedHandler = (*CSVEmbed)(nil)
+
+// RenderEmbed writes the contents of the CSV file for n as a <table>.
+func (e *CSVEmbed) RenderEmbed(w util.BufWriter, _ []byte, n *Node, dest []byte) error {
+	name := embedPath(dest)
+	if !fs.ValidPath(name) {
+		return fmt.Errorf("invalid CSV path %q", name)
+	}
+
+	f, err := e.FS.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1 // allow ragged rows
+	if e.Comma != 0 {
+		r.Comma = e.Comma
+	}
+
+	var header []string
+	if !e.NoHeader {
+		header, err = r.Read()
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("parse CSV: %w", err)
+		}
+	}
+
+	var (
+		rows      [][]string
+		truncated bool
+	)
+	for {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("parse CSV: %w", err)
+		}
+		if e.MaxRows > 0 && len(rows) == e.MaxRows {
+			truncated = true
+			break
+		}
+		rows = append(rows, row)
+	}
+
+	_, _ = w.WriteString(`<table class="wikilink-csv`)
+	if truncated {
+		_, _ = w.WriteString(` wikilink-csv-truncated`)
+	}
+	_, _ = w.WriteString("\">\n")
+	if len(header) > 0 {
+		_, _ = w.WriteString("<thead>")
+		writeCSVRow(w, "th", header)
+		_, _ = w.WriteString("</thead>\n")
+	}
+	if len(rows) > 0 {
+		_, _ = w.WriteString("<tbody>\n")
+		for _, row := range rows {
+			writeCSVRow(w, "td", row)
+			_ = w.WriteByte('\n')
+		}
+		_, _ = w.WriteString("</tbody>\n")
+	}
+	_, _ = w.WriteString("</table>")
+	return nil
+}
+
+// BlockEmbed reports that CSV files are rendered as tables.
+func (e *CSVEmbed) BlockEmbed([]byte, *Node) bool {
+	return true
+}
+
+// writeCSVRow writes the fields of a CSV record as a table row,
+// with each field in a cell of the given kind: "th" or "td".
+func writeCSVRow(w util.BufWriter, cell string, fields []string) {
+	_, _ = w.WriteString("<tr>")
+	for _, field := range fields {
+		_, _ = w.WriteString("<" + cell + ">")
+		_, _ = w.Write(util.EscapeHTML([]byte(field)))
+		_, _ = w.WriteString("</" + cell + ">")
+	}
+	_, _ = w.WriteString("</tr>")
+}
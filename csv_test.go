@@ -0,0 +1,135 @@
+package wikilink
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestCSVEmbed(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"people.csv":      {Data: []byte("name,age\nAlice,30\n\"Bob <b>\",25\nCarol\n")},
+		"data/scores.tsv": {Data: []byte("a\tb\n1\t2\n")},
+		"empty.csv":       {Data: []byte("")},
+		"broken.csv":      {Data: []byte("a,\"b\n")},
+	}
+
+	tests := []struct {
+		desc string
+		give string
+		csv  CSVEmbed
+		want string
+	}{
+		{
+			desc: "table",
+			give: "![[people.csv]]",
+			want: `<table class="wikilink-csv">
+<thead><tr><th>name</th><th>age</th></tr></thead>
+<tbody>
+<tr><td>Alice</td><td>30</td></tr>
+<tr><td>Bob &lt;b&gt;</td><td>25</td></tr>
+<tr><td>Carol</td></tr>
+</tbody>
+</table>`,
+		},
+		{
+			desc: "max rows",
+			give: "![[people.csv]]",
+			csv:  CSVEmbed{MaxRows: 2},
+			want: `<table class="wikilink-csv wikilink-csv-truncated">
+<thead><tr><th>name</th><th>age</th></tr></thead>
+<tbody>
+<tr><td>Alice</td><td>30</td></tr>
+<tr><td>Bob &lt;b&gt;</td><td>25</td></tr>
+</tbody>
+</table>`,
+		},
+		{
+			desc: "no header",
+			give: "![[people.csv]]",
+			csv:  CSVEmbed{MaxRows: 3, NoHeader: true},
+			want: `<table class="wikilink-csv wikilink-csv-truncated">
+<tbody>
+<tr><td>name</td><td>age</td></tr>
+<tr><td>Alice</td><td>30</td></tr>
+<tr><td>Bob &lt;b&gt;</td><td>25</td></tr>
+</tbody>
+</table>`,
+		},
+		{
+			desc: "max rows not reached",
+			give: "![[people.csv]]",
+			csv:  CSVEmbed{MaxRows: 3},
+			want: `<table class="wikilink-csv">
+<thead><tr><th>name</th><th>age</th></tr></thead>
+<tbody>
+<tr><td>Alice</td><td>30</td></tr>
+<tr><td>Bob &lt;b&gt;</td><td>25</td></tr>
+<tr><td>Carol</td></tr>
+</tbody>
+</table>`,
+		},
+		{
+			desc: "comma",
+			give: "![[/data/scores.tsv]]",
+			csv:  CSVEmbed{Comma: '\t'},
+			want: `<table class="wikilink-csv">
+<thead><tr><th>a</th><th>b</th></tr></thead>
+<tbody>
+<tr><td>1</td><td>2</td></tr>
+</tbody>
+</table>`,
+		},
+		{
+			desc: "empty",
+			give: "![[empty.csv]]",
+			want: `<table class="wikilink-csv">
+</table>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			e := tt.csv
+			e.FS = files
+			md := goldmark.New(goldmark.WithExtensions(&Extender{
+				EmbedHandlers: map[string]EmbedHandler{
+					".csv": &e,
+					".tsv": &e,
+				},
+			}))
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want, strings.TrimSpace(buf.String()))
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		md := goldmark.New(goldmark.WithExtensions(&Extender{
+			EmbedHandlers: map[string]EmbedHandler{
+				".csv": &CSVEmbed{FS: files},
+			},
+		}))
+
+		err := md.Convert([]byte("![[missing.csv]]"), new(bytes.Buffer))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing.csv")
+
+		err = md.Convert([]byte("![[broken.csv]]"), new(bytes.Buffer))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "parse CSV")
+	})
+}
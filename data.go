@@ -0,0 +1,286 @@
+package wikilink
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/yuin/goldmark/util"
+	"gopkg.in/yaml.v3"
+)
+
+// DataEmbed is an EmbedHandler that reads embedded JSON, YAML, and TOML
+// files and renders them as formatted code blocks,
+// so that configuration files referenced in notes are easy to read.
+//
+//	data := &wikilink.DataEmbed{FS: os.DirFS("notes")}
+//	&wikilink.Extender{
+//		EmbedHandlers: map[string]wikilink.EmbedHandler{
+//			".json": data,
+//			".yaml": data,
+//			".yml":  data,
+//			".toml": data,
+//		},
+//	}
+//
+// JSON, YAML, and TOML files are re-indented consistently.
+// YAML and TOML comments are kept.
+//
+// Like CodeEmbed, code is rendered the same way as fenced code blocks
+// unless Highlight is set.
+type DataEmbed struct {
+	// FS holds the data files.
+	//
+	// Files are opened at the destination returned by the Resolver,
+	// without its query, fragment, or leading "/".
+	FS fs.FS
+
+	// Indent is the number of spaces to indent nested values by.
+	//
+	// Defaults to 2.
+	Indent int
+
+	// Highlight, if set, renders the formatted code instead of the
+	// default <pre><code> block.
+	// See CodeEmbed.Highlight for details.
+	Highlight func(w util.BufWriter, lang string, code []byte) error
+}
+
+var _ BlockEmbedHandler = (*DataEmbed)(nil)
+
+// RenderEmbed writes the formatted contents of the data file for n
+// as a code block.
+func (e *DataEmbed) RenderEmbed(w util.BufWriter, _ []byte, n *Node, dest []byte) error {
+	name := embedPath(dest)
+	if !fs.ValidPath(name) {
+		return fmt.Errorf("invalid data file path %q", name)
+	}
+
+	data, err := fs.ReadFile(e.FS, name)
+	if err != nil {
+		return err
+	}
+
+	var lang string
+	switch ext := strings.ToLower(path.Ext(name)); ext {
+	case ".json":
+		lang = "json"
+		data, err = e.formatJSON(data)
+	case ".yaml", ".yml":
+		lang = "yaml"
+		data, err = e.formatYAML(data)
+	case ".toml":
+		lang = "toml"
+		data, err = e.formatTOML(data)
+	default:
+		lang = strings.TrimPrefix(ext, ".")
+	}
+	if err != nil {
+		return err
+	}
+
+	if e.Highlight != nil {
+		return e.Highlight(w, lang, data)
+	}
+	writeCodeBlock(w, lang, data)
+	return nil
+}
+
+// BlockEmbed reports that data files are rendered as code blocks.
+func (e *DataEmbed) BlockEmbed([]byte, *Node) bool {
+	return true
+}
+
+func (e *DataEmbed) indent() int {
+	if e.Indent > 0 {
+		return e.Indent
+	}
+	return 2
+}
+
+func (e *DataEmbed) formatJSON(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", strings.Repeat(" ", e.indent())); err != nil {
+		return nil, fmt.Errorf("format JSON: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func (e *DataEmbed) formatYAML(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(e.indent())
+
+	// Decode into yaml.Node rather than interface{}
+	// to keep comments and the order of keys.
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("format YAML: %w", err)
+		}
+		if err := enc.Encode(&doc); err != nil {
+			return nil, fmt.Errorf("format YAML: %w", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("format YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatTOML re-indents TOML line by line,
+// since TOML doesn't depend on indentation.
+// Keys and values are separated by " = ",
+// the elements of arrays and inline tables that span lines
+// are indented by their depth,
+// and runs of blank lines are collapsed.
+// Comments and multi-line strings are kept as-is.
+func (e *DataEmbed) formatTOML(data []byte) ([]byte, error) {
+	indent := strings.Repeat(" ", e.indent())
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+
+	var (
+		buf   bytes.Buffer
+		sc    tomlScanner
+		blank bool
+	)
+	for i, line := range lines {
+		if len(sc.str) > 0 {
+			// Inside a multi-line string: keep the line as-is.
+			if err := sc.scan(line); err != nil {
+				return nil, fmt.Errorf("format TOML: line %d: %w", i+1, err)
+			}
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+			continue
+		}
+
+		line = strings.TrimLeft(line, " \t")
+		if len(strings.TrimSpace(line)) == 0 {
+			blank = buf.Len() > 0
+			continue
+		}
+		if blank {
+			buf.WriteByte('\n')
+			blank = false
+		}
+
+		depth := sc.depth
+		if line[0] == ']' || line[0] == '}' {
+			depth--
+		}
+		if sc.depth == 0 && line[0] != '[' && line[0] != '#' {
+			if key, value, ok := splitTOMLKey(line); ok {
+				line = key + " = " + value
+			}
+		}
+		if sc.depth > 0 || line[0] != '[' {
+			// Skip the brackets of table headers like [server].
+			if err := sc.scan(line); err != nil {
+				return nil, fmt.Errorf("format TOML: line %d: %w", i+1, err)
+			}
+		}
+		if len(sc.str) == 0 {
+			line = strings.TrimRight(line, " \t")
+		}
+
+		buf.WriteString(strings.Repeat(indent, max(depth, 0)))
+		buf.WriteString(line)
+		buf.WriteByte('\n')
+	}
+
+	switch {
+	case len(sc.str) > 0:
+		return nil, fmt.Errorf("format TOML: unterminated multi-line string")
+	case sc.depth > 0:
+		return nil, fmt.Errorf("format TOML: unclosed array or inline table")
+	}
+	return buf.Bytes(), nil
+}
+
+// splitTOMLKey splits a TOML key/value pair at the "=" after its key,
+// which may be quoted.
+func splitTOMLKey(line string) (key, value string, ok bool) {
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '"', '\'':
+			end := strings.IndexByte(line[i+1:], c)
+			if end < 0 {
+				return "", "", false
+			}
+			i += end + 1
+		case '=':
+			return strings.TrimSpace(line[:i]), strings.TrimLeft(line[i+1:], " \t"), true
+		case '#', '[', '{':
+			return "", "", false
+		}
+	}
+	return "", "", false
+}
+
+// tomlScanner tracks the state of a TOML document across lines.
+type tomlScanner struct {
+	depth int    // number of open arrays and inline tables
+	str   string // delimiter of the open multi-line string, if any
+}
+
+// scan updates the state of the scanner with a line of TOML.
+func (sc *tomlScanner) scan(line string) error {
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if len(sc.str) > 0 {
+			switch {
+			case c == '\\' && sc.str == `"""`:
+				i++ // skip the escaped character
+			case strings.HasPrefix(line[i:], sc.str):
+				// Up to two quotes may precede the closing delimiter.
+				i += len(sc.str) - 1
+				for n := 0; n < 2 && i+1 < len(line) && line[i+1] == c; n++ {
+					i++
+				}
+				sc.str = ""
+			}
+			continue
+		}
+
+		switch c {
+		case '#':
+			return nil
+		case '"', '\'':
+			if delim := strings.Repeat(string(c), 3); strings.HasPrefix(line[i:], delim) {
+				sc.str = delim
+				i += len(delim) - 1
+				continue
+			}
+			j := i + 1
+			for ; j < len(line) && line[j] != c; j++ {
+				if c == '"' && line[j] == '\\' {
+					j++
+				}
+			}
+			if j >= len(line) {
+				return errors.New("unterminated string")
+			}
+			i = j
+		case '[', '{':
+			sc.depth++
+		case ']', '}':
+			sc.depth--
+			if sc.depth < 0 {
+				return fmt.Errorf("unexpected %q", c)
+			}
+		}
+	}
+	return nil
+}
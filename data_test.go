@@ -0,0 +1,201 @@
+package wikilink
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestDataEmbed(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"config.json": {Data: []byte(`{"name":"site","tags":["a","<b>"],"nested":{"x":1}}`)},
+		"config.yaml": {Data: []byte("name: site # the name\ntags:\n    - a\n    - b\n")},
+		"multi.yml":   {Data: []byte("a: 1\n---\nb: 2\n")},
+		"config.toml": {Data: []byte("[server]\nport = 8080\n")},
+		"messy.toml": {Data: []byte(strings.Join([]string{
+			"# Site",
+			"title=\"Notes\"   ",
+			"",
+			"",
+			"  [server]",
+			"    host   =  \"a=b # c\" # comment",
+			"hosts = [",
+			"      \"a\",",
+			"  [1, 2],",
+			"{ x = 1 },",
+			"]",
+			"motd = \"\"\"",
+			"  kept   ",
+			"    as-is\"\"\"",
+			"\"quoted key\"=1",
+		}, "\n"))},
+		"broken.toml":   {Data: []byte("a = [1,\n")},
+		"unclosed.toml": {Data: []byte("a = \"\"\"\nb\n")},
+		"broken.json":   {Data: []byte(`{"name":`)},
+		"broken.yaml":   {Data: []byte("a: [1\n")},
+	}
+
+	tests := []struct {
+		desc string
+		give string
+		data DataEmbed
+		want string
+	}{
+		{
+			desc: "json",
+			give: "![[config.json]]",
+			want: `<pre><code class="language-json">{
+  &quot;name&quot;: &quot;site&quot;,
+  &quot;tags&quot;: [
+    &quot;a&quot;,
+    &quot;&lt;b&gt;&quot;
+  ],
+  &quot;nested&quot;: {
+    &quot;x&quot;: 1
+  }
+}
+</code></pre>`,
+		},
+		{
+			desc: "json indent",
+			give: "![[config.json]]",
+			data: DataEmbed{Indent: 4},
+			want: `<pre><code class="language-json">{
+    &quot;name&quot;: &quot;site&quot;,
+    &quot;tags&quot;: [
+        &quot;a&quot;,
+        &quot;&lt;b&gt;&quot;
+    ],
+    &quot;nested&quot;: {
+        &quot;x&quot;: 1
+    }
+}
+</code></pre>`,
+		},
+		{
+			desc: "yaml",
+			give: "![[config.yaml]]",
+			want: `<pre><code class="language-yaml">name: site # the name
+tags:
+  - a
+  - b
+</code></pre>`,
+		},
+		{
+			desc: "yaml documents",
+			give: "![[multi.yml]]",
+			want: `<pre><code class="language-yaml">a: 1
+---
+b: 2
+</code></pre>`,
+		},
+		{
+			desc: "toml",
+			give: "![[config.toml]]",
+			want: `<pre><code class="language-toml">[server]
+port = 8080
+</code></pre>`,
+		},
+		{
+			desc: "toml formatted",
+			give: "![[messy.toml]]",
+			want: `<pre><code class="language-toml"># Site
+title = &quot;Notes&quot;
+
+[server]
+host = &quot;a=b # c&quot; # comment
+hosts = [
+  &quot;a&quot;,
+  [1, 2],
+  { x = 1 },
+]
+motd = &quot;&quot;&quot;
+  kept   
+    as-is&quot;&quot;&quot;
+&quot;quoted key&quot; = 1
+</code></pre>`,
+		},
+		{
+			desc: "toml indent",
+			give: "![[messy.toml]]",
+			data: DataEmbed{Indent: 4},
+			want: `<pre><code class="language-toml"># Site
+title = &quot;Notes&quot;
+
+[server]
+host = &quot;a=b # c&quot; # comment
+hosts = [
+    &quot;a&quot;,
+    [1, 2],
+    { x = 1 },
+]
+motd = &quot;&quot;&quot;
+  kept   
+    as-is&quot;&quot;&quot;
+&quot;quoted key&quot; = 1
+</code></pre>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			e := tt.data
+			e.FS = files
+			md := goldmark.New(goldmark.WithExtensions(&Extender{
+				EmbedHandlers: map[string]EmbedHandler{
+					".json": &e,
+					".yaml": &e,
+					".yml":  &e,
+					".toml": &e,
+				},
+			}))
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want, strings.TrimSpace(buf.String()))
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		e := &DataEmbed{FS: files}
+		md := goldmark.New(goldmark.WithExtensions(&Extender{
+			EmbedHandlers: map[string]EmbedHandler{
+				".json": e,
+				".yaml": e,
+				".toml": e,
+			},
+		}))
+
+		err := md.Convert([]byte("![[missing.json]]"), new(bytes.Buffer))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing.json")
+
+		err = md.Convert([]byte("![[broken.json]]"), new(bytes.Buffer))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "format JSON")
+
+		err = md.Convert([]byte("![[broken.yaml]]"), new(bytes.Buffer))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "format YAML")
+
+		err = md.Convert([]byte("![[broken.toml]]"), new(bytes.Buffer))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "format TOML")
+
+		err = md.Convert([]byte("![[unclosed.toml]]"), new(bytes.Buffer))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "format TOML")
+	})
+}
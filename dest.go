@@ -0,0 +1,93 @@
+package wikilink
+
+import "bytes"
+
+// DestBuilder assembles the destination of a wikilink
+// from its parts in a single, exactly sized allocation.
+// Use this in custom resolvers instead of concatenating the parts by hand.
+//
+//	func (r *myResolver) ResolveWikilink(n *wikilink.Node) ([]byte, error) {
+//		return wikilink.DestBuilder{
+//			Prefix:   "/docs/",
+//			Path:     n.Target,
+//			Suffix:   "/",
+//			Query:    n.Query,
+//			Fragment: n.Fragment,
+//		}.Build(), nil
+//	}
+//
+// The prefix and suffix are only added if the path is non-empty,
+// so wikilinks to fragments of the current page, like [[#Foo]],
+// resolve to "#Foo".
+//
+// Any "#" in the path, like that of [[C\# notes]], is percent-encoded
+// so that it isn't mistaken for the start of the fragment.
+type DestBuilder struct {
+	// Prefix is added before the path, e.g. "/docs/" or "../".
+	Prefix string
+
+	// Path is the path of the destination, usually derived from the
+	// target of the wikilink.
+	Path []byte
+
+	// Suffix is added after the path, e.g. ".html" or "/".
+	Suffix string
+
+	// Query is added after a "?" if non-empty.
+	Query []byte
+
+	// Fragment is added after a "#" if non-empty.
+	Fragment []byte
+}
+
+// Len returns the length of the destination that Build will return.
+func (b DestBuilder) Len() int {
+	var n int
+	if len(b.Path) > 0 {
+		n += len(b.Prefix) + len(b.Path) + len(b.Suffix)
+		n += 2 * bytes.Count(b.Path, _hash) // "#" => "%23"
+	}
+	if len(b.Query) > 0 {
+		n += len(_question) + len(b.Query)
+	}
+	if len(b.Fragment) > 0 {
+		n += len(_hash) + len(b.Fragment)
+	}
+	return n
+}
+
+// Build returns the destination in the form,
+//
+//	{Prefix}{Path}{Suffix}?{Query}#{Fragment}
+//
+// Empty parts are omitted along with their separators.
+func (b DestBuilder) Build() []byte {
+	dest := make([]byte, 0, b.Len())
+	if len(b.Path) > 0 {
+		dest = append(dest, b.Prefix...)
+		dest = appendEscapedPath(dest, b.Path)
+		dest = append(dest, b.Suffix...)
+	}
+	if len(b.Query) > 0 {
+		dest = append(dest, _question...)
+		dest = append(dest, b.Query...)
+	}
+	if len(b.Fragment) > 0 {
+		dest = append(dest, _hash...)
+		dest = append(dest, b.Fragment...)
+	}
+	return dest
+}
+
+// appendEscapedPath appends path to dest with "#" percent-encoded.
+func appendEscapedPath(dest, path []byte) []byte {
+	for {
+		idx := bytes.IndexByte(path, '#')
+		if idx < 0 {
+			return append(dest, path...)
+		}
+		dest = append(dest, path[:idx]...)
+		dest = append(dest, "%23"...)
+		path = path[idx+1:]
+	}
+}
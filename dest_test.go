@@ -0,0 +1,80 @@
+package wikilink
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDestBuilder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give DestBuilder
+		want string
+	}{
+		{desc: "empty", want: ""},
+		{
+			desc: "path",
+			give: DestBuilder{Path: []byte("Foo")},
+			want: "Foo",
+		},
+		{
+			desc: "all parts",
+			give: DestBuilder{
+				Prefix:   "/docs/",
+				Path:     []byte("Foo"),
+				Suffix:   "/",
+				Query:    []byte("a=b"),
+				Fragment: []byte("Bar"),
+			},
+			want: "/docs/Foo/?a=b#Bar",
+		},
+		{
+			desc: "fragment only",
+			give: DestBuilder{
+				Prefix:   "/docs/",
+				Suffix:   ".html",
+				Fragment: []byte("Bar"),
+			},
+			want: "#Bar",
+		},
+		{
+			desc: "query only",
+			give: DestBuilder{Prefix: "../", Query: []byte("q=1")},
+			want: "?q=1",
+		},
+		{
+			desc: "hash in path",
+			give: DestBuilder{
+				Path:     []byte("C# notes#2"),
+				Suffix:   ".html",
+				Fragment: []byte("Generics"),
+			},
+			want: "C%23 notes%232.html#Generics",
+		},
+		{
+			desc: "multibyte",
+			give: DestBuilder{
+				Prefix:   "/数据/",
+				Path:     []byte("页面"),
+				Suffix:   "/",
+				Fragment: []byte("标题"),
+			},
+			want: "/数据/页面/#标题",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := tt.give.Build()
+			assert.Equal(t, tt.want, string(got), "result mismatch")
+			assert.Equal(t, len(tt.want), tt.give.Len(), "length mismatch")
+			assert.Equal(t, len(got), cap(got), "capacity should be exact")
+		})
+	}
+}
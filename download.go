@@ -0,0 +1,34 @@
+package wikilink
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// DefaultDownloadExtensions is a list of extensions for common document
+// and archive formats, for use with Renderer.DownloadExtensions.
+var DefaultDownloadExtensions = []string{
+	".pdf", ".zip", ".gz", ".tgz", ".7z",
+	".doc", ".docx", ".xls", ".xlsx", ".ppt", ".pptx",
+	".odt", ".ods", ".odp", ".epub",
+}
+
+// downloadExtension reports the extension of the target of n, lowercased
+// and without the leading ".", if n is not an embed and the extension is
+// one of DownloadExtensions.
+func (r *Renderer) downloadExtension(n *Node) (string, bool) {
+	if n.Embed || len(r.DownloadExtensions) == 0 {
+		return "", false
+	}
+
+	ext := filepath.Ext(string(n.Target))
+	if len(ext) == 0 {
+		return "", false
+	}
+	for _, e := range r.DownloadExtensions {
+		if strings.EqualFold(ext, e) {
+			return strings.ToLower(ext[1:]), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,74 @@
+package wikilink
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer_DownloadExtensions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		exts []string
+		give *Node
+		want string
+	}{
+		{
+			desc: "disabled",
+			give: &Node{Target: []byte("report.pdf")},
+			want: `<a href="report.pdf">`,
+		},
+		{
+			desc: "download",
+			exts: DefaultDownloadExtensions,
+			give: &Node{Target: []byte("files/report.pdf")},
+			want: `<a href="files/report.pdf" download class="wikilink-file-pdf">`,
+		},
+		{
+			desc: "case insensitive",
+			exts: []string{".pptx"},
+			give: &Node{Target: []byte("Slides.PPTX")},
+			want: `<a href="Slides.PPTX" download class="wikilink-file-pptx">`,
+		},
+		{
+			desc: "other extension",
+			exts: DefaultDownloadExtensions,
+			give: &Node{Target: []byte("notes.txt")},
+			want: `<a href="notes.txt">`,
+		},
+		{
+			desc: "no extension",
+			exts: DefaultDownloadExtensions,
+			give: &Node{Target: []byte("Foo")},
+			want: `<a href="Foo.html">`,
+		},
+		{
+			desc: "embed",
+			exts: DefaultDownloadExtensions,
+			give: &Node{Target: []byte("archive.zip"), Embed: true},
+			want: `<a href="archive.zip">`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buff bytes.Buffer
+			w := bufio.NewWriter(&buff)
+
+			r := Renderer{DownloadExtensions: tt.exts}
+			_, err := r.Render(w, nil /* source */, tt.give, true /* entering */)
+			require.NoError(t, err, "should not fail")
+			require.NoError(t, w.Flush(), "flush")
+
+			assert.Equal(t, tt.want, buff.String(), "output mismatch")
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package wikilink
+
+import (
+	"bytes"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/yuin/goldmark/util"
+)
+
+// EmbedHandler renders embedded wikilinks (![[...]]) for a type of file.
+//
+// Register handlers for file extensions with Renderer.RegisterEmbedHandler
+// or the EmbedHandlers field of Renderer or Extender.
+type EmbedHandler interface {
+	// RenderEmbed writes the HTML for the embed n to w.
+	// dest is the destination returned by the Resolver for n,
+	// before Renderer.AssetBaseURLs and Renderer.RelativeDestinations
+	// are applied, so that handlers can read the file at dest.
+	// Use n.EmbedURL to get the URL to write to the page.
+	// It has not been escaped.
+	// src is the source of the document, needed to read the label of n.
+	//
+	// If RenderEmbed returns a non-nil error, rendering will be halted.
+	RenderEmbed(w util.BufWriter, src []byte, n *Node, dest []byte) error
+}
+
+// BlockEmbedHandler is an optional interface for EmbedHandlers
+// that write block-level HTML, like <table> or <pre>, for some embeds.
+//
+// When such an embed is the only content of its paragraph,
+// it's written in place of the paragraph rather than inside a <p>.
+// See EmbedBlock for details.
+type BlockEmbedHandler interface {
+	EmbedHandler
+
+	// BlockEmbed reports whether RenderEmbed writes block-level HTML
+	// for the embed n.
+	BlockEmbed(src []byte, n *Node) bool
+}
+
+// isBlockEmbed reports whether h writes block-level HTML for n.
+func isBlockEmbed(h EmbedHandler, src []byte, n *Node) bool {
+	b, ok := h.(BlockEmbedHandler)
+	return ok && b.BlockEmbed(src, n)
+}
+
+// RegisterEmbedHandler registers a handler for embeds of files
+// with the provided extension, e.g. ".csv".
+// Extensions are matched case-insensitively,
+// and may be compound, e.g. ".excalidraw.md".
+//
+// Handlers registered this way take precedence over the built-in handlers
+// for images, audio, video, and PDFs.
+// Register a nil handler to render embeds of that type as links.
+//
+// RegisterEmbedHandler must not be called concurrently with rendering.
+func (r *Renderer) RegisterEmbedHandler(ext string, h EmbedHandler) {
+	if r.EmbedHandlers == nil {
+		r.EmbedHandlers = make(map[string]EmbedHandler)
+	}
+	r.EmbedHandlers[strings.ToLower(ext)] = h
+}
+
+// embedHandler returns the handler for the embed n,
+// or nil if it should be rendered as a link.
+//
+// Compound extensions are matched before simple ones,
+// so "sketch.excalidraw.md" is matched against ".excalidraw.md"
+// and then ".md".
+func (r *Renderer) embedHandler(n *Node) EmbedHandler {
+	name := strings.ToLower(filepath.Base(string(n.Target)))
+	for i := strings.IndexByte(name, '.'); i >= 0; {
+		ext := name[i:]
+		if h, ok := r.EmbedHandlers[ext]; ok {
+			return h
+		}
+		if h, ok := r.defaultEmbeds[ext]; ok {
+			return h
+		}
+
+		next := strings.IndexByte(ext[1:], '.')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return nil
+}
+
+// embedPath returns the path of the file referenced by the destination
+// of an embed, for use with fs.FS.
+func embedPath(dest []byte) string {
+	if idx := bytes.IndexAny(dest, "?#"); idx >= 0 {
+		dest = dest[:idx]
+	}
+	return path.Clean(strings.TrimPrefix(string(dest), "/"))
+}
+
+// defaultEmbedHandlers builds the built-in embed handlers for r.
+func defaultEmbedHandlers(r *Renderer) map[string]EmbedHandler {
+	handlers := make(map[string]EmbedHandler)
+
+	// Common image file types taken from
+	// https://developer.mozilla.org/en-US/docs/Web/Media/Formats/Image_types
+	image := imageEmbed{r}
+	for _, ext := range []string{".apng", ".avif", ".gif", ".jpg", ".jpeg", ".jfif", ".pjpeg", ".pjp", ".png", ".svg", ".webp"} {
+		handlers[ext] = image
+	}
+
+	audio := &mediaEmbed{r: r, open: `<audio controls src="`, close: `</audio>`}
+	for _, ext := range []string{".flac", ".m4a", ".mp3", ".oga", ".ogg", ".opus", ".wav"} {
+		handlers[ext] = audio
+	}
+
+	video := &mediaEmbed{r: r, open: `<video controls src="`, close: `</video>`}
+	for _, ext := range []string{".m4v", ".mov", ".mp4", ".ogv", ".webm"} {
+		handlers[ext] = video
+	}
+
+	handlers[".pdf"] = &mediaEmbed{r: r, open: `<object type="application/pdf" data="`, close: `</object>`}
+	return handlers
+}
+
+// imageEmbed renders image embeds as <img> tags,
+// inside a <figure> if Renderer.ImageFigure is set.
+type imageEmbed struct{ r *Renderer }
+
+var _ BlockEmbedHandler = imageEmbed{}
+
+func (e imageEmbed) RenderEmbed(w util.BufWriter, src []byte, n *Node, dest []byte) error {
+	return e.r.renderImage(w, src, n, dest)
+}
+
+// BlockEmbed reports whether n is rendered as a <figure>.
+func (e imageEmbed) BlockEmbed(src []byte, n *Node) bool {
+	return e.r.imageFigure(src, n)
+}
+
+// mediaEmbed renders embeds as an element with a link to the file as
+// fallback content for browsers that can't display it.
+//
+//	<audio controls src="song.mp3"><a href="song.mp3">song.mp3</a></audio>
+type mediaEmbed struct {
+	r *Renderer
+
+	// open is the opening tag up to and including the opening quote
+	// of the attribute holding the destination.
+	open string
+
+	// close is the closing tag.
+	close string
+}
+
+func (e *mediaEmbed) RenderEmbed(w util.BufWriter, src []byte, n *Node, dest []byte) error {
+	url := e.r.escapeURL(n.EmbedURL(dest))
+	_, _ = w.WriteString(e.open)
+	_, _ = w.Write(url)
+	_, _ = w.WriteString(`"><a href="`)
+	_, _ = w.Write(url)
+	_, _ = w.WriteString(`">`)
+	_, _ = w.Write(util.EscapeHTML(labelText(src, n)))
+	_, _ = w.WriteString(`</a>`)
+	_, _ = w.WriteString(e.close)
+	return nil
+}
@@ -0,0 +1,136 @@
+package wikilink_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	wikilink "github.com/kentxxq/goldmark-wikilink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/util"
+)
+
+// csvEmbed renders CSV embeds as a <table> placeholder.
+type csvEmbed struct{}
+
+func (csvEmbed) RenderEmbed(w util.BufWriter, src []byte, n *wikilink.Node, dest []byte) error {
+	_, _ = w.WriteString(`<table data-src="`)
+	_, _ = w.Write(dest)
+	_, _ = w.WriteString(`"></table>`)
+	return nil
+}
+
+type errEmbed struct{ err error }
+
+func (e errEmbed) RenderEmbed(util.BufWriter, []byte, *wikilink.Node, []byte) error {
+	return e.err
+}
+
+func TestEmbedHandlers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		handlers map[string]wikilink.EmbedHandler
+		give     string
+		want     string
+	}{
+		{
+			desc: "image",
+			give: "![[cat.png]]",
+			want: `<p><img src="cat.png"></p>`,
+		},
+		{
+			desc: "image uppercase",
+			give: "![[cat.PNG]]",
+			want: `<p><img src="cat.PNG"></p>`,
+		},
+		{
+			desc: "audio",
+			give: "![[song.mp3|Our song]]",
+			want: `<p><audio controls src="song.mp3"><a href="song.mp3">Our song</a></audio></p>`,
+		},
+		{
+			desc: "video",
+			give: "![[clip.webm]]",
+			want: `<p><video controls src="clip.webm"><a href="clip.webm">clip.webm</a></video></p>`,
+		},
+		{
+			desc: "pdf",
+			give: "![[paper.pdf|<Paper>]]",
+			want: `<p><object type="application/pdf" data="paper.pdf"><a href="paper.pdf">&lt;Paper&gt;</a></object></p>`,
+		},
+		{
+			desc: "unknown",
+			give: "![[data.csv]]",
+			want: `<p><a href="data.csv">data.csv</a></p>`,
+		},
+		{
+			desc:     "custom",
+			handlers: map[string]wikilink.EmbedHandler{".csv": csvEmbed{}},
+			give:     "![[data.csv]]",
+			want:     `<p><table data-src="data.csv"></table></p>`,
+		},
+		{
+			desc:     "override",
+			handlers: map[string]wikilink.EmbedHandler{".pdf": csvEmbed{}},
+			give:     "![[paper.pdf]]",
+			want:     `<p><table data-src="paper.pdf"></table></p>`,
+		},
+		{
+			desc:     "disable",
+			handlers: map[string]wikilink.EmbedHandler{".mp3": nil},
+			give:     "![[song.mp3]]",
+			want:     `<p><a href="song.mp3">song.mp3</a></p>`,
+		},
+		{
+			desc:     "not embed",
+			handlers: map[string]wikilink.EmbedHandler{".csv": csvEmbed{}},
+			give:     "[[data.csv]]",
+			want:     `<p><a href="data.csv">data.csv</a></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+				EmbedHandlers: tt.handlers,
+			}))
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want, strings.TrimSpace(buf.String()))
+		})
+	}
+}
+
+func TestRenderer_RegisterEmbedHandler(t *testing.T) {
+	t.Parallel()
+
+	var r wikilink.Renderer
+	r.RegisterEmbedHandler(".CSV", csvEmbed{})
+	assert.Equal(t, csvEmbed{}, r.EmbedHandlers[".csv"])
+}
+
+func TestEmbedHandlers_error(t *testing.T) {
+	t.Parallel()
+
+	sadness := errors.New("great sadness")
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		EmbedHandlers: map[string]wikilink.EmbedHandler{
+			".csv": errEmbed{sadness},
+		},
+	}))
+
+	var buf bytes.Buffer
+	err := md.Convert([]byte("![[data.csv]]"), &buf)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, sadness)
+	assert.Contains(t, err.Error(), `"data.csv"`)
+}
@@ -0,0 +1,88 @@
+package wikilink
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// KindEmbedBlock is the kind of the EmbedBlock AST node.
+var KindEmbedBlock = ast.NewNodeKind("WikiLinkEmbedBlock")
+
+// EmbedBlock is a block AST node that holds an embed,
+// like ![[data.csv]], that was the only content of its paragraph.
+// Its only child is the embed's Node.
+//
+// The Renderer writes the block-level HTML of embeds in an EmbedBlock,
+// like tables and figures, in place of the paragraph,
+// since <p> elements can't hold them.
+// Embeds that render as inline HTML, like images and links,
+// are written inside a <p> as usual.
+type EmbedBlock struct {
+	ast.BaseBlock
+
+	// paragraph records whether the Renderer wrapped the embed
+	// in a <p> because it rendered as inline HTML.
+	paragraph bool
+}
+
+var _ ast.Node = (*EmbedBlock)(nil)
+
+// Kind reports the kind of this node.
+func (b *EmbedBlock) Kind() ast.NodeKind {
+	return KindEmbedBlock
+}
+
+// Dump dumps the EmbedBlock to stdout.
+func (b *EmbedBlock) Dump(src []byte, level int) {
+	ast.DumpHelper(b, src, level, nil, nil)
+}
+
+// EmbedBlockTransformer is a goldmark AST transformer that replaces
+// paragraphs holding nothing but an embed with an EmbedBlock,
+// so that the Renderer can write block-level HTML for the embed,
+// like <table> or <figure>, without an invalid <p> around it.
+//
+//	![[data.csv]]
+//	// => <table class="wikilink-csv">...</table>
+//
+// Paragraphs with attributes, like the ids added by BlockIDTransformer,
+// and embeds next to other text are left as-is.
+//
+// The Extender installs it automatically.
+// To use it directly on a goldmark Parser, install it with
+// WithASTTransformers after other transformers that change paragraphs.
+//
+//	goldmarkParser.AddOptions(parser.WithASTTransformers(
+//		util.Prioritized(&wikilink.EmbedBlockTransformer{}, 200),
+//	))
+type EmbedBlockTransformer struct{}
+
+var _ parser.ASTTransformer = (*EmbedBlockTransformer)(nil)
+
+// Transform replaces paragraphs holding only an embed with EmbedBlocks.
+func (t *EmbedBlockTransformer) Transform(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	// Collect the paragraphs first because we'll replace them.
+	var paras []*ast.Paragraph
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		p, ok := n.(*ast.Paragraph)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		if e, ok := p.FirstChild().(*Node); ok && e.Embed && p.ChildCount() == 1 && p.Attributes() == nil {
+			paras = append(paras, p)
+		}
+		return ast.WalkSkipChildren, nil
+	})
+
+	for _, p := range paras {
+		b := new(EmbedBlock)
+		b.SetLines(p.Lines())
+		b.SetBlankPreviousLines(p.HasBlankPreviousLines())
+		b.AppendChild(b, p.FirstChild())
+		p.Parent().ReplaceChild(p.Parent(), p, b)
+	}
+}
@@ -0,0 +1,126 @@
+package wikilink
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/util"
+)
+
+// blockEmbed renders embeds as <div>s.
+type blockEmbed struct{}
+
+var _ BlockEmbedHandler = blockEmbed{}
+
+func (blockEmbed) RenderEmbed(w util.BufWriter, _ []byte, n *Node, _ []byte) error {
+	_, _ = w.WriteString(`<div class="block">`)
+	_, _ = w.Write(util.EscapeHTML(n.Target))
+	_, _ = w.WriteString(`</div>`)
+	return nil
+}
+
+func (blockEmbed) BlockEmbed([]byte, *Node) bool {
+	return true
+}
+
+func TestEmbedBlockTransformer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give string
+		ext  Extender
+		want string
+	}{
+		{
+			desc: "figure",
+			give: "![[cat.png|Cat]]",
+			ext:  Extender{ImageFigure: true},
+			want: `<figure><img src="cat.png" alt="Cat"><figcaption>Cat</figcaption></figure>`,
+		},
+		{
+			desc: "image",
+			give: "![[cat.png|Cat]]",
+			want: `<p><img src="cat.png" alt="Cat"></p>`,
+		},
+		{
+			desc: "figure in text",
+			give: "See ![[cat.png|Cat]]",
+			ext:  Extender{ImageFigure: true},
+			want: `<p>See <img src="cat.png" alt="Cat"></p>`,
+		},
+		{
+			desc: "block",
+			give: "Data:\n\n![[data.csv]]\n\nDone.",
+			want: "<p>Data:</p>\n" +
+				`<div class="block">data.csv</div>` + "\n" +
+				"<p>Done.</p>",
+		},
+		{
+			desc: "block in quote",
+			give: "> ![[data.csv]]",
+			want: "<blockquote>\n" +
+				`<div class="block">data.csv</div>` + "\n" +
+				"</blockquote>",
+		},
+		{
+			desc: "unresolved",
+			give: "![[data.csv]]",
+			ext: Extender{
+				Resolver:       resolverFunc(func(*Node) ([]byte, error) { return nil, nil }),
+				MarkUnresolved: true,
+			},
+			want: `<p><span class="wikilink-unresolved" role="link" aria-disabled="true">data.csv</span></p>`,
+		},
+		{
+			desc: "private",
+			give: "![[data.csv]]",
+			ext:  Extender{Resolver: privateResolver},
+			want: `<p>data.csv</p>`,
+		},
+		{
+			desc: "block ID",
+			give: "![[cat.png|Cat]] ^cat",
+			ext:  Extender{ImageFigure: true, BlockIDs: true},
+			want: `<p id="^cat"><img src="cat.png" alt="Cat"></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ext := tt.ext
+			ext.EmbedHandlers = map[string]EmbedHandler{
+				".csv": blockEmbed{},
+			}
+			md := goldmark.New(goldmark.WithExtensions(&ext))
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want, strings.TrimSpace(buf.String()))
+		})
+	}
+}
+
+func TestEmbedBlockTransformer_noParagraphFigure(t *testing.T) {
+	t.Parallel()
+
+	md := goldmark.New(goldmark.WithExtensions(&Extender{ImageFigure: true}))
+
+	var buf bytes.Buffer
+	require.NoError(t, md.Convert([]byte(
+		"![[cat.png|Cat]]\n\n"+
+			"- ![[dog.png|Dog]]\n\n"+
+			"  ![[bird.png|Bird]] and ![[fish.png|Fish]]\n\n"+
+			"# ![[owl.png|Owl]]\n\n"+
+			"- ![[ant.png|Ant]]\n"+
+			"- See ![[bee.png|Bee]]"), &buf))
+	assert.Equal(t, 3, strings.Count(buf.String(), "<figure>"), buf.String())
+	assert.NotRegexp(t, `<(p|h1)[^>]*>[^<]*<figure>`, buf.String())
+}
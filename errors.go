@@ -0,0 +1,186 @@
+package wikilink
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrTargetNotFound matches errors that indicate that the target of
+	// a wikilink does not exist. Use errors.As with *TargetNotFoundError
+	// to get the offending Node.
+	ErrTargetNotFound = errors.New("target not found")
+
+	// ErrAmbiguousTarget matches errors that indicate that the target of
+	// a wikilink matches more than one page. Use errors.As with
+	// *AmbiguousTargetError to get the offending Node and the candidates.
+	ErrAmbiguousTarget = errors.New("ambiguous target")
+
+	// ErrInvalidTarget matches errors that indicate that the target of
+	// a wikilink is not valid. Use errors.As with *InvalidTargetError
+	// to get the offending Node.
+	ErrInvalidTarget = errors.New("invalid target")
+)
+
+// TargetNotFoundError is returned by resolvers when the target of a
+// wikilink does not exist. It matches ErrTargetNotFound with errors.Is.
+type TargetNotFoundError struct {
+	// Node that failed to resolve.
+	Node *Node
+
+	// Suggestions are existing targets close to the one that wasn't
+	// found, most likely first, if the resolver knows any.
+	Suggestions []string
+}
+
+func (e *TargetNotFoundError) Error() string {
+	msg := ErrTargetNotFound.Error()
+	if len(e.Suggestions) > 0 {
+		msg += ": did you mean " + strings.Join(e.Suggestions, ", ") + "?"
+	}
+	return nodeErrorString(e.Node, msg)
+}
+
+// Is reports whether target is ErrTargetNotFound.
+func (e *TargetNotFoundError) Is(target error) bool {
+	return target == ErrTargetNotFound
+}
+
+// AmbiguousTargetError is returned by resolvers when the target of a
+// wikilink matches more than one page. It matches ErrAmbiguousTarget with
+// errors.Is.
+type AmbiguousTargetError struct {
+	// Node that failed to resolve.
+	Node *Node
+
+	// Candidates that the target matched.
+	Candidates []string
+}
+
+func (e *AmbiguousTargetError) Error() string {
+	msg := ErrAmbiguousTarget.Error()
+	if len(e.Candidates) > 0 {
+		msg += ": could be " + strings.Join(e.Candidates, ", ")
+	}
+	return nodeErrorString(e.Node, msg)
+}
+
+// Is reports whether target is ErrAmbiguousTarget.
+func (e *AmbiguousTargetError) Is(target error) bool {
+	return target == ErrAmbiguousTarget
+}
+
+// InvalidTargetError is returned by resolvers when the target of a
+// wikilink is not valid. It matches ErrInvalidTarget with errors.Is.
+type InvalidTargetError struct {
+	// Node that failed to resolve.
+	Node *Node
+
+	// Reason the target is invalid, if known.
+	Reason string
+}
+
+func (e *InvalidTargetError) Error() string {
+	msg := ErrInvalidTarget.Error()
+	if len(e.Reason) > 0 {
+		msg += ": " + e.Reason
+	}
+	return nodeErrorString(e.Node, msg)
+}
+
+// Is reports whether target is ErrInvalidTarget.
+func (e *InvalidTargetError) Is(target error) bool {
+	return target == ErrInvalidTarget
+}
+
+// RenderError is returned by Renderer when rendering halts because the
+// resolver or embed handler for a wikilink failed.
+// It records which wikilink failed and where, so that build logs point
+// at the offending link.
+//
+//	notes/index.md:12: resolve "Foo#Bar": great sadness
+//
+// Errors like TargetNotFoundError, which already name the wikilink,
+// are only prefixed with Op.
+//
+//	resolve: notes/index.md: "Foo": target not found
+//
+// Use errors.As to retrieve it from the error returned by goldmark.
+type RenderError struct {
+	// Op is the operation that failed: "resolve" or "render embed".
+	Op string
+
+	// Node that failed to render.
+	Node *Node
+
+	// Line is the 1-indexed line number of the wikilink in the source,
+	// or 0 if it is not known.
+	Line int
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *RenderError) Error() string {
+	if e.Node == nil || hasNodeError(e.Err) {
+		// Errors like TargetNotFoundError already say which wikilink
+		// failed and where.
+		return fmt.Sprintf("%v: %v", e.Op, e.Err)
+	}
+
+	var sb strings.Builder
+	if src := e.Node.Source(); len(src) > 0 {
+		sb.WriteString(src)
+		if e.Line > 0 {
+			fmt.Fprintf(&sb, ":%d", e.Line)
+		}
+		sb.WriteString(": ")
+	} else if e.Line > 0 {
+		fmt.Fprintf(&sb, "line %d: ", e.Line)
+	}
+
+	target := string(e.Node.Target)
+	if len(e.Node.Fragment) > 0 {
+		target += "#" + string(e.Node.Fragment)
+	}
+	fmt.Fprintf(&sb, "%v %q: %v", e.Op, target, e.Err)
+	return sb.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// hasNodeError reports whether err wraps a TargetNotFoundError,
+// AmbiguousTargetError, or InvalidTargetError that records its Node.
+func hasNodeError(err error) bool {
+	var (
+		notFound  *TargetNotFoundError
+		ambiguous *AmbiguousTargetError
+		invalid   *InvalidTargetError
+	)
+	switch {
+	case errors.As(err, &notFound):
+		return notFound.Node != nil
+	case errors.As(err, &ambiguous):
+		return ambiguous.Node != nil
+	case errors.As(err, &invalid):
+		return invalid.Node != nil
+	}
+	return false
+}
+
+// nodeErrorString prefixes msg with the target of the node
+// and the document it came from, if known.
+func nodeErrorString(n *Node, msg string) string {
+	if n == nil {
+		return msg
+	}
+
+	if src := n.Source(); len(src) > 0 {
+		return fmt.Sprintf("%v: %q: %v", src, n.Target, msg)
+	}
+	return fmt.Sprintf("%q: %v", n.Target, msg)
+}
@@ -0,0 +1,181 @@
+package wikilink
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolutionErrors(t *testing.T) {
+	t.Parallel()
+
+	n := &Node{Target: []byte("Foo"), source: "notes/index.md"}
+
+	tests := []struct {
+		desc     string
+		give     error
+		sentinel error
+		wantMsg  string
+	}{
+		{
+			desc:     "not found",
+			give:     &TargetNotFoundError{Node: n},
+			sentinel: ErrTargetNotFound,
+			wantMsg:  `notes/index.md: "Foo": target not found`,
+		},
+		{
+			desc:     "not found/suggestions",
+			give:     &TargetNotFoundError{Node: n, Suggestions: []string{"notes/Food", "Fox"}},
+			sentinel: ErrTargetNotFound,
+			wantMsg:  `notes/index.md: "Foo": target not found: did you mean notes/Food, Fox?`,
+		},
+		{
+			desc:     "ambiguous",
+			give:     &AmbiguousTargetError{Node: n, Candidates: []string{"a/Foo.md", "b/Foo.md"}},
+			sentinel: ErrAmbiguousTarget,
+			wantMsg:  `notes/index.md: "Foo": ambiguous target: could be a/Foo.md, b/Foo.md`,
+		},
+		{
+			desc:     "invalid",
+			give:     &InvalidTargetError{Node: &Node{Target: []byte("Foo")}, Reason: "too long"},
+			sentinel: ErrInvalidTarget,
+			wantMsg:  `"Foo": invalid target: too long`,
+		},
+		{
+			desc:     "no node",
+			give:     &InvalidTargetError{},
+			sentinel: ErrInvalidTarget,
+			wantMsg:  `invalid target`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.EqualError(t, tt.give, tt.wantMsg)
+
+			wrapped := fmt.Errorf("wrapped: %w", tt.give)
+			assert.ErrorIs(t, wrapped, tt.sentinel)
+			for _, other := range []error{ErrTargetNotFound, ErrAmbiguousTarget, ErrInvalidTarget} {
+				if other != tt.sentinel {
+					assert.False(t, errors.Is(wrapped, other), "must not match %v", other)
+				}
+			}
+		})
+	}
+
+	t.Run("as", func(t *testing.T) {
+		t.Parallel()
+
+		var err error = fmt.Errorf("wrapped: %w", &AmbiguousTargetError{Node: n})
+
+		var ambiguous *AmbiguousTargetError
+		if assert.True(t, errors.As(err, &ambiguous)) {
+			assert.Same(t, n, ambiguous.Node)
+		}
+	})
+}
+
+func TestRenderError(t *testing.T) {
+	t.Parallel()
+
+	sadness := errors.New("great sadness")
+
+	tests := []struct {
+		desc    string
+		give    *RenderError
+		wantMsg string
+	}{
+		{
+			desc: "source and line",
+			give: &RenderError{
+				Op:   "resolve",
+				Node: &Node{Target: []byte("Foo"), Fragment: []byte("Bar"), source: "notes/index.md"},
+				Line: 12,
+				Err:  sadness,
+			},
+			wantMsg: `notes/index.md:12: resolve "Foo#Bar": great sadness`,
+		},
+		{
+			desc: "source only",
+			give: &RenderError{
+				Op:   "render embed",
+				Node: &Node{Target: []byte("cat.png"), source: "notes/index.md"},
+				Err:  sadness,
+			},
+			wantMsg: `notes/index.md: render embed "cat.png": great sadness`,
+		},
+		{
+			desc: "line only",
+			give: &RenderError{
+				Op:   "resolve",
+				Node: &Node{Target: []byte("Foo")},
+				Line: 3,
+				Err:  sadness,
+			},
+			wantMsg: `line 3: resolve "Foo": great sadness`,
+		},
+		{
+			desc:    "no node",
+			give:    &RenderError{Op: "resolve", Err: sadness},
+			wantMsg: `resolve: great sadness`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.EqualError(t, tt.give, tt.wantMsg)
+			assert.ErrorIs(t, tt.give, sadness)
+		})
+	}
+}
+
+func TestRenderError_nodeError(t *testing.T) {
+	t.Parallel()
+
+	n := &Node{Target: []byte("Foo"), source: "notes/index.md"}
+
+	tests := []struct {
+		desc    string
+		give    error
+		wantMsg string
+	}{
+		{
+			desc:    "not found",
+			give:    &TargetNotFoundError{Node: n},
+			wantMsg: `resolve: notes/index.md: "Foo": target not found`,
+		},
+		{
+			desc:    "ambiguous",
+			give:    &AmbiguousTargetError{Node: n, Candidates: []string{"a/Foo", "b/Foo"}},
+			wantMsg: `resolve: notes/index.md: "Foo": ambiguous target: could be a/Foo, b/Foo`,
+		},
+		{
+			desc:    "invalid",
+			give:    fmt.Errorf("look up: %w", &InvalidTargetError{Node: n, Reason: "outside the vault"}),
+			wantMsg: `resolve: look up: notes/index.md: "Foo": invalid target: outside the vault`,
+		},
+		{
+			desc:    "without node",
+			give:    &TargetNotFoundError{},
+			wantMsg: `notes/index.md:12: resolve "Foo": target not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := &RenderError{Op: "resolve", Node: n, Line: 12, Err: tt.give}
+			assert.EqualError(t, err, tt.wantMsg)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package wikilink
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"path"
+
+	"github.com/yuin/goldmark/util"
+)
+
+// DefaultExcalidrawExports is the default list of extensions
+// that ExcalidrawEmbed looks for exported drawings with.
+var DefaultExcalidrawExports = []string{".svg", ".png"}
+
+// ExcalidrawEmbed is an EmbedHandler for drawings made with the
+// Excalidraw plugin for Obsidian.
+// Register it for both the ".excalidraw" and ".excalidraw.md" extensions.
+//
+//	excalidraw := &wikilink.ExcalidrawEmbed{FS: os.DirFS("vault")}
+//	&wikilink.Extender{
+//		EmbedHandlers: map[string]wikilink.EmbedHandler{
+//			".excalidraw":    excalidraw,
+//			".excalidraw.md": excalidraw,
+//		},
+//	}
+//
+// Drawings are rendered as images if an exported copy of the drawing
+// exists next to it. For "sketch.excalidraw.md", ExcalidrawEmbed looks
+// for the following files in order:
+//
+//	sketch.excalidraw.svg
+//	sketch.svg
+//	sketch.excalidraw.png
+//	sketch.png
+//
+// Drawings without an exported copy are rendered as a placeholder.
+//
+//	<span class="wikilink-excalidraw">sketch</span>
+type ExcalidrawEmbed struct {
+	// FS holds the exported drawings.
+	//
+	// Files are opened at the destination returned by the Resolver
+	// with its extension replaced, and without its query, fragment,
+	// or leading "/".
+	FS fs.FS
+
+	// Exports is the list of extensions of exported drawings
+	// in order of preference.
+	//
+	// Defaults to DefaultExcalidrawExports if unspecified.
+	Exports []string
+
+	// Placeholder, if set, renders drawings that have not been exported
+	// instead of the default placeholder.
+	Placeholder func(w util.BufWriter, n *Node) error
+}
+
+var _ EmbedHandler = (*ExcalidrawEmbed)(nil)
+
+var (
+	_md         = []byte(".md")
+	_excalidraw = []byte(".excalidraw")
+)
+
+// RenderEmbed renders the exported copy of the drawing for n,
+// or a placeholder if there isn't one.
+func (e *ExcalidrawEmbed) RenderEmbed(w util.BufWriter, src []byte, n *Node, dest []byte) error {
+	// Split the destination into the path and the query or fragment
+	// so that only the path is changed.
+	var suffix []byte
+	if idx := bytes.IndexAny(dest, "?#"); idx >= 0 {
+		dest, suffix = dest[:idx], dest[idx:]
+	}
+	stem := bytes.TrimSuffix(dest, _md)          // sketch.excalidraw
+	short := bytes.TrimSuffix(stem, _excalidraw) // sketch
+	label := path.Base(string(short))
+
+	exports := e.Exports
+	if exports == nil {
+		exports = DefaultExcalidrawExports
+	}
+
+	for _, ext := range exports {
+		for _, s := range [][]byte{stem, short} {
+			name := embedPath(append(s[:len(s):len(s)], ext...))
+			if !fs.ValidPath(name) {
+				continue
+			}
+			if _, err := fs.Stat(e.FS, name); err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				return err
+			}
+
+			var url []byte
+			url = append(url, s...)
+			url = append(url, ext...)
+			url = append(url, suffix...)
+
+			alt := label
+			if l := excalidrawLabel(src, n); len(l) > 0 {
+				alt = string(l)
+			}
+
+			_, _ = w.WriteString(`<img src="`)
+			_, _ = w.Write(util.EscapeHTML(util.URLEscape(n.EmbedURL(url), true /* resolve references */)))
+			_, _ = w.WriteString(`" alt="`)
+			_, _ = w.Write(util.EscapeHTML([]byte(alt)))
+			_, _ = w.WriteString(`">`)
+			return nil
+		}
+	}
+
+	if e.Placeholder != nil {
+		return e.Placeholder(w, n)
+	}
+	_, _ = w.WriteString(`<span class="wikilink-excalidraw">`)
+	_, _ = w.Write(util.EscapeHTML([]byte(label)))
+	_, _ = w.WriteString(`</span>`)
+	return nil
+}
+
+// excalidrawLabel returns the label of n if it was set explicitly
+// with the ![[...|...]] form.
+func excalidrawLabel(src []byte, n *Node) []byte {
+	l := labelText(src, n)
+	if bytes.Equal(l, n.Target) {
+		return nil
+	}
+	if len(n.Fragment) > 0 &&
+		len(l) == len(n.Target)+1+len(n.Fragment) &&
+		bytes.HasPrefix(l, n.Target) && bytes.HasSuffix(l, n.Fragment) {
+		return nil // [[target#fragment]]
+	}
+	return l
+}
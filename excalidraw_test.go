@@ -0,0 +1,113 @@
+package wikilink
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/util"
+)
+
+func TestExcalidrawEmbed(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"sketch.excalidraw.md":    {},
+		"sketch.excalidraw.svg":   {},
+		"diagrams/flow.md":        {},
+		"diagrams/flow.png":       {},
+		"only-png.excalidraw":     {},
+		"only-png.excalidraw.png": {},
+	}
+
+	tests := []struct {
+		desc    string
+		exports []string
+		give    string
+		want    string
+	}{
+		{
+			desc: "svg next to md",
+			give: "![[sketch.excalidraw.md]]",
+			want: `<img src="sketch.excalidraw.svg" alt="sketch">`,
+		},
+		{
+			desc: "short name with label",
+			give: "![[diagrams/flow.excalidraw.md|The flow]]",
+			want: `<img src="diagrams/flow.png" alt="The flow">`,
+		},
+		{
+			desc: "png fallback",
+			give: "![[only-png.excalidraw#frag]]",
+			want: `<img src="only-png.excalidraw.png#frag" alt="only-png">`,
+		},
+		{
+			desc:    "exports preference",
+			exports: []string{".png"},
+			give:    "![[sketch.excalidraw.md]]",
+			want:    `<span class="wikilink-excalidraw">sketch</span>`,
+		},
+		{
+			desc: "placeholder",
+			give: "![[missing.excalidraw.md]]",
+			want: `<span class="wikilink-excalidraw">missing</span>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			excalidraw := &ExcalidrawEmbed{FS: files, Exports: tt.exports}
+			md := goldmark.New(goldmark.WithExtensions(&Extender{
+				EmbedHandlers: map[string]EmbedHandler{
+					".excalidraw":    excalidraw,
+					".excalidraw.md": excalidraw,
+				},
+			}))
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, "<p>"+tt.want+"</p>", strings.TrimSpace(buf.String()))
+		})
+	}
+
+	t.Run("custom placeholder", func(t *testing.T) {
+		t.Parallel()
+
+		md := goldmark.New(goldmark.WithExtensions(&Extender{
+			EmbedHandlers: map[string]EmbedHandler{
+				".excalidraw.md": &ExcalidrawEmbed{
+					FS: files,
+					Placeholder: func(w util.BufWriter, n *Node) error {
+						_, _ = w.WriteString("<em>not exported</em>")
+						return nil
+					},
+				},
+			},
+		}))
+
+		var buf bytes.Buffer
+		require.NoError(t, md.Convert([]byte("![[missing.excalidraw.md]]"), &buf))
+		assert.Equal(t, "<p><em>not exported</em></p>", strings.TrimSpace(buf.String()))
+	})
+
+	t.Run("other md embeds", func(t *testing.T) {
+		t.Parallel()
+
+		md := goldmark.New(goldmark.WithExtensions(&Extender{
+			EmbedHandlers: map[string]EmbedHandler{
+				".excalidraw.md": &ExcalidrawEmbed{FS: files},
+			},
+		}))
+
+		var buf bytes.Buffer
+		require.NoError(t, md.Convert([]byte("![[notes.md]]"), &buf))
+		assert.Equal(t, `<p><a href="notes.md">notes.md</a></p>`, strings.TrimSpace(buf.String()))
+	})
+}
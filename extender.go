@@ -1,7 +1,10 @@
 package wikilink
 
 import (
+	"log/slog"
+
 	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer"
 	"github.com/yuin/goldmark/util"
@@ -14,15 +17,349 @@ type Extender struct {
 	//
 	// Uses DefaultResolver if unspecified.
 	Resolver Resolver
+
+	// URLResolver and URLSchemes control how wikilinks to absolute URLs,
+	// like [[https://example.com]], are resolved.
+	//
+	// See Renderer.URLResolver and Renderer.URLSchemes for details.
+	URLResolver Resolver
+	URLSchemes  []string
+
+	// EmbedHandlers renders embeds of files by their extension.
+	//
+	// See Renderer.EmbedHandlers for details.
+	EmbedHandlers map[string]EmbedHandler
+
+	// ImageSetResolver supplies alternative sources for image embeds.
+	//
+	// See Renderer.ImageSetResolver for details.
+	ImageSetResolver ImageSetResolver
+
+	// ImageLoading and ImageDecoding set the loading and decoding
+	// attributes of images rendered for embeds.
+	//
+	//	&wikilink.Extender{
+	//		ImageLoading:  "lazy",
+	//		ImageDecoding: "async",
+	//	}
+	//
+	// See Renderer.ImageLoading and Renderer.ImageDecoding for details.
+	ImageLoading  string
+	ImageDecoding string
+
+	// ImageFigure renders labeled image embeds inside a <figure>
+	// with the label as the caption.
+	//
+	// See Renderer.ImageFigure for details.
+	ImageFigure bool
+
+	// ImageAltFromFilename uses the file name of unlabeled image
+	// embeds as their alt text.
+	//
+	// See Renderer.ImageAltFromFilename for details.
+	ImageAltFromFilename bool
+
+	// MarkUnresolved wraps unresolved wikilinks in a <span>
+	// marked as a disabled link for assistive technology.
+	//
+	// See Renderer.MarkUnresolved for details.
+	MarkUnresolved bool
+
+	// UnresolvedAttrs replaces the attributes of the <span>
+	// that MarkUnresolved adds.
+	//
+	// See Renderer.UnresolvedAttrs for details.
+	UnresolvedAttrs map[string]string
+
+	// IconLabel returns the aria-label of wikilinks
+	// whose labels have no text, like icons.
+	//
+	// See Renderer.IconLabel for details.
+	IconLabel func(n *Node) string
+
+	// CommentUnresolved follows unresolved wikilinks with an HTML comment
+	// naming their target.
+	//
+	// See Renderer.CommentUnresolved for details.
+	CommentUnresolved bool
+
+	// MarkPrivate wraps wikilinks to private targets in a <span>.
+	//
+	// See Renderer.MarkPrivate for details.
+	MarkPrivate bool
+
+	// MissingURL is the destination of unresolved wikilinks.
+	//
+	// See Renderer.MissingURL for details.
+	MissingURL string
+
+	// Report, if set, records every rendered wikilink.
+	//
+	// See LinkReport for details.
+	Report *LinkReport
+
+	// AllowSoftLineBreaks allows wikilinks to span multiple lines
+	// of the same paragraph.
+	//
+	// See Parser.AllowSoftLineBreaks for details.
+	AllowSoftLineBreaks bool
+
+	// MaxTargetLength and InvalidTargetChars restrict which wikilinks
+	// are parsed. Use these when rendering untrusted content.
+	//
+	// See Parser.MaxTargetLength and Parser.InvalidTargetChars
+	// for details.
+	MaxTargetLength    int
+	InvalidTargetChars string
+
+	// DisabledIn lists the kinds of nodes inside which wikilinks
+	// are not parsed.
+	//
+	// See Parser.DisabledIn for details.
+	DisabledIn []ast.NodeKind
+
+	// DecodeTargets decodes percent-encoded wikilink targets
+	// before they're resolved.
+	//
+	// See Parser.DecodeTargets for details.
+	DecodeTargets bool
+
+	// ConvertBackslashes replaces backslashes in wikilink targets
+	// with forward slashes before they're resolved.
+	//
+	// See Parser.ConvertBackslashes for details.
+	ConvertBackslashes bool
+
+	// PreferReferenceLinks lets reference links and footnote references
+	// take precedence over wikilinks when both could match.
+	//
+	// See Parser.PreferReferenceLinks for details.
+	PreferReferenceLinks bool
+
+	// PipeAttributes parses "key=value" segments after the label,
+	// like [[target|label|class=hero]], into HTML attributes.
+	//
+	// See Parser.PipeAttributes for details.
+	PipeAttributes bool
+
+	// MarkdownLabels parses the labels of wikilinks as inline Markdown
+	// with the same syntax as the rest of the document.
+	//
+	// See Parser.LabelParser for details.
+	MarkdownLabels bool
+
+	// AllowQuery parses query strings in wikilink targets.
+	//
+	// See Parser.AllowQuery for details.
+	AllowQuery bool
+
+	// Site is an arbitrary site-wide value made available to resolvers
+	// through Node.Site.
+	//
+	// See Parser.Site for details.
+	Site any
+
+	// FragmentSeparator is the character that separates targets from
+	// fragments in wikilinks, and FragmentPrefix is added to the start
+	// of fragments in resolved destinations.
+	//
+	// See Parser.FragmentSeparator and Renderer.FragmentPrefix
+	// for details.
+	FragmentSeparator byte
+	FragmentPrefix    string
+
+	// Rel, if set, determines the rel attribute of each rendered link.
+	//
+	// See Renderer.Rel for details.
+	Rel func(n *Node, dest []byte) string
+
+	// DownloadExtensions marks links to files with these extensions
+	// as downloads.
+	//
+	// See Renderer.DownloadExtensions for details.
+	DownloadExtensions []string
+
+	// BlockIDs turns Obsidian block IDs ("^id" at the end of a block)
+	// into id attributes so that wikilinks to them have a place to land.
+	// The ids use FragmentPrefix as their prefix.
+	//
+	// See BlockIDTransformer for details.
+	BlockIDs bool
+
+	// ConvertMarkdownLinks turns regular Markdown links to other Markdown
+	// documents, like [text](Foo.md), into wikilinks.
+	//
+	// See MarkdownLinkTransformer for details.
+	ConvertMarkdownLinks bool
+
+	// HTMLBlocks parses wikilinks inside HTML blocks,
+	// like <div>[[Foo]]</div>, which are otherwise left as-is.
+	//
+	// See HTMLTransformer.Blocks for details.
+	HTMLBlocks bool
+
+	// SkipInlineHTML leaves wikilinks between inline HTML tags,
+	// like <b>[[Foo]]</b>, as-is.
+	//
+	// See HTMLTransformer.SkipInline for details.
+	SkipInlineHTML bool
+
+	// Hashtags parses hashtags, like #golang, as wikilinks to the pages
+	// of their tags, with a priority that doesn't conflict with
+	// wikilinks or the rest of Markdown.
+	// Use this instead of a separate hashtag extension.
+	//
+	// See HashtagParser for details.
+	Hashtags bool
+
+	// HashtagPrefix is added to hashtags to form their targets.
+	//
+	// See HashtagParser.Prefix for details.
+	HashtagPrefix string
+
+	// NormalizeDestinations cleans up duplicate slashes and dot
+	// segments in resolved destinations.
+	//
+	// See Renderer.NormalizeDestinations for details.
+	NormalizeDestinations bool
+
+	// RelativeDestinations rewrites root-relative destinations
+	// relative to the page being rendered.
+	//
+	// See Renderer.RelativeDestinations for details.
+	RelativeDestinations bool
+
+	// PageURL returns the URL path of the page for a document.
+	//
+	// See Renderer.PageURL for details.
+	PageURL func(source string) string
+
+	// RawUnicode writes non-ASCII characters in destinations as-is
+	// instead of percent-encoding them.
+	//
+	// See Renderer.RawUnicode for details.
+	RawUnicode bool
+
+	// Logger, if set, receives a record for every rendered wikilink.
+	//
+	// See Renderer.Logger for details.
+	Logger *slog.Logger
+
+	// Metrics, if set, counts the outcomes of rendered wikilinks.
+	//
+	// See Metrics for details.
+	Metrics *Metrics
+
+	// Assets, if set, collects the files referenced by rendered wikilinks.
+	//
+	// See AssetSet for details.
+	Assets *AssetSet
+
+	// AssetBaseURLs maps file extensions to base URLs, like that of a CDN,
+	// for the destinations of wikilinks to those files.
+	//
+	// See Renderer.AssetBaseURLs for details.
+	AssetBaseURLs map[string]string
+
+	// Fingerprint rewrites the destinations of wikilinks to files
+	// for cache busting.
+	//
+	// See Renderer.Fingerprint for details.
+	Fingerprint func(n *Node, dest []byte) ([]byte, error)
+
+	// Observer, if set, is called with every rendered wikilink
+	// and the result of resolving it.
+	//
+	// See Renderer.Observer for details.
+	Observer func(n *Node, dest []byte, err error)
+
+	// Trace records the steps taken to resolve each wikilink.
+	//
+	// See Renderer.Trace for details.
+	Trace bool
 }
 
 // Extend extends the provided Markdown object with support for wikilinks.
 func (e *Extender) Extend(md goldmark.Markdown) {
+	var labelParser parser.Parser
+	if e.MarkdownLabels {
+		labelParser = md.Parser()
+	}
+
+	p := &Parser{
+		AllowSoftLineBreaks:  e.AllowSoftLineBreaks,
+		MaxTargetLength:      e.MaxTargetLength,
+		InvalidTargetChars:   e.InvalidTargetChars,
+		DisabledIn:           e.DisabledIn,
+		DecodeTargets:        e.DecodeTargets,
+		ConvertBackslashes:   e.ConvertBackslashes,
+		AllowQuery:           e.AllowQuery,
+		Site:                 e.Site,
+		FragmentSeparator:    e.FragmentSeparator,
+		PreferReferenceLinks: e.PreferReferenceLinks,
+		LabelParser:          labelParser,
+		PipeAttributes:       e.PipeAttributes,
+	}
+
 	// The link parser is at priority 200 in goldmark so we need to be
 	// lower than that to ensure that the "[" trigger fires.
 	md.Parser().AddOptions(
 		parser.WithInlineParsers(
-			util.Prioritized(&Parser{}, 199),
+			util.Prioritized(p, 199),
+		),
+	)
+
+	if e.HTMLBlocks || e.SkipInlineHTML {
+		md.Parser().AddOptions(
+			parser.WithASTTransformers(
+				util.Prioritized(&HTMLTransformer{
+					Parser:     p,
+					Blocks:     e.HTMLBlocks,
+					SkipInline: e.SkipInlineHTML,
+				}, 100),
+			),
+		)
+	}
+
+	// Hashtags go after all of goldmark's inline parsers, the last of
+	// which is at priority 500, so that "#" in code spans, autolinks,
+	// and raw HTML is left alone. Wikilinks are parsed before
+	// hashtags get a chance to see the "#" inside them.
+	if e.Hashtags {
+		md.Parser().AddOptions(
+			parser.WithInlineParsers(
+				util.Prioritized(&HashtagParser{
+					Prefix:     e.HashtagPrefix,
+					DisabledIn: e.DisabledIn,
+					Site:       e.Site,
+				}, 999),
+			),
+		)
+	}
+
+	if e.BlockIDs {
+		md.Parser().AddOptions(
+			parser.WithASTTransformers(
+				util.Prioritized(&BlockIDTransformer{
+					Prefix: e.FragmentPrefix,
+				}, 100),
+			),
+		)
+	}
+
+	if e.ConvertMarkdownLinks {
+		md.Parser().AddOptions(
+			parser.WithASTTransformers(
+				util.Prioritized(&MarkdownLinkTransformer{}, 100),
+			),
+		)
+	}
+
+	// Embeds are lifted out of their paragraphs after the other
+	// transformers have changed them.
+	md.Parser().AddOptions(
+		parser.WithASTTransformers(
+			util.Prioritized(&EmbedBlockTransformer{}, 200),
 		),
 	)
 
@@ -31,7 +368,36 @@ func (e *Extender) Extend(md goldmark.Markdown) {
 	md.Renderer().AddOptions(
 		renderer.WithNodeRenderers(
 			util.Prioritized(&Renderer{
-				Resolver: e.Resolver,
+				Resolver:              e.Resolver,
+				URLResolver:           e.URLResolver,
+				URLSchemes:            e.URLSchemes,
+				EmbedHandlers:         e.EmbedHandlers,
+				ImageSetResolver:      e.ImageSetResolver,
+				ImageLoading:          e.ImageLoading,
+				ImageDecoding:         e.ImageDecoding,
+				ImageFigure:           e.ImageFigure,
+				ImageAltFromFilename:  e.ImageAltFromFilename,
+				MarkUnresolved:        e.MarkUnresolved,
+				UnresolvedAttrs:       e.UnresolvedAttrs,
+				IconLabel:             e.IconLabel,
+				CommentUnresolved:     e.CommentUnresolved,
+				MarkPrivate:           e.MarkPrivate,
+				MissingURL:            e.MissingURL,
+				FragmentPrefix:        e.FragmentPrefix,
+				Rel:                   e.Rel,
+				DownloadExtensions:    e.DownloadExtensions,
+				NormalizeDestinations: e.NormalizeDestinations,
+				RelativeDestinations:  e.RelativeDestinations,
+				PageURL:               e.PageURL,
+				RawUnicode:            e.RawUnicode,
+				Report:                e.Report,
+				Logger:                e.Logger,
+				Metrics:               e.Metrics,
+				Assets:                e.Assets,
+				AssetBaseURLs:         e.AssetBaseURLs,
+				Fingerprint:           e.Fingerprint,
+				Observer:              e.Observer,
+				Trace:                 e.Trace,
 			}, 199),
 		),
 	)
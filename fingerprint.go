@@ -0,0 +1,59 @@
+package wikilink
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+)
+
+// FingerprintQuery returns a function for Renderer.Fingerprint
+// that adds a "v" query parameter with a hash of the contents
+// of the file to the destinations of wikilinks to files.
+// Files are read from fsys at their destinations,
+// without the query, fragment, or leading "/".
+//
+//	&wikilink.Extender{
+//		Fingerprint: wikilink.FingerprintQuery(os.DirFS("public")),
+//	}
+//	// [[logo.png]] => <a href="logo.png?v=3a7bd3e2">
+//
+// The hash is the first 8 hexadecimal digits of the SHA-256 of the file.
+func FingerprintQuery(fsys fs.FS) func(*Node, []byte) ([]byte, error) {
+	return func(_ *Node, dest []byte) ([]byte, error) {
+		name := embedPath(dest)
+		if !fs.ValidPath(name) {
+			return nil, fmt.Errorf("invalid asset path %q", name)
+		}
+
+		f, err := fsys.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return nil, err
+		}
+		sum := hex.EncodeToString(h.Sum(nil))[:8]
+
+		path, fragment := dest, []byte(nil)
+		if idx := bytes.IndexByte(dest, '#'); idx >= 0 {
+			path, fragment = dest[:idx], dest[idx:]
+		}
+
+		out := make([]byte, 0, len(dest)+len("&v=")+len(sum))
+		out = append(out, path...)
+		if bytes.IndexByte(path, '?') >= 0 {
+			out = append(out, '&')
+		} else {
+			out = append(out, '?')
+		}
+		out = append(out, "v="...)
+		out = append(out, sum...)
+		return append(out, fragment...), nil
+	}
+}
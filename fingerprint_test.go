@@ -0,0 +1,91 @@
+package wikilink
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestFingerprintQuery(t *testing.T) {
+	t.Parallel()
+
+	fp := FingerprintQuery(fstest.MapFS{
+		"img/logo.png": {Data: []byte("hello")},
+	})
+
+	tests := []struct {
+		give string
+		want string
+	}{
+		{give: "img/logo.png", want: "img/logo.png?v=2cf24dba"},
+		{give: "/img/logo.png#top", want: "/img/logo.png?v=2cf24dba#top"},
+		{give: "img/logo.png?w=100", want: "img/logo.png?w=100&v=2cf24dba"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := fp(&Node{}, []byte(tt.give))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := fp(&Node{}, []byte("missing.png"))
+		require.Error(t, err)
+	})
+}
+
+func TestRenderer_Fingerprint(t *testing.T) {
+	t.Parallel()
+
+	assets := new(AssetSet)
+	md := goldmark.New(goldmark.WithExtensions(&Extender{
+		Resolver: NewRootResolver("/"),
+		Assets:   assets,
+		Fingerprint: FingerprintQuery(fstest.MapFS{
+			"logo.png":   {Data: []byte("hello")},
+			"report.pdf": {Data: []byte("hello")},
+		}),
+		AssetBaseURLs: map[string]string{".png": "https://cdn.example.com/"},
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, md.Convert([]byte("![[logo.png]] [[report.pdf]] [[Notes]]"), &buf))
+	assert.Equal(t,
+		`<p><img src="https://cdn.example.com/logo.png?v=2cf24dba"> `+
+			`<a href="/report.pdf?v=2cf24dba">report.pdf</a> `+
+			`<a href="/Notes/">Notes</a></p>`,
+		strings.TrimSpace(buf.String()))
+
+	var dests []string
+	for _, a := range assets.Assets() {
+		dests = append(dests, a.Destination)
+	}
+	assert.Equal(t, []string{"/logo.png", "/report.pdf"}, dests)
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		sadness := errors.New("great sadness")
+		md := goldmark.New(goldmark.WithExtensions(&Extender{
+			Fingerprint: func(*Node, []byte) ([]byte, error) {
+				return nil, sadness
+			},
+		}))
+		err := md.Convert([]byte("[[logo.png]]"), new(bytes.Buffer))
+		assert.ErrorIs(t, err, sadness)
+		assert.Contains(t, err.Error(), "fingerprint")
+	})
+}
@@ -0,0 +1,203 @@
+package wikilink
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PageMeta is the publication state of a page,
+// as read from the YAML front matter of its Markdown source.
+//
+//	---
+//	title: Hello
+//	draft: true
+//	date: 2024-01-15
+//	expiryDate: 2025-01-15
+//	---
+type PageMeta struct {
+	// Draft reports whether the page is a draft
+	// that isn't published yet.
+	Draft bool `yaml:"draft"`
+
+	// Date is the date of the page.
+	// The page isn't published before this unless PublishDate is set.
+	Date time.Time `yaml:"date"`
+
+	// PublishDate, if set, is when the page is published.
+	PublishDate time.Time `yaml:"publishDate"`
+
+	// ExpiryDate, if set, is when the page stops being published.
+	ExpiryDate time.Time `yaml:"expiryDate"`
+
+	// Aliases are other names for the page, like its names before it was
+	// renamed. A single alias may be written as a string instead of a
+	// list.
+	//
+	// Aliases starting with "/" are URLs of the page, as in Hugo.
+	// Others are targets, as in Obsidian.
+	Aliases []string `yaml:"aliases"`
+
+	// Title is the title of the page, if it's different from its name.
+	Title string `yaml:"title"`
+
+	// Tags are the tags of the page, without a leading "#".
+	// They may also be written as a single string
+	// separated by commas or spaces, as in Obsidian.
+	Tags []string `yaml:"tags"`
+}
+
+// UnmarshalYAML decodes a PageMeta from YAML,
+// accepting a single string for Aliases and Tags.
+func (m *PageMeta) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.MappingNode {
+		// Work on a copy so the caller's node isn't modified.
+		mapping := *value
+		mapping.Content = append([]*yaml.Node(nil), value.Content...)
+		for i := 1; i < len(mapping.Content); i += 2 {
+			key, val := mapping.Content[i-1], mapping.Content[i]
+			if val.Kind != yaml.ScalarNode || val.Tag == "!!null" {
+				continue
+			}
+			switch key.Value {
+			case "aliases":
+				mapping.Content[i] = &yaml.Node{
+					Kind:    yaml.SequenceNode,
+					Tag:     "!!seq",
+					Content: []*yaml.Node{val},
+				}
+			case "tags":
+				seq := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+				for _, tag := range strings.FieldsFunc(val.Value, isTagSeparator) {
+					seq.Content = append(seq.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: tag})
+				}
+				mapping.Content[i] = seq
+			}
+		}
+		value = &mapping
+	}
+
+	type plain PageMeta // without this method
+	if err := value.Decode((*plain)(m)); err != nil {
+		return err
+	}
+
+	tags := m.Tags[:0]
+	for _, tag := range m.Tags {
+		if tag = strings.TrimPrefix(strings.TrimSpace(tag), "#"); len(tag) > 0 {
+			tags = append(tags, tag)
+		}
+	}
+	m.Tags = tags
+	return nil
+}
+
+func isTagSeparator(r rune) bool {
+	return r == ',' || unicode.IsSpace(r)
+}
+
+// Published reports whether the page is published at the given time:
+// it isn't a draft, its publish date has passed, and it hasn't expired.
+// This matches how Hugo decides which pages to build.
+func (m PageMeta) Published(now time.Time) bool {
+	if m.Draft {
+		return false
+	}
+
+	publish := m.PublishDate
+	if publish.IsZero() {
+		publish = m.Date
+	}
+	if !publish.IsZero() && publish.After(now) {
+		return false
+	}
+	return m.ExpiryDate.IsZero() || m.ExpiryDate.After(now)
+}
+
+// _frontMatterDelim opens and closes YAML front matter.
+var _frontMatterDelim = []byte("---")
+
+// ParsePageMeta reads the PageMeta from the YAML front matter
+// at the start of the Markdown source src.
+// Sources without front matter have a zero PageMeta.
+func ParsePageMeta(src []byte) (PageMeta, error) {
+	var meta PageMeta
+	fm, _, ok := frontMatter(src)
+	if !ok {
+		return meta, nil
+	}
+	if err := yaml.Unmarshal(fm, &meta); err != nil {
+		return meta, fmt.Errorf("parse front matter: %w", err)
+	}
+	return meta, nil
+}
+
+// LoadPageMeta reads the PageMeta of all Markdown files in fsys.
+// The results are keyed by the paths of the files without the ".md"
+// extension, the way they're written as wikilink targets.
+//
+//	pages, err := wikilink.LoadPageMeta(os.DirFS("content"))
+//	// pages["posts/hello"] is the PageMeta of content/posts/hello.md
+func LoadPageMeta(fsys fs.FS) (map[string]PageMeta, error) {
+	pages := make(map[string]PageMeta)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(path.Ext(p), ".md") {
+			return nil
+		}
+
+		src, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		meta, err := ParsePageMeta(src)
+		if err != nil {
+			return fmt.Errorf("%v: %w", p, err)
+		}
+		pages[p[:len(p)-len(".md")]] = meta
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pages, nil
+}
+
+// frontMatter returns the contents of the YAML front matter
+// at the start of src, between the "---" lines,
+// and the rest of src after it.
+func frontMatter(src []byte) (fm, body []byte, ok bool) {
+	line, rest, ok := cutLine(src)
+	if !ok || !bytes.Equal(bytes.TrimRight(line, " \t\r"), _frontMatterDelim) {
+		return nil, src, false
+	}
+
+	start := rest
+	for len(rest) > 0 {
+		var line []byte
+		end := len(start) - len(rest)
+		line, rest, _ = cutLine(rest)
+		if bytes.Equal(bytes.TrimRight(line, " \t\r"), _frontMatterDelim) {
+			return start[:end], rest, true
+		}
+	}
+	return nil, src, false
+}
+
+// cutLine splits b after its first line,
+// reporting whether the line ended with a newline.
+func cutLine(b []byte) (line, rest []byte, ok bool) {
+	idx := bytes.IndexByte(b, '\n')
+	if idx < 0 {
+		return b, nil, false
+	}
+	return b[:idx], b[idx+1:], true
+}
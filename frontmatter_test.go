@@ -0,0 +1,161 @@
+package wikilink
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePageMeta(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		give    string
+		want    PageMeta
+		wantErr string
+	}{
+		{desc: "empty"},
+		{desc: "no front matter", give: "# Hello\n\ndraft: true\n"},
+		{
+			desc: "draft",
+			give: "---\ntitle: Hello\ndraft: true\n---\n# Hello\n",
+			want: PageMeta{Draft: true, Title: "Hello"},
+		},
+		{
+			desc: "dates",
+			give: "---\ndate: 2024-01-15\npublishDate: 2024-02-01T10:00:00Z\nexpiryDate: 2025-01-15\n---\n",
+			want: PageMeta{
+				Date:        time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+				PublishDate: time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC),
+				ExpiryDate:  time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			desc: "aliases",
+			give: "---\naliases:\n  - Old Name\n  - /old/url/\n---\n",
+			want: PageMeta{Aliases: []string{"Old Name", "/old/url/"}},
+		},
+		{
+			desc: "single alias",
+			give: "---\naliases: Old Name\ndraft: true\n---\n",
+			want: PageMeta{Draft: true, Aliases: []string{"Old Name"}},
+		},
+		{
+			desc: "null aliases",
+			give: "---\naliases:\n---\n",
+		},
+		{
+			desc: "tags",
+			give: "---\ntags:\n  - go\n  - \"#lang/go\"\n  - 2024\n---\n",
+			want: PageMeta{Tags: []string{"go", "lang/go", "2024"}},
+		},
+		{
+			desc: "tags string",
+			give: "---\ntags: \"go, #lang/go notes\"\n---\n",
+			want: PageMeta{Tags: []string{"go", "lang/go", "notes"}},
+		},
+		{
+			desc: "null tags",
+			give: "---\ntags:\n---\n",
+		},
+		{
+			desc: "not draft",
+			give: "---\r\ndraft: false\r\n---\r\n",
+		},
+		{
+			desc: "empty front matter",
+			give: "---\n---\nbody\n",
+		},
+		{
+			desc: "no trailing newline",
+			give: "---\ndraft: true\n---",
+			want: PageMeta{Draft: true},
+		},
+		{
+			desc: "unclosed",
+			give: "---\ndraft: true\n",
+		},
+		{
+			desc:    "invalid",
+			give:    "---\ndraft: [\n---\n",
+			wantErr: "parse front matter",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParsePageMeta([]byte(tt.give))
+			if len(tt.wantErr) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPageMeta_Published(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-24 * time.Hour)
+	future := now.Add(24 * time.Hour)
+
+	tests := []struct {
+		desc string
+		give PageMeta
+		want bool
+	}{
+		{desc: "zero", want: true},
+		{desc: "draft", give: PageMeta{Draft: true}},
+		{desc: "past date", give: PageMeta{Date: past}, want: true},
+		{desc: "future date", give: PageMeta{Date: future}},
+		{desc: "publish date overrides date", give: PageMeta{Date: future, PublishDate: past}, want: true},
+		{desc: "future publish date", give: PageMeta{Date: past, PublishDate: future}},
+		{desc: "not expired", give: PageMeta{ExpiryDate: future}, want: true},
+		{desc: "expired", give: PageMeta{Date: past, ExpiryDate: past}},
+		{desc: "expires now", give: PageMeta{ExpiryDate: now}},
+		{desc: "published now", give: PageMeta{Date: now}, want: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, tt.give.Published(now))
+		})
+	}
+}
+
+func TestLoadPageMeta(t *testing.T) {
+	t.Parallel()
+
+	pages, err := LoadPageMeta(fstest.MapFS{
+		"index.md":         {Data: []byte("# Home\n")},
+		"posts/hello.md":   {Data: []byte("---\ndraft: true\n---\n")},
+		"posts/image.png":  {Data: []byte("---\ndraft: true\n---\n")},
+		"posts/Goodbye.MD": {Data: []byte("---\ndraft: false\n---\n")},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]PageMeta{
+		"index":         {},
+		"posts/hello":   {Draft: true},
+		"posts/Goodbye": {},
+	}, pages)
+
+	_, err = LoadPageMeta(fstest.MapFS{
+		"broken.md": {Data: []byte("---\ndraft: [\n---\n")},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "broken.md")
+}
@@ -0,0 +1,129 @@
+package wikilink
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// HashtagParser parses hashtags, like #golang, into wikilinks
+// to the pages of their tags, so that they're resolved and rendered
+// like any other wikilink.
+//
+//	#golang // => [[tags/golang|#golang]]
+//
+// Install it on your goldmark Markdown object with the Hashtags option
+// of Extender, which takes care of its priority.
+// Installing it alongside the Parser in the same goldmark object avoids
+// the conflicts of separate hashtag extensions, which don't know that
+// "#" inside a wikilink, like [[Foo#Bar]], isn't a hashtag.
+//
+// To install it directly on your goldmark Parser, give it a priority
+// after all of goldmark's own inline parsers,
+// so that "#" in code spans, autolinks, and raw HTML is left alone.
+//
+//	hashtagParser := util.Prioritized(&wikilink.HashtagParser{}, 999)
+//	goldmarkParser.AddOptions(parser.WithInlineParsers(hashtagParser))
+//
+// Following Obsidian, a hashtag must be at the start of a line
+// or follow a space, and is made of letters, numbers, "_", "-", and "/".
+// It must contain at least one character that isn't a number,
+// so #1984 isn't a hashtag.
+//
+// Resolve tag pages with TaxonomyResolver to match the URLs of
+// your site generator.
+//
+// Rendered hashtags have the "wikilink-hashtag" class.
+type HashtagParser struct {
+	// Prefix is added to the tag to form the target of the wikilink.
+	//
+	// Defaults to "tags/".
+	Prefix string
+
+	// DisabledIn lists the kinds of nodes inside which hashtags are
+	// not parsed.
+	//
+	// See Parser.DisabledIn for details.
+	DisabledIn []ast.NodeKind
+
+	// Site is an arbitrary site-wide value.
+	//
+	// See Parser.Site for details.
+	Site any
+}
+
+var _ parser.InlineParser = (*HashtagParser)(nil)
+
+// Trigger returns characters that trigger this parser.
+func (p *HashtagParser) Trigger() []byte {
+	return _hash
+}
+
+// Parse parses a hashtag.
+func (p *HashtagParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	if insideKinds(parent, p.DisabledIn) {
+		return nil
+	}
+	if prev := block.PrecendingCharacter(); prev != '\n' && !unicode.IsSpace(prev) {
+		return nil // "a#b", "&#123;", "/#foo"
+	}
+
+	line, seg := block.PeekLine()
+	tag := hashtagLen(line[1:])
+	if tag == 0 {
+		return nil
+	}
+	label := seg.WithStop(seg.Start + 1 + tag)
+
+	prefix := p.Prefix
+	if len(prefix) == 0 {
+		prefix = "tags/"
+	}
+	target := make([]byte, 0, len(prefix)+tag)
+	target = append(target, prefix...)
+	target = append(target, line[1:1+tag]...)
+
+	n := &Node{
+		Target:   target,
+		Hashtag:  true,
+		resolver: ContextResolver(pc),
+		site:     contextSite(pc, p.Site),
+		source:   ContextSource(pc),
+		ctx:      ResolveContext(pc),
+		segment:  label,
+	}
+	n.AppendChild(n, ast.NewTextSegment(label))
+
+	block.Advance(label.Len())
+	return n
+}
+
+// hashtagLen reports the length in bytes of the tag at the start of b,
+// or 0 if there isn't one.
+func hashtagLen(b []byte) int {
+	var (
+		size     int
+		nonDigit bool
+	)
+	for size < len(b) {
+		r, n := utf8.DecodeRune(b[size:])
+		switch {
+		case r == '_' || r == '-' || r == '/' || unicode.IsLetter(r) || unicode.IsMark(r):
+			nonDigit = true
+		case unicode.IsDigit(r):
+		default:
+			if !nonDigit {
+				return 0
+			}
+			return size
+		}
+		size += n
+	}
+	if !nonDigit {
+		return 0
+	}
+	return size
+}
@@ -0,0 +1,36 @@
+package wikilink
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashtagLen(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give string
+		want int
+	}{
+		{give: "golang", want: 6},
+		{give: "golang.", want: 6},
+		{give: "go-lang_2 rest", want: 9},
+		{give: "projects/wiki", want: 13},
+		{give: "日本語 text", want: 9},
+		{give: "2024", want: 0},
+		{give: "2024 text", want: 0},
+		{give: "y2k", want: 3},
+		{give: " golang", want: 0},
+		{give: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, hashtagLen([]byte(tt.give)))
+		})
+	}
+}
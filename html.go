@@ -0,0 +1,222 @@
+package wikilink
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// HTMLTransformer is a goldmark AST transformer that controls
+// whether wikilinks inside raw HTML are parsed.
+//
+// By default, goldmark leaves HTML blocks, which start with an HTML tag
+// on a line of its own, as-is, so wikilinks inside them aren't parsed.
+// Wikilinks between inline HTML tags inside a paragraph are parsed.
+//
+//	<div>[[Foo]]</div>       // HTML block: left as-is
+//	Some <b>[[Foo]]</b> text // inline HTML: becomes a link
+//
+// Set Blocks to parse wikilinks inside HTML blocks,
+// or SkipInline to leave wikilinks between inline HTML tags as-is.
+//
+// Install it on your goldmark Markdown object with the HTMLBlocks and
+// SkipInlineHTML options of Extender, or directly on a goldmark Parser
+// with WithASTTransformers.
+//
+//	goldmarkParser.AddOptions(parser.WithASTTransformers(
+//		util.Prioritized(&wikilink.HTMLTransformer{Blocks: true}, 100),
+//	))
+//
+// Remember that goldmark omits raw HTML from its output
+// unless it's configured with html.WithUnsafe.
+type HTMLTransformer struct {
+	// Parser parses wikilinks inside HTML blocks.
+	// Set this to the Parser installed on the goldmark Parser
+	// so that they're parsed with the same options.
+	//
+	// Defaults to a zero Parser.
+	Parser *Parser
+
+	// Blocks parses wikilinks inside HTML blocks.
+	// Each HTML block with wikilinks is replaced with a block of
+	// raw HTML and wikilinks.
+	//
+	// Wikilinks inside <pre>, <script>, <style>, and <textarea> blocks,
+	// and inside comments, are never parsed.
+	// Wikilinks must open and close on the same line.
+	Blocks bool
+
+	// SkipInline leaves wikilinks between inline HTML tags as-is.
+	//
+	//	Some <b>[[Foo]]</b> text // => Some <b>[[Foo]]</b> text
+	SkipInline bool
+}
+
+var _ parser.ASTTransformer = (*HTMLTransformer)(nil)
+
+// Transform parses or unparses wikilinks inside raw HTML.
+func (t *HTMLTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	src := reader.Source()
+
+	// Collect the blocks first because we'll replace them.
+	var htmlBlocks []*ast.HTMLBlock
+	var paragraphs []ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.HTMLBlock:
+			htmlBlocks = append(htmlBlocks, n)
+		case *ast.Paragraph, *ast.TextBlock, *ast.Heading:
+			paragraphs = append(paragraphs, n)
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	if t.Blocks {
+		for _, b := range htmlBlocks {
+			t.transformHTMLBlock(b, src, pc)
+		}
+	}
+	if t.SkipInline {
+		for _, p := range paragraphs {
+			skipInlineHTML(p, src)
+		}
+	}
+}
+
+// transformHTMLBlock replaces an HTML block that contains wikilinks
+// with a TextBlock holding the HTML and the wikilinks.
+func (t *HTMLTransformer) transformHTMLBlock(b *ast.HTMLBlock, src []byte, pc parser.Context) {
+	switch b.HTMLBlockType {
+	case ast.HTMLBlockType6, ast.HTMLBlockType7:
+	default:
+		return // <pre>, <script>, comments, and the like
+	}
+
+	p := t.Parser
+	if p == nil {
+		p = new(Parser)
+	}
+
+	tb := ast.NewTextBlock()
+	var found bool
+	lines := b.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		if i == lines.Len()-1 {
+			// The TextBlock adds its own newline.
+			line = line.TrimRightSpace(src)
+		}
+
+		pos := line.Start
+		for pos < line.Stop {
+			idx := bytes.Index(src[pos:line.Stop], _open)
+			if idx < 0 {
+				break
+			}
+			start := pos + idx
+			if start > line.Start && src[start-1] == '!' {
+				start--
+			}
+
+			segs := text.NewSegments()
+			segs.Append(text.NewSegment(start, line.Stop))
+
+			n, ok := p.Parse(b, text.NewBlockReader(src, segs), pc).(*Node)
+			if !ok {
+				pos = start + len(_open)
+				if src[start] == '!' {
+					pos++
+				}
+				continue
+			}
+
+			found = true
+			appendRawHTML(tb, line.Start, start)
+			tb.AppendChild(tb, n)
+			line = line.WithStart(n.Segment().Stop)
+			pos = line.Start
+		}
+		appendRawHTML(tb, line.Start, line.Stop)
+	}
+	if !found {
+		return
+	}
+
+	tb.SetBlankPreviousLines(b.HasBlankPreviousLines())
+	b.Parent().ReplaceChild(b.Parent(), b, tb)
+}
+
+// appendRawHTML appends the raw HTML between start and stop to parent.
+func appendRawHTML(parent ast.Node, start, stop int) {
+	if start >= stop {
+		return
+	}
+	raw := ast.NewRawHTML()
+	raw.Segments.Append(text.NewSegment(start, stop))
+	parent.AppendChild(parent, raw)
+}
+
+// skipInlineHTML replaces wikilinks between inline HTML tags
+// inside block with their text as written.
+func skipInlineHTML(block ast.Node, src []byte) {
+	var depth int
+	for c := block.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		switch c := c.(type) {
+		case *ast.RawHTML:
+			depth += htmlTagDepth(c, src)
+			if depth < 0 {
+				depth = 0
+			}
+		case *Node:
+			if seg := c.Segment(); depth > 0 && !seg.IsEmpty() {
+				block.ReplaceChild(block, c, ast.NewTextSegment(seg))
+			}
+		}
+		c = next
+	}
+}
+
+// _voidElements are HTML elements that have no closing tag.
+var _voidElements = map[string]struct{}{
+	"area": {}, "base": {}, "br": {}, "col": {}, "embed": {}, "hr": {},
+	"img": {}, "input": {}, "link": {}, "meta": {}, "source": {},
+	"track": {}, "wbr": {},
+}
+
+// htmlTagDepth reports how an inline HTML tag changes the nesting depth:
+// 1 for opening tags, -1 for closing tags, and 0 for everything else,
+// like comments and self-closing tags.
+func htmlTagDepth(raw *ast.RawHTML, src []byte) int {
+	var tag []byte
+	for i := 0; i < raw.Segments.Len(); i++ {
+		seg := raw.Segments.At(i)
+		tag = append(tag, seg.Value(src)...)
+	}
+
+	switch {
+	case bytes.HasPrefix(tag, []byte("</")):
+		return -1
+	case len(tag) < 2 || !isASCIILetter(tag[1]), bytes.HasSuffix(tag, []byte("/>")):
+		return 0 // <!-- -->, <?php ?>, <br/>
+	}
+
+	end := 1
+	for end < len(tag) && (isASCIILetter(tag[end]) || ('0' <= tag[end] && tag[end] <= '9')) {
+		end++
+	}
+	if _, ok := _voidElements[string(bytes.ToLower(tag[1:end]))]; ok {
+		return 0
+	}
+	return 1
+}
+
+func isASCIILetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
@@ -0,0 +1,216 @@
+package wikilink
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFunc reports whether the file or directory at the
+// slash-separated path p, relative to the root of a vault,
+// should be left out of an Index.
+// dir reports whether p is a directory;
+// nothing inside an ignored directory is indexed.
+//
+// See Index.Ignore.
+type IgnoreFunc func(p string, dir bool) bool
+
+// IgnoreAny returns an IgnoreFunc that ignores the files
+// that any of fns ignores.
+// Nil functions are skipped.
+func IgnoreAny(fns ...IgnoreFunc) IgnoreFunc {
+	return func(p string, dir bool) bool {
+		for _, fn := range fns {
+			if fn != nil && fn(p, dir) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ParseGitignore returns an IgnoreFunc that ignores the files matched by
+// the patterns in src, which uses the format of .gitignore files.
+//
+//	# comments and blank lines are skipped
+//	templates/      // directories named templates, anywhere
+//	/Archive/       // the Archive directory at the root only
+//	*.canvas        // files and directories matching a glob, anywhere
+//	drafts/**/*.md  // "**" matches any number of directories
+//	!keep.canvas    // "!" negates a previous pattern
+//
+// The patterns apply to the whole vault, as if src were the .gitignore
+// file at its root; .gitignore files in subdirectories aren't read.
+// Invalid patterns are skipped like git skips them.
+func ParseGitignore(src []byte) IgnoreFunc {
+	var rules []ignoreRule
+	for _, line := range strings.Split(string(src), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+
+		var r ignoreRule
+		switch {
+		case line[0] == '!':
+			r.Negate = true
+			line = line[1:]
+		case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.DirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if len(line) == 0 {
+			continue
+		}
+
+		// Patterns with a "/" other than at the end are relative to the
+		// root. Others match at any level.
+		anchored := strings.Contains(line, "/")
+		re, err := regexp.Compile(globRegexp(strings.TrimPrefix(line, "/"), anchored))
+		if err != nil {
+			continue
+		}
+		r.Pattern = re
+		rules = append(rules, r)
+	}
+
+	return func(p string, dir bool) bool {
+		var ignored bool
+		for _, r := range rules {
+			if (!r.DirOnly || dir) && r.Pattern.MatchString(p) {
+				ignored = !r.Negate // the last match wins
+			}
+		}
+		return ignored
+	}
+}
+
+// ignoreRule is a single pattern of a .gitignore file.
+type ignoreRule struct {
+	Pattern *regexp.Regexp
+	Negate  bool // "!foo"
+	DirOnly bool // "foo/"
+}
+
+// globRegexp translates a .gitignore glob into a regular expression
+// matching the paths that it matches.
+// Unless anchored, the glob may match at any level.
+func globRegexp(glob string, anchored bool) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**") && (i == 0 || glob[i-1] == '/') {
+				switch rest := glob[i+2:]; {
+				case len(rest) == 0: // "foo/**"
+					sb.WriteString(".*")
+					i++
+					continue
+				case rest[0] == '/': // "**/foo", "foo/**/bar"
+					sb.WriteString("(?:.*/)?")
+					i += 2
+					continue
+				}
+			}
+			for i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+			}
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == 0 && i+2 < len(glob) { // "[]abc]"
+				if e := strings.IndexByte(glob[i+2:], ']'); e >= 0 {
+					end = e + 1
+				} else {
+					end = -1
+				}
+			}
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if len(class) > 0 && class[0] == '!' {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+
+	sb.WriteString("$")
+	return sb.String()
+}
+
+// ParseObsidianExcluded returns an IgnoreFunc that ignores the files
+// excluded in the "Files and links > Excluded files" setting of Obsidian,
+// read from the contents of a vault's .obsidian/app.json file.
+//
+//	src, err := fs.ReadFile(vault, ".obsidian/app.json")
+//	...
+//	ignore, err := wikilink.ParseObsidianExcluded(src)
+//
+// Like Obsidian, each filter excludes the paths that start with it,
+// like "Templates/", unless it's wrapped in slashes,
+// like "/\.excalidraw$/", which makes it a regular expression.
+// Regular expressions use the syntax of the regexp package,
+// which lacks some features of JavaScript's, like lookarounds.
+func ParseObsidianExcluded(src []byte) (IgnoreFunc, error) {
+	var app struct {
+		UserIgnoreFilters []string `json:"userIgnoreFilters"`
+	}
+	if err := json.Unmarshal(src, &app); err != nil {
+		return nil, err
+	}
+
+	var (
+		prefixes []string
+		patterns []*regexp.Regexp
+	)
+	for _, f := range app.UserIgnoreFilters {
+		if len(f) > 2 && f[0] == '/' && f[len(f)-1] == '/' {
+			re, err := regexp.Compile(f[1 : len(f)-1])
+			if err != nil {
+				return nil, fmt.Errorf("excluded files: %w", err)
+			}
+			patterns = append(patterns, re)
+		} else if len(f) > 0 {
+			prefixes = append(prefixes, f)
+		}
+	}
+
+	return func(p string, dir bool) bool {
+		if dir {
+			p += "/" // "Templates/" excludes the Templates directory
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(p, prefix) {
+				return true
+			}
+		}
+		for _, re := range patterns {
+			if re.MatchString(p) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
@@ -0,0 +1,159 @@
+package wikilink
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitignore(t *testing.T) {
+	t.Parallel()
+
+	ignore := ParseGitignore([]byte(`# Templates and such
+templates/
+/Archive/
+*.canvas
+!keep.canvas
+drafts/**/*.md
+notes/secret.md
+\#hash.md
+files/**
+a[0-9].md
+b[!0-9].md
+what?.md
+trailing.md   ` + "\r\n" + `
+[unclosed.md
+`))
+
+	tests := []struct {
+		path string
+		dir  bool
+		want bool
+	}{
+		{path: "templates", dir: true, want: true},
+		{path: "notes/templates", dir: true, want: true},
+		{path: "templates", dir: false, want: false},
+		{path: "Archive", dir: true, want: true},
+		{path: "notes/Archive", dir: true, want: false},
+		{path: "board.canvas", want: true},
+		{path: "notes/board.canvas", want: true},
+		{path: "notes/keep.canvas", want: false},
+		{path: "drafts/a.md", want: true},
+		{path: "drafts/x/y/a.md", want: true},
+		{path: "notes/drafts/a.md", want: false},
+		{path: "notes/secret.md", want: true},
+		{path: "other/notes/secret.md", want: false},
+		{path: "#hash.md", want: true},
+		{path: "files", dir: true, want: false},
+		{path: "files/a/b.png", want: true},
+		{path: "a1.md", want: true},
+		{path: "ab.md", want: false},
+		{path: "bx.md", want: true},
+		{path: "b1.md", want: false},
+		{path: "what1.md", want: true},
+		{path: "what/.md", want: false},
+		{path: "trailing.md", want: true},
+		{path: "[unclosed.md", want: true},
+		{path: "notes/Foo.md", want: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, ignore(tt.path, tt.dir))
+		})
+	}
+}
+
+func TestParseObsidianExcluded(t *testing.T) {
+	t.Parallel()
+
+	ignore, err := ParseObsidianExcluded([]byte(`{
+  "alwaysUpdateLinks": true,
+  "userIgnoreFilters": [
+    "Templates/",
+    "Archive/Old",
+    "/\\.excalidraw\\.md$/",
+    ""
+  ]
+}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		path string
+		dir  bool
+		want bool
+	}{
+		{path: "Templates", dir: true, want: true},
+		{path: "Templates/Daily.md", want: true},
+		{path: "notes/Templates", dir: true, want: false},
+		{path: "Archive/Old notes.md", want: true},
+		{path: "Archive/New.md", want: false},
+		{path: "Drawing.excalidraw.md", want: true},
+		{path: "notes/Foo.md", want: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, ignore(tt.path, tt.dir))
+		})
+	}
+
+	t.Run("no filters", func(t *testing.T) {
+		t.Parallel()
+
+		ignore, err := ParseObsidianExcluded([]byte(`{}`))
+		require.NoError(t, err)
+		assert.False(t, ignore("Foo.md", false))
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseObsidianExcluded([]byte(`{`))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid regexp", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ParseObsidianExcluded([]byte(`{"userIgnoreFilters": ["/(/"]}`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "excluded files")
+	})
+}
+
+func TestIndex_Ignore(t *testing.T) {
+	t.Parallel()
+
+	obsidian, err := ParseObsidianExcluded([]byte(`{"userIgnoreFilters": ["Archive/"]}`))
+	require.NoError(t, err)
+
+	idx := &Index{
+		Ignore: IgnoreAny(
+			ParseGitignore([]byte("templates/\n*.canvas\n")),
+			obsidian,
+			nil,
+		),
+	}
+	require.NoError(t, idx.AddFS(fstest.MapFS{
+		"notes/Foo.md":          {},
+		"notes/board.canvas":    {},
+		"templates/Foo.md":      {Data: []byte("---\naliases: [Bar]\n---\n")},
+		"Archive/Foo.md":        {},
+		"Archive/Broken.md":     {Data: []byte("---\n: [\n---\n")},
+		"notes/templates/a.png": {},
+	}))
+
+	assert.Equal(t, []string{"notes/Foo"}, idx.Lookup("Foo"))
+	assert.Empty(t, idx.Lookup("Bar"))
+	assert.Empty(t, idx.Lookup("board.canvas"))
+	assert.Empty(t, idx.Lookup("a.png"))
+}
@@ -0,0 +1,48 @@
+package wikilink
+
+// ImageSetResolver supplies alternative sources for embedded images.
+//
+// Use it to plug an asset pipeline that generates multiple resolutions of
+// an image into the Renderer. The Renderer will render the embed with
+// srcset and sizes attributes so that browsers may pick the most
+// appropriate resolution.
+//
+//	![[chart.png]]
+//	// => <img src="chart.png" srcset="chart-480.png 480w, chart-960.png 960w" sizes="50vw">
+type ImageSetResolver interface {
+	// ResolveImageSet returns the set of alternative sources for the
+	// provided image embed. dest is the destination returned by the
+	// Resolver for the same node.
+	//
+	// If ResolveImageSet returns a nil or empty set, the image is
+	// rendered with just its src attribute.
+	// A set with a single source is still rendered into srcset,
+	// so that, for example, a "2x" source can complement src.
+	//
+	// If ResolveImageSet returns a non-nil error, rendering will be
+	// halted.
+	ResolveImageSet(n *Node, dest []byte) (*ImageSet, error)
+}
+
+// ImageSet is a collection of alternative sources for an image.
+type ImageSet struct {
+	// Sources is the list of candidate sources for the image.
+	// These are rendered in-order into the srcset attribute.
+	Sources []ImageSource
+
+	// Sizes is the value of the sizes attribute, if any.
+	//
+	//	(max-width: 600px) 480px, 800px
+	Sizes string
+}
+
+// ImageSource is a single candidate source in an ImageSet.
+type ImageSource struct {
+	// URL of this image candidate.
+	// This will be URL-escaped before being placed into the srcset.
+	URL []byte
+
+	// Descriptor is an optional width or pixel density descriptor
+	// for this candidate, e.g. "480w" or "2x".
+	Descriptor string
+}
@@ -0,0 +1,628 @@
+package wikilink
+
+import (
+	"io/fs"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Index is an index of the files in a vault
+// by the names that wikilinks use to refer to them.
+// Use it with IndexResolver to resolve wikilinks the way Obsidian does,
+// where [[Foo]] links to notes/Foo.md wherever it is in the vault.
+//
+//	idx := new(wikilink.Index)
+//	if err := idx.AddFS(os.DirFS("vault")); err != nil {
+//		return err
+//	}
+//	idx.Lookup("foo") // => ["notes/Foo"]
+//
+// Set FS instead of calling AddFS to scan the vault
+// the first time the index is used.
+//
+//	idx := &wikilink.Index{FS: os.DirFS("vault")}
+//
+// Files are registered under their paths relative to the root of the FS,
+// under their names alone, and, for Markdown notes,
+// under the aliases in their front matter.
+// Names are matched case-insensitively.
+// Notes are identified by their paths without the ".md" extension,
+// and other files, like images, by their paths as-is.
+//
+// An Index is safe for concurrent use.
+// The zero value is an empty index ready to use.
+type Index struct {
+	// Inflect, if set, returns other forms of a name, like its singular
+	// or plural, to look up when the name itself isn't in the index,
+	// so that [[tag]] finds Tags.md.
+	// Variants are tried in order and the first one in the index wins.
+	//
+	//	idx := &wikilink.Index{Inflect: wikilink.EnglishInflections}
+	//
+	// Set it before using the index.
+	Inflect func(name string) []string
+
+	// FollowSymlinks makes AddFS follow symbolic links to directories,
+	// like shared folders linked into several vaults,
+	// and index the files inside them under the paths of the links.
+	//
+	// Files reachable by more than one path are indexed once,
+	// preferring paths without symbolic links,
+	// and links that loop back to a directory that was already scanned
+	// are skipped.
+	// This relies on the fs.FS reporting the identities of files
+	// the way os.DirFS does; see os.SameFile.
+	//
+	// Set it before using the index.
+	FollowSymlinks bool
+
+	// Ignore, if set, reports the files and directories that AddFS
+	// leaves out of the index, like templates and archived notes,
+	// so that wikilinks don't resolve to them.
+	// Use ParseGitignore for patterns like those of .gitignore files,
+	// ParseObsidianExcluded for the "Excluded files" of Obsidian,
+	// and IgnoreAny to combine them.
+	//
+	// Directories starting with ".", like .obsidian and .trash,
+	// are always left out.
+	//
+	// Set it before using the index.
+	Ignore IgnoreFunc
+
+	// Workers is the number of notes that AddFS reads at once
+	// to find their aliases and titles.
+	// Raise it for vaults on network file systems,
+	// where most of the time is spent waiting for reads.
+	//
+	// Defaults to runtime.GOMAXPROCS(0).
+	// Set it before using the index.
+	Workers int
+
+	// FS, if set, is the vault to index.
+	// It's scanned like AddFS does the first time the index is used,
+	// rather than when the index is created,
+	// so that programs that never resolve a wikilink don't pay for it.
+	// Use Load to scan it earlier, or to get the error of the scan,
+	// and Reindex to scan it again.
+	//
+	// Set it before using the index.
+	FS fs.FS
+
+	// Logger, if set, receives a record when AddFS, Load, or Reindex
+	// start scanning the vault, at debug level,
+	// and when they finish, with the number of files indexed
+	// and how long it took, at info level, or the error at error level.
+	//
+	// Set it before using the index.
+	Logger *slog.Logger
+
+	// loadMu serializes Load and Reindex.
+	loadMu sync.Mutex
+	loaded atomic.Pointer[indexLoad] // nil until FS is scanned
+
+	mu     sync.RWMutex
+	names  map[string][]indexName // by lowercase name
+	titles map[string]string      // by ID
+	tags   map[string][]string    // by ID
+	files  map[string]indexFile   // by ID, scanned from FS or AddFS
+	fsyss  []fs.FS                // added with AddFS
+	ids    []string               // added with Add
+}
+
+// indexLoad is the outcome of scanning Index.FS.
+type indexLoad struct{ err error }
+
+// indexFile is where a file in an Index was scanned from.
+type indexFile struct {
+	FS   fs.FS
+	Path string
+}
+
+// indexName is a name under which a file is registered in an Index.
+type indexName struct {
+	Name  string // as written
+	ID    string // path of the file, without ".md" for notes
+	Alias bool   // whether the name is a front matter alias
+}
+
+// AddFS adds all files in fsys to the index.
+//
+// Markdown notes are read to find the aliases in their front matter
+// and their titles, so AddFS fails if a note can't be read
+// or has invalid front matter.
+// Notes are read concurrently by Workers goroutines,
+// so fsys must be safe for concurrent use, like os.DirFS.
+func (idx *Index) AddFS(fsys fs.FS) error {
+	start := idx.logStart("add")
+	s, err := idx.scan(fsys)
+	if err != nil {
+		idx.logEnd("add", start, 0, err)
+		return err
+	}
+
+	idx.mu.Lock()
+	idx.addScan(s)
+	idx.fsyss = append(idx.fsyss, fsys)
+	idx.mu.Unlock()
+	idx.logEnd("add", start, len(s.paths), nil)
+	return nil
+}
+
+// Load scans FS into the index if it hasn't been scanned yet,
+// and reports the error of the scan, if any.
+// The index is loaded automatically the first time it's used,
+// and IndexResolver reports errors from Load,
+// so call this only to scan FS ahead of time.
+// Load does nothing if FS isn't set.
+func (idx *Index) Load() error {
+	if l := idx.loaded.Load(); l != nil {
+		return l.err
+	}
+
+	idx.loadMu.Lock()
+	defer idx.loadMu.Unlock()
+	if l := idx.loaded.Load(); l != nil {
+		return l.err // loaded while we were waiting
+	}
+
+	var err error
+	if idx.FS != nil {
+		start := idx.logStart("load")
+		var s *indexScan
+		if s, err = idx.scan(idx.FS); err == nil {
+			idx.mu.Lock()
+			idx.addScan(s)
+			for id, p := range s.ids {
+				// Files in FS win over those added with AddFS,
+				// like they do in Reindex.
+				idx.files[id] = indexFile{FS: idx.FS, Path: p}
+			}
+			idx.mu.Unlock()
+			idx.logEnd("load", start, len(s.paths), nil)
+		} else {
+			idx.logEnd("load", start, 0, err)
+		}
+	}
+	idx.loaded.Store(&indexLoad{err: err})
+	return err
+}
+
+// Reindex scans FS and the file systems added with AddFS again,
+// so that the index picks up files that were added, renamed, or removed,
+// like when a preview server sees the vault change.
+// Files registered with Add are kept.
+//
+// The index keeps its current contents while it's scanned,
+// and if the scan fails.
+func (idx *Index) Reindex() error {
+	idx.loadMu.Lock()
+	defer idx.loadMu.Unlock()
+
+	idx.mu.RLock()
+	fsyss := append([]fs.FS(nil), idx.fsyss...)
+	ids := append([]string(nil), idx.ids...)
+	idx.mu.RUnlock()
+	if idx.FS != nil {
+		fsyss = append([]fs.FS{idx.FS}, fsyss...)
+	}
+
+	start := idx.logStart("reindex")
+	fresh := new(Index)
+	var files int
+	for _, fsys := range fsyss {
+		s, err := idx.scan(fsys)
+		if err != nil {
+			idx.logEnd("reindex", start, 0, err)
+			return err
+		}
+		fresh.addScan(s)
+		files += len(s.paths)
+	}
+	for _, id := range ids {
+		fresh.addID(id)
+	}
+
+	idx.mu.Lock()
+	idx.names, idx.titles, idx.tags, idx.files = fresh.names, fresh.titles, fresh.tags, fresh.files
+	idx.mu.Unlock()
+	idx.loaded.Store(&indexLoad{})
+	idx.logEnd("reindex", start, files, nil)
+	return nil
+}
+
+// scan finds the files in fsys to add to the index.
+func (idx *Index) scan(fsys fs.FS) (*indexScan, error) {
+	s := &indexScan{
+		FS:             fsys,
+		FollowSymlinks: idx.FollowSymlinks,
+		Ignore:         idx.Ignore,
+		Workers:        idx.Workers,
+	}
+	if _, err := s.Scan(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// addScan adds the files found by s.
+// The caller must hold the lock.
+func (idx *Index) addScan(s *indexScan) {
+	for _, n := range s.names {
+		idx.add(n)
+	}
+	for id, title := range s.titles {
+		if idx.titles == nil {
+			idx.titles = make(map[string]string)
+		}
+		idx.titles[id] = title
+	}
+	for id, tags := range s.tags {
+		if idx.tags == nil {
+			idx.tags = make(map[string][]string)
+		}
+		idx.tags[id] = tags
+	}
+	for id, p := range s.ids {
+		if idx.files == nil {
+			idx.files = make(map[string]indexFile)
+		}
+		if _, ok := idx.files[id]; !ok {
+			idx.files[id] = indexFile{FS: s.FS, Path: p}
+		}
+	}
+}
+
+// Title returns the title of the note with the given ID,
+// like "notes/Foo": the title in its front matter,
+// or else the text of its first level-1 heading, like "# Foo".
+// It returns "" for notes without either,
+// and for files that weren't scanned from FS or AddFS.
+func (idx *Index) Title(id string) string {
+	_ = idx.Load() // reported by IndexResolver
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return idx.titles[id]
+}
+
+// Tags returns the tags in the front matter of the note
+// with the given ID, like "notes/Foo", without a leading "#".
+// It returns nil for notes without tags,
+// and for files that weren't scanned from FS or AddFS.
+func (idx *Index) Tags(id string) []string {
+	_ = idx.Load() // reported by IndexResolver
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return append([]string(nil), idx.tags[id]...)
+}
+
+// IDs returns the IDs of all files in the index, like "notes/Foo"
+// for notes/Foo.md and "img/cat.png" for img/cat.png,
+// in lexical order.
+func (idx *Index) IDs() []string {
+	_ = idx.Load() // reported by IndexResolver
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	var ids []string
+	seen := make(map[string]struct{})
+	for _, names := range idx.names {
+		for _, n := range names {
+			if _, ok := seen[n.ID]; !ok {
+				seen[n.ID] = struct{}{}
+				ids = append(ids, n.ID)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ReadFile reads the file with the given ID, like "notes/Foo",
+// from the file system it was scanned from.
+// If more than one file system has a file with that ID,
+// it's read from FS, or else the first one added with AddFS.
+// It fails with an error matching fs.ErrNotExist
+// for files that weren't scanned from FS or AddFS.
+func (idx *Index) ReadFile(id string) ([]byte, error) {
+	f, ok := idx.file(id)
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: id, Err: fs.ErrNotExist}
+	}
+	return fs.ReadFile(f.FS, f.Path)
+}
+
+// Stat returns the fs.FileInfo of the file with the given ID,
+// like ReadFile.
+func (idx *Index) Stat(id string) (fs.FileInfo, error) {
+	f, ok := idx.file(id)
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: id, Err: fs.ErrNotExist}
+	}
+	return fs.Stat(f.FS, f.Path)
+}
+
+// file reports where the file with the given ID was scanned from,
+// if it was scanned from FS or AddFS.
+func (idx *Index) file(id string) (indexFile, bool) {
+	_ = idx.Load() // reported by IndexResolver
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	f, ok := idx.files[id]
+	return f, ok
+}
+
+// isNote reports whether the file with the given ID is a Markdown note,
+// rather than, say, an image.
+// Files registered with Add are notes if their IDs have no extension.
+func (idx *Index) isNote(id string) bool {
+	if f, ok := idx.file(id); ok {
+		return strings.EqualFold(path.Ext(f.Path), ".md")
+	}
+	return len(path.Ext(id)) == 0
+}
+
+// Add registers the file with the given ID, like "notes/Foo" for
+// notes/Foo.md, under its path and its name.
+// Use it to index files that aren't in an fs.FS.
+func (idx *Index) Add(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.addID(id)
+	idx.ids = append(idx.ids, id)
+}
+
+// addID registers the file with the given ID.
+// The caller must hold the lock.
+func (idx *Index) addID(id string) {
+	idx.add(indexName{Name: id, ID: id})
+	if base := path.Base(id); base != id {
+		idx.add(indexName{Name: base, ID: id})
+	}
+}
+
+func (idx *Index) add(n indexName) {
+	if idx.names == nil {
+		idx.names = make(map[string][]indexName)
+	}
+	key := strings.ToLower(n.Name)
+	for _, o := range idx.names[key] {
+		if o.ID == n.ID {
+			return
+		}
+	}
+	idx.names[key] = append(idx.names[key], n)
+}
+
+// Lookup returns the IDs of the files registered under the given name,
+// with or without a ".md" extension, in lexical order.
+// If no file has that name, it tries the names returned by Inflect.
+func (idx *Index) Lookup(name string) []string {
+	name = strings.TrimSuffix(name, ".md")
+	_ = idx.Load() // reported by IndexResolver
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	if ids := idx.lookup(strings.ToLower(name)); len(ids) > 0 || idx.Inflect == nil {
+		return ids
+	}
+	for _, v := range idx.Inflect(name) {
+		if ids := idx.lookup(strings.ToLower(v)); len(ids) > 0 {
+			return ids
+		}
+	}
+	return nil
+}
+
+// lookup returns the IDs registered under the lowercase key.
+// The caller must hold the lock.
+func (idx *Index) lookup(key string) []string {
+	names := idx.names[key]
+	if len(names) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(names))
+	for i, n := range names {
+		ids[i] = n.ID
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// NameCollision is a name under which more than one file
+// is registered in an Index.
+// Wikilinks to the name are ambiguous:
+// IndexResolver fails them with an AmbiguousTargetError.
+type NameCollision struct {
+	// Name as written by the first file registered under it.
+	Name string `json:"name"`
+
+	// Files registered under the name, by ID.
+	Files []NameCollisionFile `json:"files"`
+}
+
+// NameCollisionFile is a file involved in a NameCollision.
+type NameCollisionFile struct {
+	// ID of the file, like "notes/Foo".
+	ID string `json:"id"`
+
+	// Alias reports whether the file is registered under the name
+	// because of an alias in its front matter,
+	// rather than because of its name.
+	Alias bool `json:"alias,omitempty"`
+}
+
+// Collisions returns the names under which more than one file is
+// registered, including front matter aliases, so that they can be
+// renamed before wikilinks to them go wrong.
+//
+//	for _, c := range idx.Collisions() {
+//		log.Printf("%q: %v", c.Name, c.Files)
+//	}
+//
+// Collisions are sorted by name, and their files by ID.
+func (idx *Index) Collisions() []NameCollision {
+	_ = idx.Load() // reported by IndexResolver
+
+	idx.mu.RLock()
+	var collisions []NameCollision
+	for _, names := range idx.names {
+		if len(names) < 2 {
+			continue
+		}
+
+		c := NameCollision{
+			Name:  names[0].Name,
+			Files: make([]NameCollisionFile, len(names)),
+		}
+		for i, n := range names {
+			c.Files[i] = NameCollisionFile{ID: n.ID, Alias: n.Alias}
+		}
+		sort.Slice(c.Files, func(i, j int) bool {
+			return c.Files[i].ID < c.Files[j].ID
+		})
+		collisions = append(collisions, c)
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(collisions, func(i, j int) bool {
+		return strings.ToLower(collisions[i].Name) < strings.ToLower(collisions[j].Name)
+	})
+	return collisions
+}
+
+// EnglishInflections returns the singular or plural of the last word
+// of name following the regular rules of English,
+// for use as Index.Inflect.
+//
+//	EnglishInflections("tag")      // => ["tags"]
+//	EnglishInflections("Tags")     // => ["Tag"]
+//	EnglishInflections("category") // => ["categories"]
+//	EnglishInflections("horses")   // => ["hors", "horse"]
+//
+// Irregular words, like "children", aren't inflected.
+func EnglishInflections(name string) []string {
+	lower := strings.ToLower(name)
+	switch {
+	case len(lower) == 0:
+		return nil
+	case strings.HasSuffix(lower, "ies") && len(lower) > len("ies"):
+		return []string{name[:len(name)-len("ies")] + "y"}
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "is"):
+		return []string{name + "es"} // class, status, analysis
+	case strings.HasSuffix(lower, "es") && hasSibilantSuffix(lower[:len(lower)-len("es")]):
+		// "boxes" is "box", but "horses" is "horse".
+		return []string{name[:len(name)-len("es")], name[:len(name)-len("s")]}
+	case strings.HasSuffix(lower, "s"):
+		return []string{name[:len(name)-len("s")]}
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return []string{name[:len(name)-len("y")] + "ies"}
+	case hasSibilantSuffix(lower):
+		return []string{name + "es"}
+	default:
+		return []string{name + "s"}
+	}
+}
+
+// hasSibilantSuffix reports whether the plural of the word s
+// takes "es" instead of "s".
+func hasSibilantSuffix(s string) bool {
+	for _, suffix := range []string{"s", "x", "z", "ch", "sh"} {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexMatch is a file whose name is similar to a name
+// that was looked up in an Index.
+type indexMatch struct {
+	ID         string
+	Name       string  // the most similar name of the file
+	Similarity float64 // between 0 and 1
+}
+
+// closest returns up to limit files with the names most similar to name,
+// most similar first.
+// Each file is listed once, with its most similar name.
+func (idx *Index) closest(name string, limit int) []indexMatch {
+	key := []rune(strings.ToLower(strings.TrimSuffix(name, ".md")))
+	_ = idx.Load() // reported by IndexResolver
+
+	idx.mu.RLock()
+	best := make(map[string]indexMatch) // by ID
+	for k, names := range idx.names {
+		sim := similarity(key, []rune(k))
+		for _, n := range names {
+			if m, ok := best[n.ID]; !ok || sim > m.Similarity {
+				best[n.ID] = indexMatch{ID: n.ID, Name: n.Name, Similarity: sim}
+			}
+		}
+	}
+	idx.mu.RUnlock()
+
+	matches := make([]indexMatch, 0, len(best))
+	for _, m := range best {
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Similarity != matches[j].Similarity {
+			return matches[i].Similarity > matches[j].Similarity
+		}
+		return matches[i].ID < matches[j].ID
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// similarity reports how similar a and b are as 1 minus their
+// Levenshtein distance divided by the length of the longer one:
+// 1 for equal strings and 0 for completely different ones.
+func similarity(a, b []rune) float64 {
+	longest := len(a)
+	if len(b) > longest {
+		longest = len(b)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+// levenshtein returns the number of single-character insertions,
+// deletions, and substitutions needed to turn a into b.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
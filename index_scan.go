@@ -0,0 +1,246 @@
+package wikilink
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// indexScan finds the names of the files in an fs.FS for an Index.
+type indexScan struct {
+	FS             fs.FS
+	FollowSymlinks bool
+	Ignore         IgnoreFunc
+	Workers        int // notes read at once
+
+	paths  []string // of the files to index, in order
+	names  []indexName
+	titles map[string]string   // by ID
+	tags   map[string][]string // by ID
+	ids    map[string]string   // paths, by ID
+	links  []string            // symbolic links, to scan last
+	dirs   fileSet             // scanned directories
+	files  fileSet             // indexed files
+}
+
+// indexNote is what an indexScan reads from a Markdown note.
+type indexNote struct {
+	aliases []string
+	title   string
+	tags    []string
+	err     error
+}
+
+// Scan returns the names of the files in the FS.
+// Their titles are recorded in titles.
+func (s *indexScan) Scan() ([]indexName, error) {
+	if err := fs.WalkDir(s.FS, ".", s.visit); err != nil {
+		return nil, err
+	}
+
+	// Scanning links after everything else
+	// makes the real paths of files win over the linked ones.
+	// Links found inside links are appended as we go.
+	for i := 0; i < len(s.links); i++ {
+		if _, err := fs.Stat(s.FS, s.links[i]); err != nil {
+			continue // broken link
+		}
+		if err := fs.WalkDir(s.FS, s.links[i], s.visit); err != nil {
+			return nil, err
+		}
+	}
+
+	notes, err := s.readNotes()
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range s.paths {
+		s.addFile(p, notes[i])
+	}
+	return s.names, nil
+}
+
+// readNotes reads the Markdown notes among the paths with a pool of
+// workers, since reading them one by one is slow on network file systems.
+// Notes are reported in the same order as the paths.
+func (s *indexScan) readNotes() ([]indexNote, error) {
+	workers := s.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		next   atomic.Int64
+		failed atomic.Bool
+	)
+	notes := make([]indexNote, len(s.paths))
+	for w := 0; w < min(workers, len(s.paths)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !failed.Load() {
+				i := int(next.Add(1)) - 1
+				if i >= len(s.paths) {
+					return
+				}
+				notes[i] = s.readNote(s.paths[i])
+				if notes[i].err != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, n := range notes {
+		if n.err != nil {
+			return nil, n.err
+		}
+	}
+	return notes, nil
+}
+
+// readNote reads the aliases and title of the note at p.
+// Files other than notes are left alone.
+func (s *indexScan) readNote(p string) indexNote {
+	if !strings.EqualFold(path.Ext(p), ".md") {
+		return indexNote{}
+	}
+
+	src, err := fs.ReadFile(s.FS, p)
+	if err != nil {
+		return indexNote{err: err}
+	}
+	meta, err := ParsePageMeta(src)
+	if err != nil {
+		return indexNote{err: fmt.Errorf("%v: %w", p, err)}
+	}
+	return indexNote{aliases: meta.Aliases, title: noteTitle(meta, src), tags: meta.Tags}
+}
+
+func (s *indexScan) visit(p string, d fs.DirEntry, err error) error {
+	if err != nil {
+		return err
+	}
+	if p != "." && s.Ignore != nil && s.Ignore(p, d.IsDir()) {
+		if d.IsDir() {
+			return fs.SkipDir
+		}
+		return nil
+	}
+	if d.IsDir() {
+		if p != "." && strings.HasPrefix(d.Name(), ".") {
+			return fs.SkipDir // .obsidian, .git, .trash
+		}
+		if s.FollowSymlinks {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			if !s.dirs.Add(info) {
+				return fs.SkipDir // already scanned through another link
+			}
+		}
+		return nil
+	}
+
+	if s.FollowSymlinks {
+		if d.Type()&fs.ModeSymlink != 0 {
+			s.links = append(s.links, p)
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !s.files.Add(info) {
+			return nil // already indexed under another path
+		}
+	}
+
+	s.paths = append(s.paths, p)
+	return nil
+}
+
+// addFile records the names of the file at p.
+func (s *indexScan) addFile(p string, note indexNote) {
+	id := p
+	if strings.EqualFold(path.Ext(p), ".md") {
+		id = p[:len(p)-len(".md")]
+	}
+	if s.ids == nil {
+		s.ids = make(map[string]string)
+	}
+	if _, ok := s.ids[id]; !ok {
+		s.ids[id] = p
+	}
+
+	if strings.EqualFold(path.Ext(p), ".md") {
+		for _, alias := range note.aliases {
+			if len(alias) > 0 && alias[0] != '/' { // "/" for URLs
+				s.names = append(s.names, indexName{Name: alias, ID: id, Alias: true})
+			}
+		}
+		if len(note.title) > 0 {
+			if s.titles == nil {
+				s.titles = make(map[string]string)
+			}
+			s.titles[id] = note.title
+		}
+		if len(note.tags) > 0 {
+			if s.tags == nil {
+				s.tags = make(map[string][]string)
+			}
+			s.tags[id] = note.tags
+		}
+	}
+
+	s.names = append(s.names, indexName{Name: id, ID: id})
+	if base := path.Base(id); base != id {
+		s.names = append(s.names, indexName{Name: base, ID: id})
+	}
+}
+
+// noteTitle returns the title of a note: the title in its front matter,
+// or else the text of its first level-1 heading.
+func noteTitle(meta PageMeta, src []byte) string {
+	if len(meta.Title) > 0 {
+		return meta.Title
+	}
+	_, body, _ := frontMatter(src)
+	return firstTitleHeading(body)
+}
+
+// fileSet is a set of files that tells files apart with os.SameFile,
+// so that a file reachable by more than one path is only added once.
+type fileSet map[fileSetKey][]fs.FileInfo
+
+// fileSetKey narrows down the files that may be the same
+// so that os.SameFile isn't called on every file in the set.
+type fileSetKey struct {
+	Size    int64
+	ModTime int64
+}
+
+// Add adds the file to the set,
+// reporting false if the set already had it.
+func (fset *fileSet) Add(info fs.FileInfo) bool {
+	if *fset == nil {
+		*fset = make(fileSet)
+	}
+	key := fileSetKey{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+	for _, other := range (*fset)[key] {
+		if os.SameFile(info, other) {
+			return false
+		}
+	}
+	(*fset)[key] = append((*fset)[key], info)
+	return true
+}
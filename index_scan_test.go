@@ -0,0 +1,110 @@
+package wikilink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndex_FollowSymlinks(t *testing.T) {
+	t.Parallel()
+
+	shared := t.TempDir()
+	writeFile(t, filepath.Join(shared, "Shared.md"), "")
+	writeFile(t, filepath.Join(shared, "sub", "Deep.md"), "")
+	symlink(t, shared, filepath.Join(shared, "sub", "loop")) // back to shared
+
+	vault := t.TempDir()
+	writeFile(t, filepath.Join(vault, "notes", "Foo.md"), "")
+	symlink(t, shared, filepath.Join(vault, "shared"))
+	symlink(t, shared, filepath.Join(vault, "also-shared"))
+	symlink(t, filepath.Join(vault, "notes"), filepath.Join(vault, "a-notes")) // sorts first
+	symlink(t, filepath.Join(vault, "notes", "Foo.md"), filepath.Join(vault, "Link.md"))
+	symlink(t, filepath.Join(vault, "missing"), filepath.Join(vault, "broken"))
+	symlink(t, vault, filepath.Join(vault, "notes", "up"))
+
+	t.Run("follow", func(t *testing.T) {
+		t.Parallel()
+
+		idx := &Index{FollowSymlinks: true}
+		require.NoError(t, idx.AddFS(os.DirFS(vault)))
+
+		assert.Equal(t, []string{"notes/Foo"}, idx.Lookup("Foo"))
+		assert.Empty(t, idx.Lookup("Link"), "real path wins")
+		assert.Empty(t, idx.Lookup("a-notes/Foo"))
+		assert.Equal(t, []string{"also-shared/Shared"}, idx.Lookup("Shared"), "first link wins")
+		assert.Equal(t, []string{"also-shared/sub/Deep"}, idx.Lookup("Deep"))
+		assert.Empty(t, idx.Lookup("broken"))
+	})
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+
+		idx := new(Index)
+		require.NoError(t, idx.AddFS(os.DirFS(vault)))
+
+		assert.Equal(t, []string{"notes/Foo"}, idx.Lookup("Foo"))
+		assert.Equal(t, []string{"Link"}, idx.Lookup("Link"))
+		assert.Empty(t, idx.Lookup("Deep"))
+	})
+}
+
+func writeFile(t *testing.T, name, contents string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(name), 0o755))
+	require.NoError(t, os.WriteFile(name, []byte(contents), 0o644))
+}
+
+func symlink(t *testing.T, oldname, newname string) {
+	t.Helper()
+
+	if err := os.Symlink(oldname, newname); err != nil {
+		t.Skipf("symbolic links not supported: %v", err)
+	}
+}
+
+func TestIndex_Workers(t *testing.T) {
+	t.Parallel()
+
+	files := make(fstest.MapFS)
+	for i := 0; i < 200; i++ {
+		name := fmt.Sprintf("notes/%03d/Note.md", i)
+		files[name] = &fstest.MapFile{
+			Data: []byte(fmt.Sprintf("---\naliases: [Alias %d]\n---\n# Title %d\n", i, i)),
+		}
+	}
+	files["img/cat.png"] = &fstest.MapFile{}
+
+	sequential := &Index{Workers: 1}
+	require.NoError(t, sequential.AddFS(files))
+
+	for _, workers := range []int{0, 4, 500} {
+		idx := &Index{Workers: workers}
+		require.NoError(t, idx.AddFS(files), "workers=%d", workers)
+
+		assert.Equal(t, sequential.names, idx.names, "workers=%d: names", workers)
+		assert.Equal(t, sequential.titles, idx.titles, "workers=%d: titles", workers)
+	}
+	assert.Equal(t, []string{"notes/042/Note"}, sequential.Lookup("alias 42"))
+	assert.Equal(t, "Title 42", sequential.Title("notes/042/Note"))
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		bad := make(fstest.MapFS, len(files)+1)
+		for name, f := range files {
+			bad[name] = f
+		}
+		bad["notes/100/Bad.md"] = &fstest.MapFile{Data: []byte("---\naliases: [\n---\n")}
+
+		err := (&Index{Workers: 8}).AddFS(bad)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "notes/100/Bad.md")
+	})
+}
@@ -0,0 +1,361 @@
+package wikilink
+
+import (
+	"io/fs"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndex(t *testing.T) {
+	t.Parallel()
+
+	idx := new(Index)
+	require.NoError(t, idx.AddFS(fstest.MapFS{
+		"notes/Foo.md":        {Data: []byte("---\naliases: [Old Foo, /old/foo/]\n---\n")},
+		"archive/foo.md":      {},
+		"Bar.md":              {},
+		"attachments/cat.png": {},
+		".obsidian/app.json":  {},
+		".trash/Deleted.md":   {},
+	}))
+	idx.Add("extra/Baz")
+
+	tests := []struct {
+		give string
+		want []string
+	}{
+		{give: "Foo", want: []string{"archive/foo", "notes/Foo"}},
+		{give: "notes/foo", want: []string{"notes/Foo"}},
+		{give: "notes/Foo.md", want: []string{"notes/Foo"}},
+		{give: "old foo", want: []string{"notes/Foo"}},
+		{give: "/old/foo/"},
+		{give: "bar", want: []string{"Bar"}},
+		{give: "cat.png", want: []string{"attachments/cat.png"}},
+		{give: "app.json"},
+		{give: "Deleted"},
+		{give: "Baz", want: []string{"extra/Baz"}},
+		{give: "Qux"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, idx.Lookup(tt.give))
+		})
+	}
+}
+
+func TestIndex_Title(t *testing.T) {
+	t.Parallel()
+
+	idx := new(Index)
+	require.NoError(t, idx.AddFS(fstest.MapFS{
+		"Foo.md":       {Data: []byte("---\ntitle: \"Foo: an introduction\"\n---\n# Foo\n")},
+		"Bar.md":       {Data: []byte("---\naliases: [Baz]\n---\nIntro\n\n## Details\n\n# Bar, the note\n")},
+		"Code.md":      {Data: []byte("```sh\n# not a title\n```\n")},
+		"cat.png":      {},
+		"notes/Qux.md": {Data: []byte("# Qux #\n")},
+	}))
+	idx.Add("Extra")
+
+	assert.Equal(t, "Foo: an introduction", idx.Title("Foo"), "front matter")
+	assert.Equal(t, "Bar, the note", idx.Title("Bar"), "first heading")
+	assert.Equal(t, "Qux", idx.Title("notes/Qux"), "closed heading")
+	assert.Empty(t, idx.Title("Code"), "code block")
+	assert.Empty(t, idx.Title("cat.png"), "asset")
+	assert.Empty(t, idx.Title("Extra"), "added without FS")
+	assert.Empty(t, idx.Title("Nope"), "missing")
+}
+
+func TestIndex_ReadFile(t *testing.T) {
+	t.Parallel()
+
+	idx := &Index{FS: fstest.MapFS{
+		"notes/Foo.md": {Data: []byte("# Foo\n")},
+		"cat.png":      {Data: []byte("PNG")},
+	}}
+	require.NoError(t, idx.AddFS(fstest.MapFS{
+		"Shared.MD":    {Data: []byte("# Shared\n")},
+		"notes/Foo.md": {Data: []byte("# Other Foo\n")},
+	}))
+	idx.Add("Virtual")
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{id: "notes/Foo", want: "# Foo\n"},
+		{id: "cat.png", want: "PNG"},
+		{id: "Shared", want: "# Shared\n"},
+	}
+	for _, tt := range tests {
+		got, err := idx.ReadFile(tt.id)
+		require.NoError(t, err, tt.id)
+		assert.Equal(t, tt.want, string(got), tt.id)
+	}
+
+	for _, id := range []string{"Virtual", "Foo", "missing"} {
+		_, err := idx.ReadFile(id)
+		assert.ErrorIs(t, err, fs.ErrNotExist, id)
+	}
+}
+
+func TestIndex_IDs(t *testing.T) {
+	t.Parallel()
+
+	idx := &Index{FS: fstest.MapFS{
+		"notes/Foo.md": {Data: []byte("---\naliases: [Old Foo]\ntags: [go, notes]\n---\n")},
+		"cat.png":      {},
+	}}
+	idx.Add("Virtual")
+
+	assert.Equal(t, []string{"Virtual", "cat.png", "notes/Foo"}, idx.IDs())
+	assert.Equal(t, []string{"go", "notes"}, idx.Tags("notes/Foo"))
+	assert.Nil(t, idx.Tags("cat.png"))
+}
+
+func TestIndex_Collisions(t *testing.T) {
+	t.Parallel()
+
+	idx := new(Index)
+	require.NoError(t, idx.AddFS(fstest.MapFS{
+		"notes/Foo.md":   {Data: []byte("---\naliases: [Bar, Baz]\n---\n")},
+		"archive/foo.md": {},
+		"Bar.md":         {},
+		"Qux.md":         {Data: []byte("---\naliases: Baz\n---\n")},
+		"Unique.md":      {},
+	}))
+
+	assert.Equal(t, []NameCollision{
+		{
+			Name: "Bar",
+			Files: []NameCollisionFile{
+				{ID: "Bar"},
+				{ID: "notes/Foo", Alias: true},
+			},
+		},
+		{
+			Name: "Baz",
+			Files: []NameCollisionFile{
+				{ID: "Qux", Alias: true},
+				{ID: "notes/Foo", Alias: true},
+			},
+		},
+		{
+			Name: "foo", // archive/foo.md is indexed first
+			Files: []NameCollisionFile{
+				{ID: "archive/foo"},
+				{ID: "notes/Foo"},
+			},
+		},
+	}, idx.Collisions())
+
+	assert.Empty(t, new(Index).Collisions())
+}
+
+func TestIndex_Inflect(t *testing.T) {
+	t.Parallel()
+
+	idx := &Index{Inflect: EnglishInflections}
+	idx.Add("Tags")
+	idx.Add("tag") // exact matches win
+	idx.Add("notes/Category")
+	idx.Add("Horse")
+
+	tests := []struct {
+		give string
+		want []string
+	}{
+		{give: "Tags", want: []string{"Tags"}},
+		{give: "tag", want: []string{"tag"}},
+		{give: "categories", want: []string{"notes/Category"}},
+		{give: "notes/categories.md", want: []string{"notes/Category"}},
+		{give: "horses", want: []string{"Horse"}},
+		{give: "Dogs"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, idx.Lookup(tt.give))
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		idx := new(Index)
+		idx.Add("Tags")
+		assert.Empty(t, idx.Lookup("tag"))
+	})
+}
+
+func TestEnglishInflections(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give string
+		want []string
+	}{
+		{give: "", want: nil},
+		{give: "tag", want: []string{"tags"}},
+		{give: "Tags", want: []string{"Tag"}},
+		{give: "Category", want: []string{"Categories"}},
+		{give: "categories", want: []string{"category"}},
+		{give: "day", want: []string{"days"}},
+		{give: "box", want: []string{"boxes"}},
+		{give: "boxes", want: []string{"box", "boxe"}},
+		{give: "church", want: []string{"churches"}},
+		{give: "class", want: []string{"classes"}},
+		{give: "status", want: []string{"statuses"}},
+		{give: "notes/Idea", want: []string{"notes/Ideas"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, EnglishInflections(tt.give))
+		})
+	}
+}
+
+func TestIndex_FS(t *testing.T) {
+	t.Parallel()
+
+	fsys := &countingFS{FS: fstest.MapFS{
+		"notes/Foo.md": {Data: []byte("# Foo\n")},
+	}}
+	idx := &Index{FS: fsys}
+	r := &IndexResolver{Index: idx}
+	assert.Zero(t, fsys.opens.Load(), "scanned before first use")
+
+	got, err := r.ResolveWikilink(&Node{Target: []byte("Foo")})
+	require.NoError(t, err)
+	assert.Equal(t, "notes/Foo.html", string(got))
+	assert.Equal(t, "Foo", idx.Title("notes/Foo"))
+
+	opens := fsys.opens.Load()
+	assert.NotZero(t, opens)
+	require.NoError(t, idx.Load())
+	assert.Equal(t, []string{"notes/Foo"}, idx.Lookup("foo"))
+	assert.Equal(t, opens, fsys.opens.Load(), "scanned more than once")
+}
+
+func TestIndex_FSError(t *testing.T) {
+	t.Parallel()
+
+	idx := &Index{FS: fstest.MapFS{
+		"bad.md": {Data: []byte("---\naliases: [\n---\n")},
+	}}
+	assert.Empty(t, idx.Lookup("bad"))
+
+	_, err := (&IndexResolver{Index: idx}).ResolveWikilink(&Node{Target: []byte("bad")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad.md")
+
+	_, err = (&MultiRootResolver{Roots: []VaultRoot{{Index: idx}}}).ResolveWikilink(&Node{Target: []byte("bad")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad.md")
+}
+
+func TestIndex_Reindex(t *testing.T) {
+	t.Parallel()
+
+	vault := fstest.MapFS{"Foo.md": {}}
+	shared := fstest.MapFS{"Shared.md": {}}
+
+	idx := &Index{FS: vault}
+	require.NoError(t, idx.AddFS(shared))
+	idx.Add("extra/Baz")
+	assert.Equal(t, []string{"Foo"}, idx.Lookup("Foo"))
+
+	delete(vault, "Foo.md")
+	vault["Bar.md"] = &fstest.MapFile{Data: []byte("# Bar\n")}
+	shared["More.md"] = &fstest.MapFile{}
+	assert.Equal(t, []string{"Foo"}, idx.Lookup("Foo"), "before Reindex")
+
+	require.NoError(t, idx.Reindex())
+	assert.Empty(t, idx.Lookup("Foo"), "removed")
+	assert.Equal(t, []string{"Bar"}, idx.Lookup("Bar"), "added")
+	assert.Equal(t, "Bar", idx.Title("Bar"), "title")
+	assert.Equal(t, []string{"More"}, idx.Lookup("More"), "added with AddFS")
+	assert.Equal(t, []string{"Shared"}, idx.Lookup("Shared"), "kept with AddFS")
+	assert.Equal(t, []string{"extra/Baz"}, idx.Lookup("Baz"), "kept with Add")
+
+	t.Run("error", func(t *testing.T) {
+		vault["bad.md"] = &fstest.MapFile{Data: []byte("---\naliases: [\n---\n")}
+		require.Error(t, idx.Reindex())
+		assert.Equal(t, []string{"Bar"}, idx.Lookup("Bar"), "contents kept")
+		assert.NoError(t, idx.Load())
+	})
+}
+
+// countingFS counts the files opened in an fs.FS.
+type countingFS struct {
+	fs.FS
+
+	opens atomic.Int64
+}
+
+func (f *countingFS) Open(name string) (fs.File, error) {
+	f.opens.Add(1)
+	return f.FS.Open(name)
+}
+
+func TestIndex_AddFSError(t *testing.T) {
+	t.Parallel()
+
+	err := new(Index).AddFS(fstest.MapFS{
+		"bad.md": {Data: []byte("---\naliases: [\n---\n")},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad.md")
+}
+
+func TestIndex_Closest(t *testing.T) {
+	t.Parallel()
+
+	idx := new(Index)
+	idx.Add("guide/Installation")
+	idx.Add("Instructions")
+	idx.Add("Usage")
+
+	got := idx.closest("instalation", 2)
+	require.Len(t, got, 2)
+	assert.Equal(t, "guide/Installation", got[0].ID)
+	assert.Equal(t, "Installation", got[0].Name)
+	assert.InDelta(t, 0.92, got[0].Similarity, 0.01)
+	assert.Equal(t, "Instructions", got[1].ID)
+
+	assert.Empty(t, new(Index).closest("foo", 3))
+}
+
+func TestLevenshtein(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"日本語", "日本", 1},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, levenshtein([]rune(tt.a), []rune(tt.b)), "%q, %q", tt.a, tt.b)
+	}
+}
@@ -2,12 +2,21 @@ package wikilink_test
 
 import (
 	"bytes"
+	"errors"
+	"io"
 	"os"
+	"strings"
 	"testing"
 
 	wikilink "github.com/kentxxq/goldmark-wikilink"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	extast "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
 	"gopkg.in/yaml.v3"
 )
 
@@ -40,6 +49,190 @@ func TestIntegration(t *testing.T) {
 	}
 }
 
+func TestIntegration_SoftLineBreaks(t *testing.T) {
+	t.Parallel()
+
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		AllowSoftLineBreaks: true,
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, md.Convert([]byte("Links can [[go across\nlines|when\nallowed]].\n"), &buf))
+	require.Equal(t, "<p>Links can <a href=\"go%20across%20lines.html\">when\nallowed</a>.</p>\n", buf.String())
+}
+
+func TestIntegration_DisabledIn(t *testing.T) {
+	t.Parallel()
+
+	md := goldmark.New(goldmark.WithExtensions(
+		extension.Table,
+		&wikilink.Extender{
+			DisabledIn: []ast.NodeKind{
+				ast.KindHeading,
+				ast.KindBlockquote,
+				extast.KindTableCell,
+			},
+		},
+	))
+
+	give := strings.Join([]string{
+		"# [[Heading]]",
+		"",
+		"> [[Quote]]",
+		"",
+		"| [[Table]] |",
+		"| --------- |",
+		"| [[Cell]]  |",
+		"",
+		"- [[List]]",
+		"",
+	}, "\n")
+	want := strings.Join([]string{
+		"<h1>[[Heading]]</h1>",
+		"<blockquote>",
+		"<p>[[Quote]]</p>",
+		"</blockquote>",
+		"<table>",
+		"<thead>",
+		"<tr>",
+		"<th>[[Table]]</th>",
+		"</tr>",
+		"</thead>",
+		"<tbody>",
+		"<tr>",
+		"<td>[[Cell]]</td>",
+		"</tr>",
+		"</tbody>",
+		"</table>",
+		"<ul>",
+		`<li><a href="List.html">List</a></li>`,
+		"</ul>",
+		"",
+	}, "\n")
+
+	var buf bytes.Buffer
+	require.NoError(t, md.Convert([]byte(give), &buf))
+	require.Equal(t, want, buf.String())
+}
+
+func TestIntegration_Typographer(t *testing.T) {
+	t.Parallel()
+
+	// The typographer must not rewrite quotes or dashes inside wikilinks
+	// regardless of the order in which the extensions are installed.
+	exts := [][]goldmark.Extender{
+		{extension.Typographer, &wikilink.Extender{}},
+		{&wikilink.Extender{}, extension.Typographer},
+	}
+
+	for _, ext := range exts {
+		md := goldmark.New(goldmark.WithExtensions(ext...))
+
+		var buf bytes.Buffer
+		require.NoError(t, md.Convert([]byte(`It's [[it's "here" -- really]] and [[x|it's]].`), &buf))
+		require.Equal(t,
+			`<p>It&rsquo;s <a href="it's%20%22here%22%20--%20really.html">it's &quot;here&quot; -- really</a> and <a href="x.html">it's</a>.</p>`+"\n",
+			buf.String())
+	}
+}
+
+func TestIntegration_PreferReferenceLinks(t *testing.T) {
+	t.Parallel()
+
+	src := strings.Join([]string{
+		"[[foo]] [[bar]] [[^1]] [[Baz|qux]]",
+		"",
+		"[foo]: https://example.com",
+		"[baz|qux]: https://example.com/baz",
+		"[^1]: A note.",
+		"",
+	}, "\n")
+
+	tests := []struct {
+		desc   string
+		prefer bool
+		want   string
+	}{
+		{
+			desc: "default",
+			want: `<a href="foo.html">foo</a> <a href="bar.html">bar</a> ` +
+				`<a href="%5E1.html">^1</a> <a href="Baz.html">qux</a>`,
+		},
+		{
+			desc:   "prefer references",
+			prefer: true,
+			want: `[<a href="https://example.com">foo</a>] <a href="bar.html">bar</a> ` +
+				`[<sup id="fnref:1"><a href="#fn:1" class="footnote-ref" role="doc-noteref">1</a></sup>] ` +
+				`[<a href="https://example.com/baz">Baz|qux</a>]`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			md := goldmark.New(goldmark.WithExtensions(
+				&wikilink.Extender{PreferReferenceLinks: tt.prefer},
+				extension.Footnote,
+			))
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(src), &buf))
+			assert.Contains(t, buf.String(), "<p>"+tt.want+"</p>")
+		})
+	}
+}
+
+func TestIntegration_RenderError(t *testing.T) {
+	t.Parallel()
+
+	sadness := errors.New("great sadness")
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		Resolver: resolverFunc(func(n *wikilink.Node) ([]byte, error) {
+			if string(n.Target) == "Broken" {
+				return nil, sadness
+			}
+			return wikilink.DefaultResolver.ResolveWikilink(n)
+		}),
+	}))
+
+	ctx := parser.NewContext()
+	wikilink.SetContextSource(ctx, "notes/index.md")
+
+	var buf bytes.Buffer
+	err := md.Convert([]byte("# Title\n\nSee [[Foo]].\n\nAnd [[Broken#Section|this]].\n"), &buf, parser.WithContext(ctx))
+	require.Error(t, err)
+	assert.EqualError(t, err, `notes/index.md:5: resolve "Broken#Section": great sadness`)
+	assert.ErrorIs(t, err, sadness)
+
+	var renderErr *wikilink.RenderError
+	require.True(t, errors.As(err, &renderErr), "expected *RenderError, got %T", err)
+	assert.Equal(t, "Broken", string(renderErr.Node.Target))
+	assert.Equal(t, 5, renderErr.Line)
+}
+
+func TestIntegration_RenderError_markdownLabel(t *testing.T) {
+	t.Parallel()
+
+	sadness := errors.New("great sadness")
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		MarkdownLabels: true,
+		Resolver: resolverFunc(func(n *wikilink.Node) ([]byte, error) {
+			return nil, sadness
+		}),
+	}))
+
+	// The label starts with an emphasis, not text.
+	err := md.Convert([]byte("# Title\n\nSee [[a|*b*]].\n"), io.Discard)
+	require.Error(t, err)
+	assert.EqualError(t, err, `line 3: resolve "a": great sadness`)
+
+	var renderErr *wikilink.RenderError
+	require.True(t, errors.As(err, &renderErr), "expected *RenderError, got %T", err)
+	assert.Equal(t, 3, renderErr.Line)
+}
+
 var (
 	_resolver = resolver{}
 
@@ -56,3 +249,317 @@ func (resolver) ResolveWikilink(n *wikilink.Node) ([]byte, error) {
 
 	return wikilink.DefaultResolver.ResolveWikilink(n)
 }
+
+func TestIntegration_Hashtags(t *testing.T) {
+	t.Parallel()
+
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		Resolver: &wikilink.TaxonomyResolver{},
+		Hashtags: true,
+	}))
+
+	tests := []struct {
+		desc string
+		give string
+		want string
+	}{
+		{
+			desc: "hashtag",
+			give: "Notes on #Go-Lang and #testing.",
+			want: `<p>Notes on <a href="/tags/go-lang/" class="wikilink-hashtag">#Go-Lang</a>` +
+				` and <a href="/tags/testing/" class="wikilink-hashtag">#testing</a>.</p>`,
+		},
+		{
+			desc: "start of line",
+			give: "#todo later",
+			want: `<p><a href="/tags/todo/" class="wikilink-hashtag">#todo</a> later</p>`,
+		},
+		{
+			desc: "wikilink fragment",
+			give: "See [[Foo#Bar]] and [[#Baz]].",
+			want: `<p>See <a href="Foo.html#Bar">Foo#Bar</a> and <a href="#Baz">#Baz</a>.</p>`,
+		},
+		{
+			desc: "code span",
+			give: "Run `#nope` now.",
+			want: "<p>Run <code>#nope</code> now.</p>",
+		},
+		{
+			desc: "autolink",
+			give: "<https://example.com/#nope>",
+			want: `<p><a href="https://example.com/#nope">https://example.com/#nope</a></p>`,
+		},
+		{
+			desc: "inline HTML",
+			give: `<a href="#nope">x</a>`,
+			want: "<p><!-- raw HTML omitted -->x<!-- raw HTML omitted --></p>",
+		},
+		{
+			desc: "not after a space",
+			give: "issue#12 C#sharp",
+			want: "<p>issue#12 C#sharp</p>",
+		},
+		{
+			desc: "numbers",
+			give: "Chapter #1984",
+			want: "<p>Chapter #1984</p>",
+		},
+		{
+			desc: "heading",
+			give: "# Title",
+			want: "<h1>Title</h1>",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want+"\n", buf.String())
+		})
+	}
+}
+
+func TestIntegration_HashtagPrefix(t *testing.T) {
+	t.Parallel()
+
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		Hashtags:      true,
+		HashtagPrefix: "topics/",
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, md.Convert([]byte("About #go"), &buf))
+	assert.Equal(t, "<p>About <a href=\"topics/go.html\" class=\"wikilink-hashtag\">#go</a></p>\n", buf.String())
+}
+
+func TestIntegration_MarkdownLabels(t *testing.T) {
+	t.Parallel()
+
+	md := goldmark.New(goldmark.WithExtensions(
+		extension.Strikethrough,
+		&wikilink.Extender{MarkdownLabels: true},
+	))
+
+	tests := []struct {
+		desc string
+		give string
+		want string
+	}{
+		{
+			desc: "emphasis",
+			give: "[[page|**important** note]]",
+			want: `<p><a href="page.html"><strong>important</strong> note</a></p>`,
+		},
+		{
+			desc: "code span",
+			give: "See [[api|the `Parse` function]].",
+			want: `<p>See <a href="api.html">the <code>Parse</code> function</a>.</p>`,
+		},
+		{
+			desc: "extension",
+			give: "[[old|~~gone~~]]",
+			want: `<p><a href="old.html"><del>gone</del></a></p>`,
+		},
+		{
+			desc: "target is plain",
+			give: "[[a*b*c]]",
+			want: `<p><a href="a*b*c.html">a*b*c</a></p>`,
+		},
+		{
+			desc: "nested link",
+			give: "[[page|see [here](https://example.com)]]",
+			want: `<p><a href="page.html">see here</a></p>`,
+		},
+		{
+			desc: "not a paragraph",
+			give: "[[page|# Foo]]",
+			want: `<p><a href="page.html"># Foo</a></p>`,
+		},
+		{
+			desc: "embed",
+			give: "![[cat.png|*a cat*]]",
+			want: `<p><img src="cat.png" alt="a cat"></p>`,
+		},
+		{
+			desc: "in a list",
+			give: "- item with [[page|_label_]]",
+			want: "<ul>\n<li>item with <a href=\"page.html\"><em>label</em></a></li>\n</ul>",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want+"\n", buf.String())
+		})
+	}
+}
+
+func TestIntegration_HTML(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		ext  *wikilink.Extender
+		give string
+		want string
+	}{
+		{
+			desc: "block/default",
+			ext:  &wikilink.Extender{},
+			give: "<div>[[Foo]]</div>\n",
+			want: "<div>[[Foo]]</div>\n",
+		},
+		{
+			desc: "block/parsed",
+			ext:  &wikilink.Extender{HTMLBlocks: true},
+			give: "<div class=\"note\">\nSee [[Foo|the foo]] and ![[cat.png]].\n</div>\n\nAfter\n",
+			want: "<div class=\"note\">\nSee <a href=\"Foo.html\">the foo</a> and <img src=\"cat.png\">.\n</div>\n<p>After</p>\n",
+		},
+		{
+			desc: "block/no wikilinks",
+			ext:  &wikilink.Extender{HTMLBlocks: true},
+			give: "<div>[[</div>\n",
+			want: "<div>[[</div>\n",
+		},
+		{
+			desc: "block/pre",
+			ext:  &wikilink.Extender{HTMLBlocks: true},
+			give: "<pre>\n[[Foo]]\n</pre>\n",
+			want: "<pre>\n[[Foo]]\n</pre>\n",
+		},
+		{
+			desc: "block/comment",
+			ext:  &wikilink.Extender{HTMLBlocks: true},
+			give: "<!-- [[Foo]] -->\n",
+			want: "<!-- [[Foo]] -->\n",
+		},
+		{
+			desc: "block/disabled",
+			ext: &wikilink.Extender{
+				HTMLBlocks: true,
+				DisabledIn: []ast.NodeKind{ast.KindBlockquote},
+			},
+			give: "> <div>[[Foo]]</div>\n",
+			want: "<blockquote>\n<div>[[Foo]]</div>\n</blockquote>\n",
+		},
+		{
+			desc: "inline/default",
+			ext:  &wikilink.Extender{},
+			give: "Some <b>[[Foo]]</b> text",
+			want: "<p>Some <b><a href=\"Foo.html\">Foo</a></b> text</p>\n",
+		},
+		{
+			desc: "inline/skipped",
+			ext:  &wikilink.Extender{SkipInlineHTML: true},
+			give: "[[A]] <b>[[B|b]]<br>[[C]]</b> [[D]] <img src=x>[[E]]",
+			want: "<p><a href=\"A.html\">A</a> <b>[[B|b]]<br>[[C]]</b> " +
+				"<a href=\"D.html\">D</a> <img src=x><a href=\"E.html\">E</a></p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			md := goldmark.New(
+				goldmark.WithExtensions(tt.ext),
+				goldmark.WithRendererOptions(html.WithUnsafe()),
+			)
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestIntegration_PipeAttributes(t *testing.T) {
+	t.Parallel()
+
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		PipeAttributes: true,
+	}))
+
+	tests := []struct {
+		desc string
+		give string
+		want string
+	}{
+		{
+			desc: "class",
+			give: "[[target|label|class=hero]]",
+			want: `<p><a href="target.html" class="hero">label</a></p>`,
+		},
+		{
+			desc: "several",
+			give: `[[target|label|id=intro | data-kind="a b" |title='Read this']]`,
+			want: `<p><a href="target.html" title="Read this" data-kind="a b" id="intro">label</a></p>`,
+		},
+		{
+			desc: "label only",
+			give: "[[target|a=b]]",
+			want: `<p><a href="target.html">a=b</a></p>`,
+		},
+		{
+			desc: "not at the end",
+			give: "[[target|label|class=hero|more label]]",
+			want: `<p><a href="target.html">label|class=hero|more label</a></p>`,
+		},
+		{
+			desc: "escaped",
+			give: `[[target|x\|a=b]]`,
+			want: `<p><a href="target.html">x|a=b</a></p>`,
+		},
+		{
+			desc: "unsafe",
+			give: `[[target|label|onclick=alert(1)|href=evil.html|style=color:red]]`,
+			want: `<p><a href="target.html" style="color:red">label</a></p>`,
+		},
+		{
+			desc: "escaping",
+			give: `[[target|label|title=a "quote" & <tag>]]`,
+			want: `<p><a href="target.html" title="a &quot;quote&quot; &amp; &lt;tag&gt;">label</a></p>`,
+		},
+		{
+			desc: "image",
+			give: "![[cat.png|A cat|class=wide|width=300]]",
+			want: `<p><img src="cat.png" alt="A cat" class="wide"></p>`,
+		},
+		{
+			desc: "image data",
+			give: "![[cat.png|A cat|data-zoom=true]]",
+			want: `<p><img src="cat.png" alt="A cat" data-zoom="true"></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want+"\n", buf.String())
+		})
+	}
+}
+
+func TestIntegration_PipeAttributesDisabled(t *testing.T) {
+	t.Parallel()
+
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{}))
+
+	var buf bytes.Buffer
+	require.NoError(t, md.Convert([]byte("[[target|label|class=hero]]"), &buf))
+	assert.Equal(t, "<p><a href=\"target.html\">label|class=hero</a></p>\n", buf.String())
+}
@@ -0,0 +1,86 @@
+package wikilink
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// log records the outcome of resolving n with the Renderer's Logger.
+//
+// Successful resolutions are logged at debug level,
+// missing, ambiguous, and invalid targets at warn level,
+// and all other resolver failures at error level.
+func (r *Renderer) log(n *Node, src, dest []byte, err error, status LinkStatus) {
+	level := slog.LevelWarn
+	switch status {
+	case LinkOK:
+		level = slog.LevelDebug
+	case LinkError:
+		level = slog.LevelError
+	}
+
+	ctx := n.Context()
+	if !r.Logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := make([]slog.Attr, 0, 7)
+	if len(n.source) > 0 {
+		attrs = append(attrs, slog.String("source", n.source))
+	}
+	if line := nodeLine(n, src); line > 0 {
+		attrs = append(attrs, slog.Int("line", line))
+	}
+	attrs = append(attrs, slog.String("target", string(n.Target)))
+	if len(n.Fragment) > 0 {
+		attrs = append(attrs, slog.String("fragment", string(n.Fragment)))
+	}
+	if len(dest) > 0 {
+		attrs = append(attrs, slog.String("destination", string(dest)))
+	}
+
+	if steps := n.Trace(); len(steps) > 0 {
+		attrs = append(attrs, slog.Any("trace", steps))
+	}
+
+	var ambiguous *AmbiguousTargetError
+	if errors.As(err, &ambiguous) && len(ambiguous.Candidates) > 0 {
+		attrs = append(attrs, slog.Any("candidates", ambiguous.Candidates))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+
+	r.Logger.LogAttrs(ctx, level, "wikilink "+string(status), attrs...)
+}
+
+// logStart records that op, like "reindex", started scanning the vault
+// with the Index's Logger, and returns the time it started.
+func (idx *Index) logStart(op string) time.Time {
+	if idx.Logger != nil {
+		idx.Logger.LogAttrs(context.Background(), slog.LevelDebug, "wikilink index "+op+" started")
+	}
+	return time.Now()
+}
+
+// logEnd records that op finished scanning the vault
+// with the Index's Logger.
+//
+// Successful scans are logged at info level
+// with the number of files indexed, and failures at error level.
+func (idx *Index) logEnd(op string, start time.Time, files int, err error) {
+	if idx.Logger == nil {
+		return
+	}
+
+	attrs := []slog.Attr{slog.Duration("duration", time.Since(start))}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+		idx.Logger.LogAttrs(context.Background(), slog.LevelError, "wikilink index "+op+" failed", attrs...)
+		return
+	}
+	attrs = append(attrs, slog.Int("files", files))
+	idx.Logger.LogAttrs(context.Background(), slog.LevelInfo, "wikilink index "+op+" done", attrs...)
+}
@@ -0,0 +1,169 @@
+package wikilink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderer_Logger(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		resolver Resolver
+		level    slog.Level
+		trace    bool
+		give     *Node
+		want     string
+		wantErr  bool
+	}{
+		{
+			desc:  "ok",
+			level: slog.LevelDebug,
+			give:  &Node{Target: []byte("foo"), source: "a.md"},
+			want:  `level=DEBUG msg="wikilink ok" source=a.md target=foo destination=foo.html` + "\n",
+		},
+		{
+			desc:     "trace",
+			resolver: NewTranslationResolver(map[string]string{"bar": "foo"}, DefaultResolver),
+			level:    slog.LevelDebug,
+			trace:    true,
+			give:     &Node{Target: []byte("bar")},
+			want:     `level=DEBUG msg="wikilink ok" target=bar destination=foo.html trace="[translated \"bar\" to \"foo\"]"` + "\n",
+		},
+		{
+			desc:  "ok below level",
+			level: slog.LevelInfo,
+			give:  &Node{Target: []byte("foo")},
+			want:  "",
+		},
+		{
+			desc: "missing",
+			resolver: resolverFunc(func(*Node) ([]byte, error) {
+				return nil, nil
+			}),
+			level: slog.LevelInfo,
+			give:  &Node{Target: []byte("foo"), Fragment: []byte("bar")},
+			want:  `level=WARN msg="wikilink missing" target=foo fragment=bar` + "\n",
+		},
+		{
+			desc: "ambiguous",
+			resolver: resolverFunc(func(n *Node) ([]byte, error) {
+				return nil, &AmbiguousTargetError{Node: n, Candidates: []string{"a/foo", "b/foo"}}
+			}),
+			level:   slog.LevelInfo,
+			give:    &Node{Target: []byte("foo")},
+			want:    `level=WARN msg="wikilink ambiguous" target=foo candidates="[a/foo b/foo]" error="\"foo\": ambiguous target: could be a/foo, b/foo"` + "\n",
+			wantErr: true,
+		},
+		{
+			desc: "error",
+			resolver: resolverFunc(func(*Node) ([]byte, error) {
+				return nil, io.ErrUnexpectedEOF
+			}),
+			level:   slog.LevelInfo,
+			give:    &Node{Target: []byte("foo")},
+			want:    `level=ERROR msg="wikilink error" target=foo error="unexpected EOF"` + "\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{
+				Level: tt.level,
+				ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+					if len(groups) == 0 && a.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+					return a
+				},
+			}))
+
+			r := Renderer{Resolver: tt.resolver, Logger: logger, Trace: tt.trace}
+			_, err := r.Render(bufio.NewWriter(io.Discard), nil /* src */, tt.give, true /* entering */)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.want, logs.String())
+		})
+	}
+}
+
+// ctxHandler records the contexts of the records it handles.
+type ctxHandler struct {
+	slog.Handler
+
+	ctxs []context.Context
+}
+
+func (h *ctxHandler) Handle(ctx context.Context, r slog.Record) error {
+	h.ctxs = append(h.ctxs, ctx)
+	return nil
+}
+
+func TestRenderer_LoggerContext(t *testing.T) {
+	t.Parallel()
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+
+	h := &ctxHandler{Handler: slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug})}
+	r := Renderer{Logger: slog.New(h)}
+	_, err := r.Render(bufio.NewWriter(io.Discard), nil /* src */, &Node{Target: []byte("foo"), ctx: ctx}, true /* entering */)
+	require.NoError(t, err)
+
+	require.Len(t, h.ctxs, 1)
+	assert.Equal(t, "value", h.ctxs[0].Value(key{}))
+}
+
+func TestIndex_Logger(t *testing.T) {
+	t.Parallel()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == "duration") {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	vault := fstest.MapFS{
+		"Foo.md":  {},
+		"Bar.md":  {},
+		"cat.png": {},
+	}
+	idx := &Index{FS: vault, Logger: logger}
+	require.NoError(t, idx.Load())
+	require.NoError(t, idx.AddFS(fstest.MapFS{"Shared.md": {}}))
+
+	vault["Bad.md"] = &fstest.MapFile{Data: []byte("---\naliases: [\n---\n")}
+	require.Error(t, idx.Reindex())
+
+	assert.Equal(t,
+		`level=DEBUG msg="wikilink index load started"`+"\n"+
+			`level=INFO msg="wikilink index load done" files=3`+"\n"+
+			`level=DEBUG msg="wikilink index add started"`+"\n"+
+			`level=INFO msg="wikilink index add done" files=1`+"\n"+
+			`level=DEBUG msg="wikilink index reindex started"`+"\n"+
+			`level=ERROR msg="wikilink index reindex failed" error="Bad.md: parse front matter: yaml: line 1: did not find expected node content"`+"\n",
+		logs.String())
+}
@@ -0,0 +1,92 @@
+package wikilink
+
+import (
+	"bytes"
+	"path"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+// MarkdownLinkTransformer is a goldmark AST transformer that turns
+// regular Markdown links to other Markdown documents into wikilinks.
+// Use it when migrating an existing Markdown site to wikilinks
+// so that old and new links are resolved the same way.
+//
+//	[the intro](docs/Getting%20Started.md#Install)
+//	// => [[docs/Getting Started#Install|the intro]]
+//
+// Only links with relative destinations ending with ".md",
+// optionally followed by a fragment, are converted.
+// The ".md" extension is dropped and the destination is percent-decoded.
+// Links to other files and absolute URLs are left as-is.
+//
+// Install it on your goldmark Markdown object with the
+// ConvertMarkdownLinks option of Extender,
+// or directly on a goldmark Parser with WithASTTransformers.
+//
+//	goldmarkParser.AddOptions(parser.WithASTTransformers(
+//		util.Prioritized(&wikilink.MarkdownLinkTransformer{}, 100),
+//	))
+type MarkdownLinkTransformer struct{}
+
+var _ parser.ASTTransformer = (*MarkdownLinkTransformer)(nil)
+
+var _mdExt = []byte(".md")
+
+// Transform replaces links to Markdown documents with wikilinks.
+func (t *MarkdownLinkTransformer) Transform(doc *ast.Document, _ text.Reader, pc parser.Context) {
+	// Collect the links first because we'll replace them.
+	var links []*ast.Link
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if l, ok := n.(*ast.Link); ok && entering {
+			links = append(links, l)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, l := range links {
+		n := markdownLinkNode(l.Destination)
+		if n == nil {
+			continue
+		}
+		n.resolver = ContextResolver(pc)
+		n.site = SiteContext(pc)
+		n.source = ContextSource(pc)
+		n.ctx = ResolveContext(pc)
+
+		for c := l.FirstChild(); c != nil; {
+			next := c.NextSibling()
+			n.AppendChild(n, c)
+			c = next
+		}
+		l.Parent().ReplaceChild(l.Parent(), l, n)
+	}
+}
+
+// markdownLinkNode returns a wikilink Node for the destination of
+// a Markdown link, or nil if it isn't a relative link to a Markdown
+// document.
+func markdownLinkNode(dest []byte) *Node {
+	if len(dest) == 0 || dest[0] == '/' || bytes.IndexByte(dest, ':') >= 0 {
+		return nil // absolute path or URL
+	}
+
+	target, fragment := dest, []byte(nil)
+	if idx := bytes.IndexByte(dest, '#'); idx >= 0 {
+		target, fragment = dest[:idx], dest[idx+1:]
+	}
+	if bytes.IndexByte(target, '?') >= 0 {
+		return nil
+	}
+	if !bytes.EqualFold([]byte(path.Ext(string(target))), _mdExt) {
+		return nil
+	}
+	target = target[:len(target)-len(_mdExt)]
+
+	return &Node{
+		Target:   percentDecode(target),
+		Fragment: percentDecode(fragment),
+	}
+}
@@ -0,0 +1,101 @@
+package wikilink_test
+
+import (
+	"bytes"
+	"testing"
+
+	wikilink "github.com/kentxxq/goldmark-wikilink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestMarkdownLinkTransformer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give string
+		want string
+	}{
+		{
+			desc: "document",
+			give: "[the intro](Intro.md)\n",
+			want: `<p><a href="Intro.html">the intro</a></p>` + "\n",
+		},
+		{
+			desc: "fragment",
+			give: "[install](docs/Getting%20Started.md#Install)\n",
+			want: `<p><a href="docs/Getting%20Started.html#Install">install</a></p>` + "\n",
+		},
+		{
+			desc: "formatted label",
+			give: "[**bold** text](Foo.MD)\n",
+			want: `<p><a href="Foo.html"><strong>bold</strong> text</a></p>` + "\n",
+		},
+		{
+			desc: "other file",
+			give: "[report](report.pdf)\n",
+			want: `<p><a href="report.pdf">report</a></p>` + "\n",
+		},
+		{
+			desc: "url",
+			give: "[remote](https://example.com/Foo.md)\n",
+			want: `<p><a href="https://example.com/Foo.md">remote</a></p>` + "\n",
+		},
+		{
+			desc: "absolute path",
+			give: "[root](/Foo.md)\n",
+			want: `<p><a href="/Foo.md">root</a></p>` + "\n",
+		},
+		{
+			desc: "query",
+			give: "[query](Foo.md?x=1)\n",
+			want: `<p><a href="Foo.md?x=1">query</a></p>` + "\n",
+		},
+		{
+			desc: "wikilinks untouched",
+			give: "[[Foo]] and [bar](Bar.md)\n",
+			want: `<p><a href="Foo.html">Foo</a> and <a href="Bar.html">bar</a></p>` + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+				ConvertMarkdownLinks: true,
+			}))
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestMarkdownLinkTransformer_node(t *testing.T) {
+	t.Parallel()
+
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		ConvertMarkdownLinks: true,
+	}))
+	doc := md.Parser().Parse(text.NewReader([]byte("[label](a/Foo%20Bar.md#Baz)\n")))
+
+	var nodes []*wikilink.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if wl, ok := n.(*wikilink.Node); ok && entering {
+			nodes = append(nodes, wl)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	require.Len(t, nodes, 1)
+	assert.Equal(t, "a/Foo Bar", string(nodes[0].Target))
+	assert.Equal(t, "Baz", string(nodes[0].Fragment))
+	assert.False(t, nodes[0].Embed)
+}
@@ -0,0 +1,168 @@
+package wikilink
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+
+	"github.com/yuin/goldmark/util"
+)
+
+// LinkedMentions writes the "Linked mentions" section of a note:
+// a list of the notes that link to it,
+// each with the lines that link to it as excerpts.
+// Templates can add it to the bottom of every note,
+// after all notes were rendered.
+//
+//	mentions := &wikilink.LinkedMentions{Index: idx, Report: report}
+//	mentions.WriteHTML(w, "notes/Foo")
+//	// <section class="wikilink-mentions">
+//	// <h2>Linked mentions</h2>
+//	// <ul>
+//	// <li><a href="notes/Bar.html">Bar</a>
+//	// <ul>
+//	// <li>See Foo for details.</li>
+//	// </ul>
+//	// </li>
+//	// </ul>
+//	// </section>
+//
+// Mentions are found with LinkReport.Backlinks,
+// and excerpts are read from the files of the notes in Index,
+// so render the whole of every note, front matter included,
+// for the lines of the report to match the files.
+type LinkedMentions struct {
+	// Index of the files in the vault.
+	Index *Index
+
+	// Report of the wikilinks in all notes.
+	Report *LinkReport
+
+	// Resolver resolves the IDs of the notes that mention a note,
+	// like "notes/Bar", to their URLs, like IndexResolver.Next.
+	// Notes that it doesn't resolve, like drafts,
+	// aren't listed.
+	//
+	// Defaults to DefaultResolver.
+	Resolver Resolver
+
+	// Heading is the text of the heading of the section.
+	//
+	// Defaults to "Linked mentions".
+	Heading string
+}
+
+// WriteHTML writes the section for the note with the given ID,
+// like "notes/Foo", to w.
+// It writes nothing if no note mentions it.
+func (m *LinkedMentions) WriteHTML(w io.Writer, id string) error {
+	var sources []string               // in order
+	lines := make(map[string][]string) // excerpts by source
+	files := make(map[string][]byte)   // by source
+	for _, e := range m.Report.Backlinks(m.Index, id) {
+		ls, ok := lines[e.Source]
+		if !ok {
+			sources = append(sources, e.Source)
+
+			src, err := m.Index.ReadFile(sourceID(e.Source))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+			files[e.Source] = src
+		}
+
+		excerpt := excerptLine(files[e.Source], e.Line)
+		if len(excerpt) > 0 && (len(ls) == 0 || ls[len(ls)-1] != excerpt) {
+			ls = append(ls, excerpt) // once per line
+		}
+		lines[e.Source] = ls
+	}
+
+	bw := bufio.NewWriter(w)
+	var wrote bool
+	for _, source := range sources {
+		src := sourceID(source)
+		dest, err := Resolve(src, "", m.Resolver)
+		if err != nil {
+			return fmt.Errorf("%v: %w", src, err)
+		}
+		if len(dest) == 0 {
+			continue
+		}
+
+		if !wrote {
+			heading := m.Heading
+			if len(heading) == 0 {
+				heading = "Linked mentions"
+			}
+			_, _ = bw.WriteString("<section class=\"wikilink-mentions\">\n<h2>")
+			_, _ = bw.Write(util.EscapeHTML([]byte(heading)))
+			_, _ = bw.WriteString("</h2>\n<ul>\n")
+			wrote = true
+		}
+
+		title := m.Index.Title(src)
+		if len(title) == 0 {
+			title = path.Base(src)
+		}
+		_, _ = bw.WriteString("<li>")
+		writeLink(bw, []byte(dest), title)
+		if ls := lines[source]; len(ls) > 0 {
+			_, _ = bw.WriteString("\n<ul>\n")
+			for _, l := range ls {
+				_, _ = bw.WriteString("<li>")
+				_, _ = bw.Write(util.EscapeHTML([]byte(l)))
+				_, _ = bw.WriteString("</li>\n")
+			}
+			_, _ = bw.WriteString("</ul>\n")
+		}
+		_, _ = bw.WriteString("</li>\n")
+	}
+	if wrote {
+		_, _ = bw.WriteString("</ul>\n</section>\n")
+	}
+	return bw.Flush()
+}
+
+// excerptLine returns the text of the given 1-indexed line of src,
+// or "" if there's no such line.
+func excerptLine(src []byte, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	for i := 1; i < n; i++ {
+		_, rest, ok := cutLine(src)
+		if !ok {
+			return "" // past the end of the file
+		}
+		src = rest
+	}
+	line, _, _ := cutLine(src)
+	return excerptText(line)
+}
+
+// _blockMarkers matches the Markdown markers at the start of a line,
+// like those of list items, headings, and block quotes.
+var _blockMarkers = regexp.MustCompile(`^(?:[ \t]*(?:[>*+-]|\d{1,9}[.)]|#{1,6}|\[[ xX]\])(?:[ \t]+|$))*`)
+
+// _excerptWikilink matches the wikilinks in an excerpt.
+var _excerptWikilink = regexp.MustCompile(`!?\[\[((?:\\.|[^\\\]])+)\]\]`)
+
+// excerptText returns line without Markdown block markers
+// and with wikilinks replaced by their labels, or else their targets.
+func excerptText(line []byte) string {
+	line = bytes.TrimSpace(_blockMarkers.ReplaceAll(line, nil))
+	line = _excerptWikilink.ReplaceAllFunc(line, func(link []byte) []byte {
+		inner := _excerptWikilink.FindSubmatch(link)[1]
+		if idx := indexUnescaped(inner, _pipe); idx >= 0 {
+			return unescape(bytes.TrimSpace(inner[idx+1:]), '#')
+		}
+		return unescape(bytes.TrimSpace(inner), '#')
+	})
+	return string(line)
+}
@@ -0,0 +1,138 @@
+package wikilink_test
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	wikilink "github.com/kentxxq/goldmark-wikilink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+)
+
+// _mentionsVault is a vault whose notes link to each other.
+var _mentionsVault = fstest.MapFS{
+	"notes/Foo.md": {Data: []byte("# Foo\n\nSee [[#Details]].\n\n## Details\n")},
+	"notes/Bar.md": {Data: []byte("---\ntitle: All about bars\n---\n" +
+		"- See [[Foo]] for details.\n" +
+		"- Compare [[Foo|foos]] with [[Baz]]: [[Foo]].\n" +
+		"\n" +
+		"> Also [[notes/Foo#Details|the details]]\n")},
+	"Baz.md":   {Data: []byte("Baz links to [[foo]] and [[Missing]].\n")},
+	"Draft.md": {Data: []byte("A draft about [[Foo]].\n")},
+	"Lone.md":  {Data: []byte("Nothing links here.\n")},
+}
+
+// renderVault renders every note in fsys with md,
+// setting their sources to their paths in fsys.
+func renderVault(t *testing.T, md goldmark.Markdown, fsys fs.FS) {
+	t.Helper()
+
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, ".md") {
+			return err
+		}
+		src, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		ctx := parser.NewContext()
+		wikilink.SetContextSource(ctx, p)
+		return md.Convert(src, io.Discard, parser.WithContext(ctx))
+	})
+	require.NoError(t, err)
+}
+
+func TestLinkReport_Backlinks(t *testing.T) {
+	t.Parallel()
+
+	idx := &wikilink.Index{FS: _mentionsVault}
+	report := new(wikilink.LinkReport)
+	renderVault(t, goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		Resolver: &wikilink.IndexResolver{Index: idx},
+		Report:   report,
+	})), _mentionsVault)
+
+	var got []string
+	for _, e := range report.Backlinks(idx, "notes/Foo") {
+		got = append(got, e.Source+":"+e.Target)
+	}
+	assert.Equal(t, []string{
+		"Baz.md:foo",
+		"Draft.md:Foo",
+		"notes/Bar.md:Foo",
+		"notes/Bar.md:Foo",
+		"notes/Bar.md:Foo",
+		"notes/Bar.md:notes/Foo",
+	}, got)
+
+	assert.Empty(t, report.Backlinks(idx, "Lone"))
+}
+
+func TestLinkedMentions(t *testing.T) {
+	t.Parallel()
+
+	idx := &wikilink.Index{FS: _mentionsVault}
+	report := new(wikilink.LinkReport)
+	renderVault(t, goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		Resolver: &wikilink.IndexResolver{Index: idx},
+		Report:   report,
+	})), _mentionsVault)
+
+	mentions := &wikilink.LinkedMentions{
+		Index:  idx,
+		Report: report,
+		Resolver: &wikilink.UnpublishedResolver{
+			Pages: map[string]wikilink.PageMeta{"Draft": {Draft: true}},
+		},
+	}
+
+	t.Run("mentioned", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, mentions.WriteHTML(&buf, "notes/Foo"))
+		assert.Equal(t, `<section class="wikilink-mentions">
+<h2>Linked mentions</h2>
+<ul>
+<li><a href="Baz.html">Baz</a>
+<ul>
+<li>Baz links to foo and Missing.</li>
+</ul>
+</li>
+<li><a href="notes/Bar.html">All about bars</a>
+<ul>
+<li>See Foo for details.</li>
+<li>Compare foos with Baz: Foo.</li>
+<li>Also the details</li>
+</ul>
+</li>
+</ul>
+</section>
+`, buf.String())
+	})
+
+	t.Run("not mentioned", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, mentions.WriteHTML(&buf, "Lone"))
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("heading", func(t *testing.T) {
+		t.Parallel()
+
+		m := *mentions
+		m.Heading = "Backlinks & more"
+		var buf bytes.Buffer
+		require.NoError(t, m.WriteHTML(&buf, "Baz"))
+		assert.Contains(t, buf.String(), "<h2>Backlinks &amp; more</h2>")
+		assert.Contains(t, buf.String(), "<li>Compare foos with Baz: Foo.</li>")
+	})
+}
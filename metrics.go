@@ -0,0 +1,65 @@
+package wikilink
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Metrics counts the outcomes of wikilinks rendered by a Renderer.
+// Use it to monitor link health in long-running processes
+// like preview servers.
+//
+//	metrics := new(wikilink.Metrics)
+//	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+//		Metrics: metrics,
+//	}))
+//
+// Metrics implements expvar.Var so it may be published directly.
+//
+//	expvar.Publish("wikilinks", metrics)
+//
+// Metrics is safe for concurrent use.
+// The zero value has all counters at zero and is ready to use.
+type Metrics struct {
+	resolved   atomic.Int64
+	unresolved atomic.Int64
+	errored    atomic.Int64
+	cacheHits  atomic.Int64
+}
+
+// Resolved reports the number of wikilinks that resolved to a destination.
+func (m *Metrics) Resolved() int64 { return m.resolved.Load() }
+
+// Unresolved reports the number of wikilinks whose targets were not
+// found, whether they were rendered as plain text
+// or as links to the Renderer's MissingURL
+// or a destination the resolver reported as Missing.
+func (m *Metrics) Unresolved() int64 { return m.unresolved.Load() }
+
+// Errored reports the number of wikilinks that failed to resolve
+// because of an ambiguous or invalid target, or any other resolver error.
+func (m *Metrics) Errored() int64 { return m.errored.Load() }
+
+// CacheHits reports the number of wikilinks resolved from the cache
+// of a resolver built with NewCachedResolver.
+// These are also counted by Resolved, Unresolved, or Errored.
+func (m *Metrics) CacheHits() int64 { return m.cacheHits.Load() }
+
+// String returns the counters as a JSON object.
+//
+//	{"resolved": 12, "unresolved": 1, "errored": 0, "cacheHits": 4}
+func (m *Metrics) String() string {
+	return fmt.Sprintf(`{"resolved": %d, "unresolved": %d, "errored": %d, "cacheHits": %d}`,
+		m.Resolved(), m.Unresolved(), m.Errored(), m.CacheHits())
+}
+
+func (m *Metrics) add(status LinkStatus) {
+	switch status {
+	case LinkOK:
+		m.resolved.Add(1)
+	case LinkMissing:
+		m.unresolved.Add(1)
+	default:
+		m.errored.Add(1)
+	}
+}
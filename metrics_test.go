@@ -0,0 +1,72 @@
+package wikilink_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"io"
+	"testing"
+
+	wikilink "github.com/kentxxq/goldmark-wikilink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	metrics := new(wikilink.Metrics)
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		Resolver: _resolver,
+		Metrics:  metrics,
+	}))
+
+	src := "[[Foo]] [[Bar]] [[Does Not Exist]]\n"
+	require.NoError(t, md.Convert([]byte(src), io.Discard))
+
+	md = goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		Resolver: resolverFunc(func(n *wikilink.Node) ([]byte, error) {
+			return nil, &wikilink.AmbiguousTargetError{Node: n}
+		}),
+		Metrics: metrics,
+	}))
+	require.Error(t, md.Convert([]byte("[[Baz]]"), io.Discard))
+
+	assert.Equal(t, int64(2), metrics.Resolved(), "resolved")
+	assert.Equal(t, int64(1), metrics.Unresolved(), "unresolved")
+	assert.Equal(t, int64(1), metrics.Errored(), "errored")
+
+	var got map[string]int64
+	require.NoError(t, json.Unmarshal([]byte(metrics.String()), &got))
+	assert.Equal(t, map[string]int64{
+		"resolved":   2,
+		"unresolved": 1,
+		"errored":    1,
+		"cacheHits":  0,
+	}, got)
+}
+
+func TestMetrics_cacheHits(t *testing.T) {
+	t.Parallel()
+
+	metrics := new(wikilink.Metrics)
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		Resolver: wikilink.NewCachedResolver(10, _resolver),
+		Metrics:  metrics,
+	}))
+
+	src := "[[Foo]] [[Bar]] [[Foo]] [[Foo#Baz]] [[Foo]]\n"
+	require.NoError(t, md.Convert([]byte(src), io.Discard))
+
+	assert.Equal(t, int64(5), metrics.Resolved(), "resolved")
+	assert.Equal(t, int64(2), metrics.CacheHits(), "cache hits")
+}
+
+func TestMetrics_zero(t *testing.T) {
+	t.Parallel()
+
+	var _ expvar.Var = new(wikilink.Metrics)
+
+	var metrics wikilink.Metrics
+	assert.JSONEq(t, `{"resolved": 0, "unresolved": 0, "errored": 0, "cacheHits": 0}`, metrics.String())
+}
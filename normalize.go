@@ -0,0 +1,47 @@
+package wikilink
+
+import (
+	"bytes"
+	"path"
+	"strings"
+)
+
+// normalizeDestination collapses duplicate slashes and resolves "." and
+// ".." segments in the path portion of dest. The query and fragment
+// are left as-is.
+//
+//	/root//Foo/    // => /root/Foo/
+//	./Foo.html#Bar // => Foo.html#Bar
+//	a/../b/        // => b/
+//
+// Trailing slashes and leading ".." segments are preserved.
+// Absolute URLs, like "https://publish.obsidian.md/v/Foo",
+// and network-path references, like "//cdn.example.com/",
+// are returned as-is because their paths aren't ours to clean.
+func normalizeDestination(dest []byte) []byte {
+	if hasSchemeOrAuthority(dest) {
+		return dest
+	}
+
+	end := len(dest)
+	if idx := bytes.IndexAny(dest, "?#"); idx >= 0 {
+		end = idx
+	}
+	p := string(dest[:end])
+	if len(p) == 0 {
+		return dest // fragment-only destination
+	}
+
+	clean := path.Clean(p)
+	if clean == "." {
+		clean = "./" // the current directory, e.g. "a/.."
+	}
+	if strings.HasSuffix(p, "/") && !strings.HasSuffix(clean, "/") {
+		clean += "/"
+	}
+
+	out := make([]byte, 0, len(clean)+len(dest)-end)
+	out = append(out, clean...)
+	out = append(out, dest[end:]...)
+	return out
+}
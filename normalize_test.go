@@ -0,0 +1,143 @@
+package wikilink
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDestination(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give string
+		want string
+	}{
+		{desc: "clean", give: "Foo.html", want: "Foo.html"},
+		{desc: "duplicate slashes", give: "/root//Foo/", want: "/root/Foo/"},
+		{desc: "leading dot", give: "./Foo.html#Bar", want: "Foo.html#Bar"},
+		{desc: "dot dot", give: "a/../b/", want: "b/"},
+		{desc: "leading dot dot", give: "../Foo/", want: "../Foo/"},
+		{desc: "current directory", give: "a/..", want: "./"},
+		{desc: "current directory slash", give: "./", want: "./"},
+		{desc: "root", give: "/./", want: "/"},
+		{desc: "network path", give: "//cdn.example.com//x", want: "//cdn.example.com//x"},
+		{desc: "url", give: "https://publish.obsidian.md/v/a/../Foo", want: "https://publish.obsidian.md/v/a/../Foo"},
+		{desc: "custom scheme", give: "obsidian://open?vault=v&file=Foo", want: "obsidian://open?vault=v&file=Foo"},
+		{desc: "file url", give: "file:///x/Foo.html", want: "file:///x/Foo.html"},
+		{desc: "colon in path", give: "a/b:c//d", want: "a/b:c/d"},
+		{desc: "triple slash", give: "///x", want: "/x"},
+		{desc: "fragment only", give: "#Foo", want: "#Foo"},
+		{desc: "query", give: "a//b.html?x=a//b#c//d", want: "a/b.html?x=a//b#c//d"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := normalizeDestination([]byte(tt.give))
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestRenderer_NormalizeDestinations(t *testing.T) {
+	t.Parallel()
+
+	resolver := resolverFunc(func(n *Node) ([]byte, error) {
+		return []byte("/root//" + string(n.Target) + "/"), nil
+	})
+
+	tests := []struct {
+		desc      string
+		normalize bool
+		give      *Node
+		want      string
+	}{
+		{
+			desc: "disabled",
+			give: &Node{Target: []byte("Foo")},
+			want: `<a href="/root//Foo/">`,
+		},
+		{
+			desc:      "enabled",
+			normalize: true,
+			give:      &Node{Target: []byte("Foo")},
+			want:      `<a href="/root/Foo/">`,
+		},
+		{
+			desc:      "url",
+			normalize: true,
+			give:      &Node{Target: []byte("https://example.com//a/../b")},
+			want:      `<a href="https://example.com//a/../b">`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buff bytes.Buffer
+			w := bufio.NewWriter(&buff)
+
+			r := Renderer{Resolver: resolver, NormalizeDestinations: tt.normalize}
+			_, err := r.Render(w, nil /* source */, tt.give, true /* entering */)
+			require.NoError(t, err, "should not fail")
+			require.NoError(t, w.Flush(), "flush")
+
+			assert.Equal(t, tt.want, buff.String(), "output mismatch")
+		})
+	}
+}
+
+func TestRenderer_NormalizeDestinations_absoluteURLs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	tests := []struct {
+		desc     string
+		resolver Resolver
+		want     string
+	}{
+		{
+			desc:     "Obsidian Publish",
+			resolver: NewObsidianPublishResolver("https://publish.obsidian.md/v"),
+			want:     `<a href="https://publish.obsidian.md/v/Foo">`,
+		},
+		{
+			desc:     "obsidian scheme",
+			resolver: &ObsidianURIResolver{Vault: "v"},
+			want:     `<a href="obsidian://open?vault=v&file=Foo">`,
+		},
+		{
+			desc:     "file URL",
+			resolver: &FileURLResolver{Dir: dir},
+			want:     `<a href="` + fileURL(filepath.ToSlash(dir)).String() + `/Foo.html">`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buff bytes.Buffer
+			w := bufio.NewWriter(&buff)
+
+			r := Renderer{Resolver: tt.resolver, NormalizeDestinations: true}
+			_, err := r.Render(w, nil /* source */, &Node{Target: []byte("Foo")}, true /* entering */)
+			require.NoError(t, err, "should not fail")
+			require.NoError(t, w.Flush(), "flush")
+
+			assert.Equal(t, tt.want, buff.String(), "output mismatch")
+		})
+	}
+}
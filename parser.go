@@ -2,10 +2,15 @@ package wikilink
 
 import (
 	"bytes"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
 
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
 )
 
 // Parser parses wikilinks.
@@ -18,7 +23,173 @@ import (
 //
 // Note that the priority for the wikilink parser must 199 or lower to take
 // precedence over the plain Markdown link parser which has a priority of 200.
-type Parser struct{}
+//
+// The Parser consumes the entire wikilink, from "[[" to "]]", as soon as it
+// sees the opening bracket. Other inline parsers, such as the typographer
+// extension's, never see its contents, so quotes and dashes in the target
+// and label are kept verbatim.
+type Parser struct {
+	// AllowSoftLineBreaks specifies whether a wikilink may span multiple
+	// lines of the same paragraph.
+	//
+	// By default, wikilinks must open and close on the same line,
+	// matching Obsidian.
+	// Set this to support text exported by tools that wrap long lines.
+	//
+	//	See [[a page
+	//	with a long name]].
+	//
+	// Line breaks inside the target are replaced with a single space,
+	// and line breaks inside the label are retained as soft line breaks.
+	AllowSoftLineBreaks bool
+
+	// MaxTargetLength is the maximum length in bytes of the target of a
+	// wikilink, including its fragment.
+	// Wikilinks with longer targets are not parsed and are left as
+	// plain text.
+	//
+	// There is no limit if this is zero.
+	MaxTargetLength int
+
+	// InvalidTargetChars is a set of characters that must not appear in
+	// the target of a wikilink or its fragment.
+	// Wikilinks with targets that contain any of these characters are
+	// not parsed and are left as plain text.
+	//
+	//	Parser{InvalidTargetChars: "<>\"'"}
+	//
+	// All characters are allowed if this is empty.
+	InvalidTargetChars string
+
+	// DisabledIn lists the kinds of nodes inside which wikilinks are not
+	// parsed. Use this to leave wikilinks in some regions of the document
+	// alone for other tooling to post-process.
+	//
+	//	Parser{
+	//		DisabledIn: []ast.NodeKind{
+	//			ast.KindHeading,
+	//			ast.KindBlockquote,
+	//			extast.KindTableCell,
+	//		},
+	//	}
+	//
+	// Wikilinks inside raw HTML blocks are only parsed
+	// with HTMLTransformer, which also respects this setting.
+	DisabledIn []ast.NodeKind
+
+	// DecodeTargets specifies whether percent-encoded sequences in the
+	// target and fragment of a wikilink should be decoded.
+	// This is useful for notes exported from web tools
+	// that percent-encode link targets.
+	//
+	//	[[My%20Page]]  // target is "My Page"
+	//
+	// Targets that are not valid percent-encoded strings
+	// are left as-is.
+	DecodeTargets bool
+
+	// ConvertBackslashes specifies whether backslashes in the target
+	// of a wikilink should be replaced with forward slashes.
+	// This is useful for notes with Windows-style paths pasted into them.
+	//
+	//	[[notes\Foo]]  // target is "notes/Foo"
+	//
+	// Backslashes that escape special characters, like "\|",
+	// are removed as usual before conversion.
+	ConvertBackslashes bool
+
+	// AllowQuery specifies whether the target of a wikilink may contain
+	// a query string. If set, everything after the first "?" in the
+	// target is placed into Node.Query verbatim.
+	//
+	//	[[search?tag=golang]]  // target "search", query "tag=golang"
+	//
+	// This is disabled by default because "?" is a valid character in
+	// page names.
+	AllowQuery bool
+
+	// Site is an arbitrary site-wide value, such as the site's
+	// configuration or a registry of its pages.
+	//
+	// It is stored on every parser.Context that the Parser sees
+	// unless the context already has a value,
+	// and is made available to resolvers with Node.Site.
+	// See also SetSiteContext and SiteContext.
+	Site any
+
+	// FragmentSeparator is the character that separates the target of a
+	// wikilink from its fragment.
+	//
+	//	Parser{FragmentSeparator: '>'}
+	//	// [[Foo>Bar]] => target "Foo", fragment "Bar"
+	//
+	// The separator may be escaped with a backslash
+	// to use it in a target.
+	//
+	// Defaults to '#' if unset.
+	FragmentSeparator byte
+
+	// PreferReferenceLinks specifies whether reference links take
+	// precedence over wikilinks when both could match.
+	//
+	// In CommonMark, "[[foo]]" is a shortcut reference link inside
+	// brackets if the document defines a "[foo]" reference.
+	// By default, the Parser ignores such definitions
+	// and "[[foo]]" is always a wikilink.
+	//
+	//	[[foo]]
+	//
+	//	[foo]: https://example.com
+	//
+	//	// Default:              <a href="foo.html">foo</a>
+	//	// PreferReferenceLinks: [<a href="https://example.com">foo</a>]
+	//
+	// With this set, wikilinks whose contents match the label of a
+	// reference definition in the document are left to the link parser,
+	// as are wikilinks whose targets start with "^",
+	// which could be footnote references like "[[^1]]".
+	PreferReferenceLinks bool
+
+	// LabelParser, if set, parses the labels of wikilinks as inline
+	// Markdown so that emphasis, code spans, and the like are rendered
+	// instead of being shown as-is.
+	//
+	//	[[page|**important** note]]
+	//	// => <a href="page.html"><strong>important</strong> note</a>
+	//
+	// Use the goldmark Parser that the wikilink Parser is installed on
+	// so that labels support the same syntax as the rest of the document.
+	// The Extender does this with its MarkdownLabels option.
+	//
+	// Only labels written after a "|" are parsed.
+	// Targets used as labels, like [[a*b*c]], and labels that span
+	// multiple lines are kept as plain text,
+	// as are labels that aren't a single paragraph, like "# Foo".
+	// Links inside labels are replaced with their text
+	// because links can't be nested.
+	LabelParser parser.Parser
+
+	// PipeAttributes specifies whether "key=value" segments after
+	// the label, separated by "|", are parsed as HTML attributes
+	// for the wikilink.
+	//
+	//	[[target|label|class=hero|data-kind=intro]]
+	//	// => <a href="target.html" class="hero" data-kind="intro">label</a>
+	//
+	// Attributes are taken from the end of the label for as long as
+	// they look like "key=value", optionally with the value in quotes,
+	// and are stored as the attributes of the Node.
+	// The first segment after the target is always the label,
+	// so [[target|a=b]] has the label "a=b".
+	// Escape "|" in labels that end with something like an attribute:
+	//
+	//	[[target|x\|a=b]] // label "x|a=b"
+	//
+	// The Renderer only writes global HTML attributes, like "class",
+	// "id", and "title", and "data-" attributes, so that authors can't
+	// add event handlers or override the destination.
+	PipeAttributes bool
+}
 
 var _ parser.InlineParser = (*Parser)(nil)
 
@@ -27,6 +198,7 @@ var (
 	_embedOpen = []byte("![[")
 	_pipe      = []byte{'|'}
 	_hash      = []byte{'#'}
+	_question  = []byte{'?'}
 	_close     = []byte("]]")
 )
 
@@ -46,46 +218,495 @@ func (p *Parser) Trigger() []byte {
 //	[[target|label]]
 //
 // If the label is omitted, the target is used as the label.
+// Following Obsidian, only the first "|" separates the target from the label.
+// Everything after it, including other "|" characters, is the label.
+//
+//	[[target|label|more label]]
 //
 // The target may optionally contain a fragment identifier:
 //
 //	[[target#fragment]]
-func (p *Parser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
-	line, seg := block.PeekLine()
-	stop := bytes.Index(line, _close)
-	if stop < 0 {
-		return nil // must close on the same line
+//
+// Use a backslash to escape "|", "[", "]", and "#" characters that are
+// part of the target or label. The escapes are removed from the parsed
+// target.
+//
+//	[[foo\|bar|baz\|qux]]  // target "foo|bar", label "baz|qux"
+//	[[foo\]\]bar]]         // target "foo]]bar"
+//	[[C\# notes#Generics]] // target "C# notes", fragment "Generics"
+func (p *Parser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+	if p.disabledIn(parent) {
+		return nil
 	}
 
-	var embed bool
+	line, seg := block.PeekLine()
+	start := seg.Start
 
+	var embed bool
 	switch {
 	case bytes.HasPrefix(line, _open):
-		seg = text.NewSegment(seg.Start+len(_open), seg.Start+stop)
+		seg = seg.WithStart(seg.Start + len(_open))
 	case bytes.HasPrefix(line, _embedOpen):
 		embed = true
-		seg = text.NewSegment(seg.Start+len(_embedOpen), seg.Start+stop)
+		seg = seg.WithStart(seg.Start + len(_embedOpen))
 	default:
 		return nil
 	}
 
-	n := &Node{Target: block.Value(seg), Embed: embed}
-	if idx := bytes.Index(n.Target, _pipe); idx >= 0 {
-		n.Target = n.Target[:idx]                // [[ ... |
-		seg = seg.WithStart(seg.Start + idx + 1) // | ... ]]
+	buf := _segmentsPool.Get().(*segments)
+	defer buf.release()
+
+	// pieces holds the contents of the wikilink between the "[[" and "]]"
+	// with one segment for each line that the wikilink spans.
+	pieces, lines, offset := p.scan(block, seg, buf.pieces[:0])
+	buf.pieces = pieces
+	if len(pieces) == 0 {
+		return nil
+	}
+
+	if p.PreferReferenceLinks && p.isReference(block, pieces, pc) {
+		return nil
+	}
+
+	// Split the pieces at the first "|" into the target and the label.
+	target, label := pieces, pieces
+	var hasLabel bool
+	for i, piece := range pieces {
+		if idx := indexUnescaped(piece.Value(block.Source()), _pipe); idx >= 0 {
+			buf.label = append(buf.label[:0], piece.WithStart(piece.Start+idx+1))
+			buf.label = append(buf.label, pieces[i+1:]...)
+			label = buf.label
+
+			pieces[i] = piece.WithStop(piece.Start + idx)
+			target = pieces[:i+1]
+			hasLabel = true
+			break
+		}
 	}
 
-	if len(n.Target) == 0 || seg.Len() == 0 {
+	n := &Node{
+		Target:   joinSegments(block, target),
+		Embed:    embed,
+		resolver: ContextResolver(pc),
+		site:     p.site(pc),
+		source:   ContextSource(pc),
+		ctx:      ResolveContext(pc),
+		segment:  text.NewSegment(start, offset),
+	}
+	if len(n.Target) == 0 || segmentsLen(label) == 0 {
 		return nil // target and label must not be empty
 	}
+	n.LinkType = linkType(parent, block.Source())
 
 	// Target may be Foo#Bar, so break them apart.
-	if idx := bytes.LastIndex(n.Target, _hash); idx >= 0 {
+	sep := p.fragmentSeparator()
+	if idx := lastIndexUnescaped(n.Target, sep); idx >= 0 {
 		n.Fragment = n.Target[idx+1:] // Foo#Bar => Bar
-		n.Target = n.Target[:idx]     // Foo#Bar => Foo
+		n.Target = n.Target[:idx:idx] // Foo#Bar => Foo
+	}
+
+	// With queries enabled, Foo?Bar is also broken apart.
+	if p.AllowQuery {
+		if idx := bytes.Index(n.Target, _question); idx >= 0 {
+			n.Query = n.Target[idx+1:]    // Foo?Bar => Bar
+			n.Target = n.Target[:idx:idx] // Foo?Bar => Foo
+		}
+	}
+	if len(target) == 1 {
+		n.targetSegment = text.NewSegment(target[0].Start, target[0].Start+len(n.Target))
 	}
 
-	n.AppendChild(n, ast.NewTextSegment(seg))
-	block.Advance(stop + 2)
+	n.Target = unescape(n.Target, sep)
+	n.Fragment = unescape(n.Fragment, sep)
+	if p.ConvertBackslashes && bytes.IndexByte(n.Target, '\\') >= 0 {
+		// Target may alias the source so don't change it in-place.
+		n.Target = bytes.ReplaceAll(n.Target, []byte{'\\'}, []byte{'/'})
+	}
+	if p.DecodeTargets {
+		n.Target = percentDecode(n.Target)
+		n.Fragment = percentDecode(n.Fragment)
+	}
+	if !p.validTarget(n) {
+		return nil
+	}
+
+	if hasLabel && p.PipeAttributes {
+		label[len(label)-1] = pipeAttributes(n, block.Source(), label[len(label)-1])
+	}
+
+	if !hasLabel || len(label) != 1 || !p.appendMarkdownLabel(n, block.Source(), label[0]) {
+		for i, piece := range label {
+			if piece.IsEmpty() {
+				continue
+			}
+			t := ast.NewTextSegment(piece)
+			t.SetSoftLineBreak(i < len(label)-1)
+			n.AppendChild(n, t)
+		}
+	}
+
+	advance(block, lines, offset)
 	return n
 }
+
+// appendMarkdownLabel parses the label of n in src as inline Markdown
+// with LabelParser and appends the result to n.
+// It reports false if the label isn't a single paragraph.
+func (p *Parser) appendMarkdownLabel(n *Node, src []byte, label text.Segment) bool {
+	if p.LabelParser == nil {
+		return false
+	}
+
+	// Parse the label in place so that the segments of the resulting
+	// nodes point into src.
+	reader := text.NewReader(src[:label.Stop])
+	reader.Advance(label.Start)
+	doc := p.LabelParser.Parse(reader)
+
+	para, ok := doc.FirstChild().(*ast.Paragraph)
+	if !ok || para.NextSibling() != nil {
+		return false
+	}
+
+	unwrapLinks(para)
+	for c := para.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		n.AppendChild(n, c)
+		c = next
+	}
+	return true
+}
+
+// unwrapLinks replaces links and wikilinks inside parent
+// with their children.
+func unwrapLinks(parent ast.Node) {
+	for c := parent.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		unwrapLinks(c)
+		switch c.(type) {
+		case *ast.Link, *Node:
+			for gc := c.FirstChild(); gc != nil; {
+				gcNext := gc.NextSibling()
+				parent.InsertBefore(parent, c, gc)
+				gc = gcNext
+			}
+			parent.RemoveChild(parent, c)
+		}
+		c = next
+	}
+}
+
+// pipeAttributes moves "|key=value" segments from the end of the last
+// segment of a label into attributes on n,
+// and returns the rest of the segment.
+func pipeAttributes(n *Node, src []byte, last text.Segment) text.Segment {
+	var names, values []string
+	for {
+		idx := lastIndexUnescaped(last.Value(src), '|')
+		if idx < 0 {
+			break
+		}
+		name, value, ok := pipeAttribute(last.Value(src)[idx+1:])
+		if !ok {
+			break
+		}
+		names = append(names, name)
+		values = append(values, value)
+		last = last.WithStop(last.Start + idx)
+	}
+
+	// Set the attributes in the order they were written
+	// so that later ones win.
+	for i := len(names) - 1; i >= 0; i-- {
+		n.SetAttributeString(names[i], []byte(values[i]))
+	}
+	return last
+}
+
+// pipeAttribute parses a "key=value" segment of a wikilink label.
+func pipeAttribute(b []byte) (name, value string, ok bool) {
+	name, value, ok = strings.Cut(string(b), "=")
+	name = strings.TrimSpace(name)
+	if !ok || !validAttrName(name) {
+		return "", "", false
+	}
+
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return name, value, true
+}
+
+// _linkType matches the key of a typed link, like "supports:: ",
+// at the end of the text before a wikilink.
+var _linkType = regexp.MustCompile(`(?:^|\s)([\p{L}\p{N}_-]+)::[ \t]*$`)
+
+// linkType returns the type of a wikilink about to be appended to parent
+// from the text immediately before it, or nil if it isn't typed.
+//
+//	supports:: [[Claim]] // => "supports"
+func linkType(parent ast.Node, src []byte) []byte {
+	if parent == nil {
+		return nil
+	}
+	t, ok := parent.LastChild().(*ast.Text)
+	if !ok || t.SoftLineBreak() || t.HardLineBreak() {
+		return nil
+	}
+	m := _linkType.FindSubmatchIndex(t.Segment.Value(src))
+	if m == nil {
+		return nil
+	}
+	return t.Segment.Value(src)[m[2]:m[3]:m[3]]
+}
+
+// isReference reports whether the contents of a wikilink
+// could be a reference link or a footnote reference instead.
+func (p *Parser) isReference(block text.Reader, pieces []text.Segment, pc parser.Context) bool {
+	label := joinSegments(block, pieces)
+	if len(label) > 0 && label[0] == '^' {
+		return true // footnote reference
+	}
+	if pc == nil {
+		return false
+	}
+	_, ok := pc.Reference(util.ToLinkReference(label))
+	return ok
+}
+
+func (p *Parser) fragmentSeparator() byte {
+	if p.FragmentSeparator == 0 {
+		return '#'
+	}
+	return p.FragmentSeparator
+}
+
+// site returns the site-wide value for the document being parsed
+// with the provided context, storing Parser.Site on it if it's not set.
+func (p *Parser) site(pc parser.Context) any {
+	return contextSite(pc, p.Site)
+}
+
+// contextSite returns the site-wide value stored on pc,
+// storing def on it if it's not set.
+func contextSite(pc parser.Context, def any) any {
+	if pc == nil {
+		return def
+	}
+
+	site := SiteContext(pc)
+	if site == nil && def != nil {
+		site = def
+		SetSiteContext(pc, site)
+	}
+	return site
+}
+
+// disabledIn reports whether parsing is disabled inside the provided node
+// because it or one of its ancestors is listed in DisabledIn.
+func (p *Parser) disabledIn(n ast.Node) bool {
+	return insideKinds(n, p.DisabledIn)
+}
+
+// insideKinds reports whether n or one of its ancestors
+// is of one of the provided kinds.
+func insideKinds(n ast.Node, kinds []ast.NodeKind) bool {
+	if len(kinds) == 0 {
+		return false
+	}
+
+	for ; n != nil; n = n.Parent() {
+		for _, kind := range kinds {
+			if n.Kind() == kind {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validTarget reports whether the target and fragment of the provided node
+// satisfy the MaxTargetLength and InvalidTargetChars constraints.
+func (p *Parser) validTarget(n *Node) bool {
+	if p.MaxTargetLength > 0 && len(n.Target)+len(n.Fragment) > p.MaxTargetLength {
+		return false
+	}
+	if len(p.InvalidTargetChars) > 0 {
+		if bytes.ContainsAny(n.Target, p.InvalidTargetChars) ||
+			bytes.ContainsAny(n.Fragment, p.InvalidTargetChars) {
+			return false
+		}
+	}
+	return true
+}
+
+// scan finds the closing "]]" of a wikilink whose contents start at seg,
+// looking past the current line only if AllowSoftLineBreaks is set.
+//
+// It appends the contents of the wikilink split by line to pieces,
+// and reports the number of lines and the offset in the source that
+// advance must move the reader by to move past the closing "]]".
+// No segments are returned if the wikilink is not closed.
+func (p *Parser) scan(block text.Reader, seg text.Segment, pieces []text.Segment) (_ []text.Segment, lines, offset int) {
+	lineNum, pos := block.Position()
+	defer block.SetPosition(lineNum, pos)
+
+	for {
+		value := seg.Value(block.Source())
+		if stop := indexUnescaped(value, _close); stop >= 0 {
+			pieces = append(pieces, seg.WithStop(seg.Start+stop))
+			return pieces, lines, seg.Start + stop + len(_close)
+		}
+
+		if !p.AllowSoftLineBreaks {
+			return nil, 0, 0 // must close on the same line
+		}
+
+		pieces = append(pieces, seg.TrimRightSpace(block.Source()))
+		block.AdvanceLine()
+		lines++
+
+		var line []byte
+		line, seg = block.PeekLine()
+		if line == nil {
+			return nil, 0, 0 // reached the end of the block
+		}
+	}
+}
+
+// advance moves the reader past the closing "]]" of a wikilink
+// given the lines and offset reported by scan.
+func advance(block text.Reader, lines, offset int) {
+	for i := 0; i < lines; i++ {
+		block.AdvanceLine()
+	}
+	_, cur := block.PeekLine()
+	block.Advance(offset - cur.Start)
+}
+
+// segments holds reusable buffers for the segments of a wikilink
+// while it's being parsed.
+//
+// Nodes aren't pooled because they're owned by the document
+// once they're returned from Parse.
+type segments struct {
+	pieces []text.Segment
+	label  []text.Segment
+}
+
+var _segmentsPool = sync.Pool{
+	New: func() any { return new(segments) },
+}
+
+// release returns the buffers to the pool.
+// They must not be used afterwards.
+func (s *segments) release() {
+	s.pieces = s.pieces[:0]
+	s.label = s.label[:0]
+	_segmentsPool.Put(s)
+}
+
+// joinSegments joins the values of the provided segments with a single
+// space.
+//
+// The value of the segment is returned as-is if there's only one.
+func joinSegments(block text.Reader, segs []text.Segment) []byte {
+	// Each segment lies within a single line so we can take its value
+	// from the source directly. text.Reader.Value would copy it.
+	if len(segs) == 1 {
+		v := segs[0].Value(block.Source())
+		return v[:len(v):len(v)] // appending must not overwrite the source
+	}
+
+	var out []byte
+	for i, seg := range segs {
+		if i > 0 {
+			out = append(out, ' ')
+		}
+		out = append(out, seg.Value(block.Source())...)
+	}
+	return out
+}
+
+// segmentsLen reports the combined length of the provided segments.
+func segmentsLen(segs []text.Segment) (n int) {
+	for _, seg := range segs {
+		n += seg.Len()
+	}
+	return n
+}
+
+// percentDecode decodes percent-encoded sequences in b.
+// b is returned as-is if it does not contain any valid sequences.
+func percentDecode(b []byte) []byte {
+	if bytes.IndexByte(b, '%') < 0 {
+		return b
+	}
+
+	s, err := url.PathUnescape(string(b))
+	if err != nil {
+		return b
+	}
+	return []byte(s)
+}
+
+// _escapable is the set of characters that may be escaped with a backslash
+// inside a wikilink so that they don't take on their special meaning.
+// The fragment separator, if not "#", may also be escaped.
+//
+//	[[foo\|bar]]   // target is "foo|bar"
+//	[[foo\]\]bar]] // target is "foo]]bar"
+//	[[C\# notes]]  // target is "C# notes"
+const _escapable = "|[]#"
+
+// indexUnescaped returns the index of the first instance of sep in b
+// that is not preceded by a backslash escape, or -1 if there isn't one.
+func indexUnescaped(b, sep []byte) int {
+	for i := 0; i+len(sep) <= len(b); i++ {
+		if b[i] == '\\' {
+			i++ // skip the escaped byte
+			continue
+		}
+		if bytes.HasPrefix(b[i:], sep) {
+			return i
+		}
+	}
+	return -1
+}
+
+// lastIndexUnescaped returns the index of the last instance of c in b
+// that is not escaped with a backslash, or -1 if there isn't one.
+func lastIndexUnescaped(b []byte, c byte) int {
+	last := -1
+	for i := 0; i < len(b); i++ {
+		switch b[i] {
+		case '\\':
+			i++ // skip the escaped byte
+		case c:
+			last = i
+		}
+	}
+	return last
+}
+
+// unescape removes backslash escapes for characters in _escapable
+// and the fragment separator sep from b.
+//
+// b is returned as-is if it does not contain any escapes.
+func unescape(b []byte, sep byte) []byte {
+	if bytes.IndexByte(b, '\\') < 0 {
+		return b
+	}
+
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if c == '\\' && i+1 < len(b) && (b[i+1] == sep || strings.IndexByte(_escapable, b[i+1]) >= 0) {
+			i++
+			c = b[i]
+		}
+		out = append(out, c)
+	}
+	return out
+}
@@ -1,10 +1,13 @@
 package wikilink
 
 import (
+	"bytes"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
@@ -104,6 +107,65 @@ func TestParser(t *testing.T) {
 			wantFragment: "foo",
 			wantEmbed:    true,
 		},
+		{
+			desc:       "multiple pipes",
+			give:       "[[foo|bar|baz]] qux",
+			wantTarget: "foo",
+			wantLabel:  "bar|baz",
+			remainder:  " qux",
+		},
+		{
+			desc:       "escaped pipe in target",
+			give:       `[[foo\|bar]]`,
+			wantTarget: "foo|bar",
+			wantLabel:  `foo\|bar`,
+		},
+		{
+			desc:       "escaped pipe in label",
+			give:       `[[foo|bar\|baz]] qux`,
+			wantTarget: "foo",
+			wantLabel:  `bar\|baz`,
+			remainder:  " qux",
+		},
+		{
+			desc:       "escaped brackets in target",
+			give:       `[[foo\]\]bar|baz]]`,
+			wantTarget: "foo]]bar",
+			wantLabel:  "baz",
+		},
+		{
+			desc:         "escapes in fragment",
+			give:         `[[foo#bar\|baz|qux]]`,
+			wantTarget:   "foo",
+			wantLabel:    "qux",
+			wantFragment: "bar|baz",
+		},
+		{
+			desc:       "escaped hash in target",
+			give:       `[[C\# notes]]`,
+			wantTarget: "C# notes",
+			wantLabel:  `C\# notes`,
+		},
+		{
+			desc:         "escaped hash with fragment",
+			give:         `[[C\# notes#Generics|C#]]`,
+			wantTarget:   "C# notes",
+			wantLabel:    "C#",
+			wantFragment: "Generics",
+		},
+		{
+			desc:         "escaped hash in fragment",
+			give:         `[[foo#bar\#baz]]`,
+			wantTarget:   "foo",
+			wantLabel:    `foo#bar\#baz`,
+			wantFragment: "bar#baz",
+		},
+		{
+			desc:       "unrelated backslash",
+			give:       `[[foo\bar]]`,
+			wantTarget: `foo\bar`,
+			wantLabel:  `foo\bar`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -136,3 +198,399 @@ func TestParser(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_SoftLineBreaks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give string
+
+		wantTarget   string
+		wantLabels   []string
+		wantFragment string
+
+		remainder string // unconsumed portion of the last line
+	}{
+		{
+			desc:       "target",
+			give:       "[[foo\nbar]] baz",
+			wantTarget: "foo bar",
+			wantLabels: []string{"foo", "bar"},
+			remainder:  " baz",
+		},
+		{
+			desc:       "label",
+			give:       "[[foo|bar\nbaz]]",
+			wantTarget: "foo",
+			wantLabels: []string{"bar", "baz"},
+		},
+		{
+			desc:       "pipe at end of line",
+			give:       "[[foo|\nbar]] baz",
+			wantTarget: "foo",
+			wantLabels: []string{"bar"},
+			remainder:  " baz",
+		},
+		{
+			desc:         "many lines",
+			give:         "[[foo  \nbar#baz\nqux|quux\nquuz]]",
+			wantTarget:   "foo bar",
+			wantFragment: "baz qux",
+			wantLabels:   []string{"quux", "quuz"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			r := text.NewReader([]byte(tt.give))
+
+			p := Parser{AllowSoftLineBreaks: true}
+			got := p.Parse(nil /* parent */, r, parser.NewContext())
+			require.NotNil(t, got, "expected Node, got nil")
+
+			n, ok := got.(*Node)
+			require.True(t, ok, "expected Node, got %T", got)
+			assert.Equal(t, tt.wantTarget, string(n.Target), "target mismatch")
+			assert.Equal(t, tt.wantFragment, string(n.Fragment), "fragment mismatch")
+
+			var labels []string
+			for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+				label, ok := c.(*ast.Text)
+				require.True(t, ok, "expected Text, got %T", c)
+				labels = append(labels, string(r.Value(label.Segment)))
+				assert.Equal(t, c.NextSibling() != nil, label.SoftLineBreak(),
+					"only the last label should not have a soft line break")
+			}
+			assert.Equal(t, tt.wantLabels, labels, "labels mismatch")
+
+			_, pos := r.Position()
+			assert.Equal(t, tt.remainder, string(r.Value(pos)),
+				"remaining text does not match")
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		var p Parser
+		got := p.Parse(nil /* parent */, text.NewReader([]byte("[[foo\nbar]]")), parser.NewContext())
+		assert.Nil(t, got, "expected nil")
+	})
+
+	t.Run("unclosed", func(t *testing.T) {
+		t.Parallel()
+
+		p := Parser{AllowSoftLineBreaks: true}
+		got := p.Parse(nil /* parent */, text.NewReader([]byte("[[foo\nbar")), parser.NewContext())
+		assert.Nil(t, got, "expected nil")
+	})
+}
+
+func TestParser_TargetValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc  string
+		give  string
+		p     Parser
+		valid bool
+	}{
+		{
+			desc:  "within length",
+			give:  "[[foo#bar]]",
+			p:     Parser{MaxTargetLength: 6},
+			valid: true,
+		},
+		{
+			desc: "too long",
+			give: "[[foo#barbaz|qux]]",
+			p:    Parser{MaxTargetLength: 6},
+		},
+		{
+			desc:  "label does not count",
+			give:  "[[foo|barbazqux]]",
+			p:     Parser{MaxTargetLength: 3},
+			valid: true,
+		},
+		{
+			desc:  "valid characters",
+			give:  "[[foo bar|<baz>]]",
+			p:     Parser{InvalidTargetChars: "<>"},
+			valid: true,
+		},
+		{
+			desc: "invalid character in target",
+			give: "[[foo<bar>]]",
+			p:    Parser{InvalidTargetChars: "<>"},
+		},
+		{
+			desc: "invalid character in fragment",
+			give: `[[foo#"bar"]]`,
+			p:    Parser{InvalidTargetChars: `"`},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := tt.p.Parse(nil /* parent */, text.NewReader([]byte(tt.give)), parser.NewContext())
+			if tt.valid {
+				assert.NotNil(t, got, "expected Node")
+			} else {
+				assert.Nil(t, got, "expected nil")
+			}
+		})
+	}
+}
+
+func TestParser_DecodeTargets(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc         string
+		give         string
+		wantTarget   string
+		wantFragment string
+	}{
+		{
+			desc:       "plain",
+			give:       "[[My Page]]",
+			wantTarget: "My Page",
+		},
+		{
+			desc:         "encoded",
+			give:         "[[My%20Page#Some%20Section|label]]",
+			wantTarget:   "My Page",
+			wantFragment: "Some Section",
+		},
+		{
+			desc:       "unicode",
+			give:       "[[%E6%95%B0%E6%8D%AE]]",
+			wantTarget: "数据",
+		},
+		{
+			desc:       "invalid",
+			give:       "[[100%]]",
+			wantTarget: "100%",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := Parser{DecodeTargets: true}
+			got := p.Parse(nil /* parent */, text.NewReader([]byte(tt.give)), parser.NewContext())
+			require.NotNil(t, got, "expected Node, got nil")
+
+			n, ok := got.(*Node)
+			require.True(t, ok, "expected Node, got %T", got)
+			assert.Equal(t, tt.wantTarget, string(n.Target), "target mismatch")
+			assert.Equal(t, tt.wantFragment, string(n.Fragment), "fragment mismatch")
+		})
+	}
+}
+
+func TestParser_AllowQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc         string
+		give         string
+		allow        bool
+		wantTarget   string
+		wantQuery    string
+		wantFragment string
+	}{
+		{
+			desc:       "disabled",
+			give:       "[[What is Go?]]",
+			wantTarget: "What is Go?",
+		},
+		{
+			desc:       "query",
+			give:       "[[search?tag=golang]]",
+			allow:      true,
+			wantTarget: "search",
+			wantQuery:  "tag=golang",
+		},
+		{
+			desc:         "query and fragment",
+			give:         "[[search?q=a?b#results|label]]",
+			allow:        true,
+			wantTarget:   "search",
+			wantQuery:    "q=a?b",
+			wantFragment: "results",
+		},
+		{
+			desc:       "query only",
+			give:       "[[?page=2]]",
+			allow:      true,
+			wantTarget: "",
+			wantQuery:  "page=2",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := Parser{AllowQuery: tt.allow}
+			got := p.Parse(nil /* parent */, text.NewReader([]byte(tt.give)), parser.NewContext())
+			require.NotNil(t, got, "expected Node, got nil")
+
+			n, ok := got.(*Node)
+			require.True(t, ok, "expected Node, got %T", got)
+			assert.Equal(t, tt.wantTarget, string(n.Target), "target mismatch")
+			assert.Equal(t, tt.wantQuery, string(n.Query), "query mismatch")
+			assert.Equal(t, tt.wantFragment, string(n.Fragment), "fragment mismatch")
+		})
+	}
+}
+
+func TestParser_ConvertBackslashes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc         string
+		give         string
+		wantTarget   string
+		wantFragment string
+	}{
+		{
+			desc:       "plain",
+			give:       "[[notes/Foo]]",
+			wantTarget: "notes/Foo",
+		},
+		{
+			desc:       "windows path",
+			give:       `[[notes\2024\Foo.md]]`,
+			wantTarget: "notes/2024/Foo.md",
+		},
+		{
+			desc:         "fragment",
+			give:         `[[notes\Foo#a\b]]`,
+			wantTarget:   "notes/Foo",
+			wantFragment: `a\b`,
+		},
+		{
+			desc:       "escapes",
+			give:       `[[notes\C\# notes\|label]]`,
+			wantTarget: "notes/C# notes|label",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			src := []byte(tt.give)
+			p := Parser{ConvertBackslashes: true}
+			got := p.Parse(nil /* parent */, text.NewReader(src), parser.NewContext())
+			require.NotNil(t, got, "expected Node, got nil")
+
+			n, ok := got.(*Node)
+			require.True(t, ok, "expected Node, got %T", got)
+			assert.Equal(t, tt.wantTarget, string(n.Target), "target mismatch")
+			assert.Equal(t, tt.wantFragment, string(n.Fragment), "fragment mismatch")
+			assert.Equal(t, tt.give, string(src), "source must not be modified")
+		})
+	}
+}
+
+func TestParser_FragmentSeparator(t *testing.T) {
+	t.Parallel()
+
+	p := Parser{FragmentSeparator: '>'}
+	got := p.Parse(nil /* parent */, text.NewReader([]byte("[[Foo#1>Bar|baz]]")), parser.NewContext())
+	require.NotNil(t, got, "expected Node, got nil")
+
+	n, ok := got.(*Node)
+	require.True(t, ok, "expected Node, got %T", got)
+	assert.Equal(t, "Foo#1", string(n.Target), "target mismatch")
+	assert.Equal(t, "Bar", string(n.Fragment), "fragment mismatch")
+
+	got = p.Parse(nil /* parent */, text.NewReader([]byte(`[[a\>b>c]]`)), parser.NewContext())
+	require.NotNil(t, got, "expected Node, got nil")
+	n = got.(*Node)
+	assert.Equal(t, "a>b", string(n.Target), "escaped separator: target mismatch")
+	assert.Equal(t, "c", string(n.Fragment), "escaped separator: fragment mismatch")
+}
+
+func TestParser_LinkType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give string
+		want []string // link types of the wikilinks, in order
+	}{
+		{desc: "untyped", give: "See [[Foo]]", want: []string{""}},
+		{desc: "typed", give: "supports:: [[Claim]]", want: []string{"supports"}},
+		{desc: "no space", give: "supports::[[Claim]]", want: []string{"supports"}},
+		{desc: "list item", give: "- is-part-of:: [[Whole]]", want: []string{"is-part-of"}},
+		{desc: "after text", give: "This supports:: [[Claim]]", want: []string{"supports"}},
+		{desc: "only first", give: "refutes:: [[A]], [[B]]", want: []string{"refutes", ""}},
+		{desc: "embed", give: "cover:: ![[cat.png]]", want: []string{"cover"}},
+		{desc: "single colon", give: "supports: [[Claim]]", want: []string{""}},
+		{desc: "inside word", give: "a.b:: [[Claim]]", want: []string{""}},
+		{desc: "previous line", give: "supports::\n[[Claim]]", want: []string{""}},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(&Extender{}))
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			doc := md.Parser().Parse(text.NewReader([]byte(tt.give)))
+			var got []string
+			_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+				if n, ok := n.(*Node); ok && entering {
+					got = append(got, string(n.LinkType))
+				}
+				return ast.WalkContinue, nil
+			})
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func BenchmarkParser(b *testing.B) {
+	var src bytes.Buffer
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&src, "See [[Page %d]], [[Page %d#Section|the section]], and ![[image %d.png]].\n\n", i, i, i)
+	}
+
+	p := goldmark.New(goldmark.WithExtensions(&Extender{})).Parser()
+
+	b.ReportAllocs()
+	b.SetBytes(int64(src.Len()))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Parse(text.NewReader(src.Bytes()))
+	}
+}
+
+func BenchmarkParser_Parse(b *testing.B) {
+	src := []byte("[[Foo bar#Baz|qux]]")
+	parent := ast.NewParagraph()
+	pc := parser.NewContext()
+	var p Parser
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if p.Parse(parent, text.NewReader(src), pc) == nil {
+			b.Fatal("expected a wikilink")
+		}
+	}
+}
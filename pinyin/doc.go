@@ -0,0 +1,6 @@
+// Package pinyin provides a goldmark-wikilink resolver that transliterates
+// Chinese wikilink targets to pinyin.
+//
+// It lives in a separate module so that the main wikilink package does not
+// depend on a pinyin dictionary.
+package pinyin
@@ -0,0 +1,25 @@
+module github.com/kentxxq/goldmark-wikilink/pinyin
+
+go 1.21
+
+// For development inside this repository only:
+// build against the parent module in this checkout.
+// Replace directives are ignored when this module is used as a dependency,
+// so the requirement below must name a release of the parent module
+// with every API this module uses.
+replace github.com/kentxxq/goldmark-wikilink => ../
+
+require (
+	// v0.6.0 is the first release with wikilink.ResolveDetails.
+	// Tag it before tagging this module; see RELEASING.md.
+	github.com/kentxxq/goldmark-wikilink v0.6.0
+	github.com/mozillazg/go-pinyin v0.20.0
+	github.com/stretchr/testify v1.7.0
+	github.com/yuin/goldmark v1.1.32
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
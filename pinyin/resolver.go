@@ -0,0 +1,93 @@
+package pinyin
+
+import (
+	"strings"
+	"unicode"
+
+	wikilink "github.com/kentxxq/goldmark-wikilink"
+	gopinyin "github.com/mozillazg/go-pinyin"
+)
+
+// Resolver builds a wikilink resolver that transliterates Chinese
+// characters in targets to pinyin before resolving them with next.
+//
+// Each Chinese character becomes a separate word, so pairing this with a
+// slugging resolver generates URLs like those of many Chinese Hugo themes.
+//
+//	r := pinyin.Resolver(&wikilink.SlugResolver{Suffix: "/"})
+//	[[数据库]]    // => "shu-ju-ku/"
+//	[[Go 数据库]] // => "go-shu-ju-ku/"
+//
+// Fragments are left as-is.
+func Resolver(next wikilink.Resolver) wikilink.Resolver {
+	return &resolver{next: next}
+}
+
+type resolver struct {
+	next wikilink.Resolver
+}
+
+var _ wikilink.DetailedResolver = (*resolver)(nil)
+
+func (r *resolver) ResolveWikilink(n *wikilink.Node) ([]byte, error) {
+	res, err := r.ResolveWikilinkDetails(n)
+	return res.Destination, err
+}
+
+func (r *resolver) ResolveWikilinkDetails(n *wikilink.Node) (wikilink.Resolution, error) {
+	target := Transliterate(string(n.Target))
+	if target == string(n.Target) {
+		return wikilink.ResolveDetails(r.next, n)
+	}
+
+	c := *n
+	c.Target = []byte(target)
+	return wikilink.ResolveDetails(r.next, &c)
+}
+
+// Transliterate replaces Chinese characters in s with their pinyin,
+// without tones, separated from each other and from surrounding text by
+// spaces. Other characters are left as-is.
+//
+//	Transliterate("数据库")   // => "shu ju ku"
+//	Transliterate("Go数据库") // => "Go shu ju ku"
+//	Transliterate("a/数据库") // => "a/shu ju ku"
+func Transliterate(s string) string {
+	args := gopinyin.NewArgs()
+
+	var (
+		sb   strings.Builder
+		last rune // last character written, or 0
+		han  bool // whether the last character was Chinese
+	)
+	// sep reports whether a space is needed to separate a word from
+	// the last character written.
+	sep := func() bool {
+		return last != 0 && last != '/' && !unicode.IsSpace(last)
+	}
+
+	for _, r := range s {
+		if !unicode.Is(unicode.Han, r) {
+			if han && sep() && r != '/' && !unicode.IsSpace(r) {
+				sb.WriteByte(' ')
+			}
+			sb.WriteRune(r)
+			last, han = r, false
+			continue
+		}
+
+		py := gopinyin.SinglePinyin(r, args)
+		if len(py) == 0 {
+			sb.WriteRune(r)
+			last, han = r, true
+			continue
+		}
+
+		if sep() {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(py[0])
+		last, han = 'a', true // any letter
+	}
+	return sb.String()
+}
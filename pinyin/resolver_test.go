@@ -0,0 +1,72 @@
+package pinyin
+
+import (
+	"bytes"
+	"testing"
+
+	wikilink "github.com/kentxxq/goldmark-wikilink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestTransliterate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give string
+		want string
+	}{
+		{give: "数据库", want: "shu ju ku"},
+		{give: "Go数据库", want: "Go shu ju ku"},
+		{give: "数据库 设计", want: "shu ju ku she ji"},
+		{give: "笔记/数据库", want: "bi ji/shu ju ku"},
+		{give: "数据库v2", want: "shu ju ku v2"},
+		{give: "Foo", want: "Foo"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, Transliterate(tt.give))
+		})
+	}
+}
+
+func TestResolver(t *testing.T) {
+	t.Parallel()
+
+	r := Resolver(&wikilink.SlugResolver{Suffix: "/"})
+
+	n := &wikilink.Node{Target: []byte("数据库"), Fragment: []byte("Index")}
+	got, err := r.ResolveWikilink(n)
+	require.NoError(t, err)
+	assert.Equal(t, "shu-ju-ku/#index", string(got))
+	assert.Equal(t, "数据库", string(n.Target), "node must not be modified")
+}
+
+type detailedResolverFunc func(*wikilink.Node) (wikilink.Resolution, error)
+
+func (f detailedResolverFunc) ResolveWikilink(n *wikilink.Node) ([]byte, error) {
+	res, err := f(n)
+	return res.Destination, err
+}
+
+func (f detailedResolverFunc) ResolveWikilinkDetails(n *wikilink.Node) (wikilink.Resolution, error) {
+	return f(n)
+}
+
+func TestResolver_Private(t *testing.T) {
+	t.Parallel()
+
+	r := Resolver(detailedResolverFunc(func(*wikilink.Node) (wikilink.Resolution, error) {
+		return wikilink.Resolution{Destination: []byte("secret.html"), Private: true}, nil
+	}))
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{Resolver: r}))
+
+	var buf bytes.Buffer
+	require.NoError(t, md.Convert([]byte("[[数据库]] [[Foo]]"), &buf))
+	assert.Equal(t, "<p>数据库 Foo</p>", string(bytes.TrimSpace(buf.Bytes())))
+}
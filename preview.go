@@ -0,0 +1,160 @@
+package wikilink
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Previewer extracts the previews of notes shown when hovering over
+// wikilinks to them: the first few blocks of a note, rendered as HTML.
+// Serve them as JSON from an endpoint that pages fetch on hover,
+// or embed them in the pages as data.
+//
+//	p := &wikilink.Previewer{Index: idx, Blocks: 2}
+//	preview, err := p.Preview("Foo#Details")
+//	json.NewEncoder(w).Encode(preview)
+//	// {"id": "notes/Foo", "title": "Foo", "html": "<h2>Details</h2>\n<p>..."}
+//
+// The raw HTML in notes is left out of previews
+// unless Markdown is set to render it.
+type Previewer struct {
+	// Index of the files in the vault.
+	// Notes are read from the file systems they were scanned from.
+	Index *Index
+
+	// Markdown renders the previews.
+	// Don't make it render raw HTML, with html.WithUnsafe,
+	// unless the notes in the vault are trusted.
+	//
+	// Defaults to goldmark with an Extender
+	// that resolves wikilinks with an IndexResolver for Index.
+	Markdown goldmark.Markdown
+
+	// Blocks is the most top-level blocks, like paragraphs and lists,
+	// to include in a preview.
+	//
+	// Defaults to 3.
+	Blocks int
+
+	// Words, if set, ends previews after the first block
+	// that brings the number of words in them to Words or more,
+	// even if there are fewer than Blocks blocks.
+	Words int
+}
+
+// Preview is the preview of a note.
+type Preview struct {
+	// ID of the note in the Index, like "notes/Foo".
+	ID string `json:"id"`
+
+	// Title of the note, if any. See Index.Title.
+	Title string `json:"title,omitempty"`
+
+	// HTML is the rendered start of the note,
+	// or of the section that the fragment of the target points to.
+	// It's empty for files other than notes, like images,
+	// and for files registered with Index.Add.
+	HTML string `json:"html"`
+}
+
+// Preview returns the preview of the note that the given target points to,
+// like "Foo", "Foo#Details", or "[[Foo]]", with the same rules as
+// IndexResolver.
+// Previews of targets with fragments start at the heading they name,
+// if there's one.
+//
+// It fails with a TargetNotFoundError or an AmbiguousTargetError
+// if the target doesn't match exactly one file in the index.
+func (p *Previewer) Preview(target string) (*Preview, error) {
+	n := ParseTarget(target)
+	if n == nil || len(n.Target) == 0 {
+		return nil, &InvalidTargetError{Node: &Node{Target: []byte(target)}, Reason: "empty target"}
+	}
+	if err := p.Index.Load(); err != nil {
+		return nil, err
+	}
+
+	ids := lookupTarget(p.Index, string(n.Target))
+	switch len(ids) {
+	case 0:
+		return nil, &TargetNotFoundError{Node: n}
+	case 1:
+	default:
+		return nil, &AmbiguousTargetError{Node: n, Candidates: ids}
+	}
+
+	preview := &Preview{ID: ids[0], Title: p.Index.Title(ids[0])}
+	f, ok := p.Index.file(ids[0])
+	if !ok || !strings.EqualFold(path.Ext(f.Path), ".md") {
+		return preview, nil // not a note
+	}
+	src, err := fs.ReadFile(f.FS, f.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	_, body, _ := frontMatter(src)
+	md := p.markdown()
+	doc := md.Parser().Parse(text.NewReader(body))
+	p.trim(doc, body, string(n.Fragment))
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, body, doc); err != nil {
+		return nil, fmt.Errorf("%v: %w", ids[0], err)
+	}
+	preview.HTML = buf.String()
+	return preview, nil
+}
+
+// markdown returns the goldmark.Markdown that renders previews.
+func (p *Previewer) markdown() goldmark.Markdown {
+	if p.Markdown != nil {
+		return p.Markdown
+	}
+	return goldmark.New(goldmark.WithExtensions(&Extender{
+		Resolver: &IndexResolver{Index: p.Index},
+	}))
+}
+
+// trim removes the blocks of doc that aren't part of the preview:
+// those before the heading named by fragment, if any,
+// and those after Blocks blocks or Words words.
+func (p *Previewer) trim(doc ast.Node, src []byte, fragment string) {
+	maxBlocks := p.Blocks
+	if maxBlocks <= 0 {
+		maxBlocks = 3
+	}
+
+	start := doc.FirstChild()
+	if len(fragment) > 0 {
+		for c := doc.FirstChild(); c != nil; c = c.NextSibling() {
+			if h, ok := c.(*ast.Heading); ok && strings.EqualFold(string(h.Text(src)), fragment) {
+				start = c
+				break
+			}
+		}
+	}
+
+	var blocks, words int
+	var keep bool
+	for c := doc.FirstChild(); c != nil; {
+		next := c.NextSibling()
+		if c == start {
+			keep = true
+		}
+		if keep && blocks < maxBlocks && (p.Words <= 0 || words < p.Words) {
+			blocks++
+			words += len(strings.Fields(string(c.Text(src))))
+		} else {
+			doc.RemoveChild(doc, c)
+		}
+		c = next
+	}
+}
@@ -0,0 +1,125 @@
+package wikilink_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	wikilink "github.com/kentxxq/goldmark-wikilink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestPreviewer(t *testing.T) {
+	t.Parallel()
+
+	idx := &wikilink.Index{FS: fstest.MapFS{
+		"notes/Foo.md": {Data: []byte("---\ntitle: All about foo\n---\n" +
+			"Foo is a [[Bar|kind of bar]].\n\n" +
+			"<script>alert(1)</script>\n\n" +
+			"- one\n- two\n\n" +
+			"## Details\n\n" +
+			"The details of foo, in many more words than the others.\n\n" +
+			"More details.\n")},
+		"Bar.md":        {Data: []byte("# Bar\n\nA bar.\n")},
+		"a/Dup.md":      {},
+		"b/Dup.md":      {},
+		"cat.png":       {Data: []byte("PNG")},
+		"Empty Note.md": {},
+	}}
+
+	tests := []struct {
+		desc   string
+		give   string
+		blocks int
+		words  int
+		want   *wikilink.Preview
+	}{
+		{
+			desc: "default",
+			give: "Foo",
+			want: &wikilink.Preview{
+				ID:    "notes/Foo",
+				Title: "All about foo",
+				HTML: `<p>Foo is a <a href="Bar.html">kind of bar</a>.</p>` + "\n" +
+					"<!-- raw HTML omitted -->\n" +
+					"<ul>\n<li>one</li>\n<li>two</li>\n</ul>\n",
+			},
+		},
+		{
+			desc:   "blocks",
+			give:   "[[notes/Foo]]",
+			blocks: 1,
+			want: &wikilink.Preview{
+				ID:    "notes/Foo",
+				Title: "All about foo",
+				HTML:  `<p>Foo is a <a href="Bar.html">kind of bar</a>.</p>` + "\n",
+			},
+		},
+		{
+			desc:  "heading",
+			give:  "Foo#details",
+			words: 5,
+			want: &wikilink.Preview{
+				ID:    "notes/Foo",
+				Title: "All about foo",
+				HTML: "<h2>Details</h2>\n" +
+					"<p>The details of foo, in many more words than the others.</p>\n",
+			},
+		},
+		{
+			desc: "missing heading",
+			give: "Bar#Nope",
+			want: &wikilink.Preview{
+				ID:    "Bar",
+				Title: "Bar",
+				HTML:  "<h1>Bar</h1>\n<p>A bar.</p>\n",
+			},
+		},
+		{
+			desc: "not a note",
+			give: "cat.png",
+			want: &wikilink.Preview{ID: "cat.png"},
+		},
+		{
+			desc: "empty note",
+			give: "empty note",
+			want: &wikilink.Preview{ID: "Empty Note"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := &wikilink.Previewer{Index: idx, Blocks: tt.blocks, Words: tt.words}
+			got, err := p.Preview(tt.give)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+
+		p := &wikilink.Previewer{Index: idx}
+		_, err := p.Preview("Missing")
+		assert.ErrorIs(t, err, wikilink.ErrTargetNotFound)
+
+		_, err = p.Preview("Dup")
+		assert.ErrorIs(t, err, wikilink.ErrAmbiguousTarget)
+
+		_, err = p.Preview("[[#Details]]")
+		assert.ErrorIs(t, err, wikilink.ErrInvalidTarget)
+	})
+
+	t.Run("markdown", func(t *testing.T) {
+		t.Parallel()
+
+		p := &wikilink.Previewer{Index: idx, Markdown: goldmark.New(), Blocks: 1}
+		got, err := p.Preview("Foo")
+		require.NoError(t, err)
+		assert.Equal(t, "<p>Foo is a [[Bar|kind of bar]].</p>\n", got.HTML)
+	})
+}
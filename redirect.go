@@ -0,0 +1,120 @@
+package wikilink
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/yuin/goldmark/util"
+)
+
+// Redirect is a redirect from an old URL of a page to its canonical URL.
+type Redirect struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Redirects is a list of redirects, sorted by From.
+// Write it out in the format of your web server or host
+// so that links to renamed notes keep working.
+//
+//	pages, err := wikilink.LoadPageMeta(os.DirFS("content"))
+//	redirects, err := wikilink.BuildRedirects(pages, resolver)
+//	err = redirects.WriteNetlify(f) // public/_redirects
+type Redirects []Redirect
+
+// BuildRedirects builds redirects from the Aliases of pages,
+// as loaded by LoadPageMeta, to their canonical URLs.
+//
+// Both the canonical URLs and the aliases are resolved with r,
+// or DefaultResolver if r is nil, so use it with a resolver that builds
+// absolute URLs, like NewRootResolver.
+// Aliases starting with "/" are used as URLs as-is.
+//
+// Pages that don't resolve to a destination, like drafts with
+// UnpublishedResolver, and aliases that resolve to the canonical URL
+// are left out.
+func BuildRedirects(pages map[string]PageMeta, r Resolver) (Redirects, error) {
+	var redirects Redirects
+	for id, meta := range pages {
+		if len(meta.Aliases) == 0 {
+			continue
+		}
+
+		to, err := Resolve(id, "", r)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", id, err)
+		}
+		if len(to) == 0 {
+			continue
+		}
+
+		for _, alias := range meta.Aliases {
+			from := alias
+			if len(alias) == 0 || alias[0] != '/' {
+				from, err = Resolve(alias, "", r)
+				if err != nil {
+					return nil, fmt.Errorf("%v: alias %q: %w", id, alias, err)
+				}
+			}
+			if len(from) == 0 || from == to {
+				continue
+			}
+			redirects = append(redirects, Redirect{
+				From: string(util.URLEscape([]byte(from), true /* resolve references */)),
+				To:   string(util.URLEscape([]byte(to), true /* resolve references */)),
+			})
+		}
+	}
+
+	sort.SliceStable(redirects, func(i, j int) bool {
+		return redirects[i].From < redirects[j].From
+	})
+	return redirects, nil
+}
+
+// WriteNetlify writes the redirects to w as permanent redirects
+// in the format of Netlify's _redirects file,
+// which Cloudflare Pages also understands.
+//
+//	/old-name/ /new-name/ 301
+func (rs Redirects) WriteNetlify(w io.Writer) error {
+	for _, r := range rs {
+		if _, err := fmt.Fprintf(w, "%s %s 301\n", r.From, r.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteNginxMap writes the redirects to w as the entries of an nginx map
+// block, to be included inside one.
+//
+//	map $uri $redirect_uri {
+//		include redirects.map;
+//	}
+//
+// The entries are written as:
+//
+//	/old-name/ /new-name/;
+func (rs Redirects) WriteNginxMap(w io.Writer) error {
+	for _, r := range rs {
+		if _, err := fmt.Fprintf(w, "%s %s;\n", r.From, r.To); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the redirects to w as a JSON array of objects
+// with "from" and "to" keys.
+func (rs Redirects) WriteJSON(w io.Writer) error {
+	if rs == nil {
+		rs = Redirects{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode([]Redirect(rs))
+}
@@ -0,0 +1,94 @@
+package wikilink
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildRedirects(t *testing.T) {
+	t.Parallel()
+
+	pages := map[string]PageMeta{
+		"notes/new name": {Aliases: []string{"notes/Old Name", "/legacy/page.html", "notes/new name"}},
+		"posts/draft":    {Draft: true, Aliases: []string{"posts/old-draft"}},
+		"about":          {},
+	}
+
+	redirects, err := BuildRedirects(pages, &UnpublishedResolver{
+		Pages: pages,
+		Next:  NewRootResolver("/"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Redirects{
+		{From: "/legacy/page.html", To: "/notes/new%20name/"},
+		{From: "/notes/Old%20Name/", To: "/notes/new%20name/"},
+	}, redirects)
+
+	t.Run("Netlify", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, redirects.WriteNetlify(&buf))
+		assert.Equal(t, "/legacy/page.html /notes/new%20name/ 301\n"+
+			"/notes/Old%20Name/ /notes/new%20name/ 301\n", buf.String())
+	})
+
+	t.Run("nginx", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, redirects.WriteNginxMap(&buf))
+		assert.Equal(t, "/legacy/page.html /notes/new%20name/;\n"+
+			"/notes/Old%20Name/ /notes/new%20name/;\n", buf.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, redirects.WriteJSON(&buf))
+		assert.Equal(t, `[
+  {
+    "from": "/legacy/page.html",
+    "to": "/notes/new%20name/"
+  },
+  {
+    "from": "/notes/Old%20Name/",
+    "to": "/notes/new%20name/"
+  }
+]
+`, buf.String())
+	})
+}
+
+func TestBuildRedirects_Empty(t *testing.T) {
+	t.Parallel()
+
+	redirects, err := BuildRedirects(map[string]PageMeta{"foo": {}}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, redirects)
+
+	var buf bytes.Buffer
+	require.NoError(t, redirects.WriteJSON(&buf))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestBuildRedirects_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := BuildRedirects(
+		map[string]PageMeta{"foo": {Aliases: []string{"bar"}}},
+		resolverFunc(func(n *Node) ([]byte, error) {
+			if string(n.Target) == "bar" {
+				return nil, &TargetNotFoundError{Node: n}
+			}
+			return []byte("/foo/"), nil
+		}),
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `alias "bar"`)
+	assert.ErrorIs(t, err, ErrTargetNotFound)
+}
@@ -0,0 +1,68 @@
+package wikilink
+
+import (
+	"bytes"
+	"path"
+	"strings"
+)
+
+// htmlPageURL is the default Renderer.PageURL.
+// It places the page for a document next to it,
+// with an ".html" extension.
+//
+//	notes/Foo.md // => /notes/Foo.html
+func htmlPageURL(source string) string {
+	source = path.Clean("/" + source)
+	return strings.TrimSuffix(source, path.Ext(source)) + ".html"
+}
+
+func (r *Renderer) pageURL(source string) string {
+	if r.PageURL != nil {
+		return r.PageURL(source)
+	}
+	return htmlPageURL(source)
+}
+
+// relativeDestination rewrites the root-relative destination dest
+// relative to the page at the URL path page.
+// Other destinations are returned as-is.
+//
+//	relativeDestination("/notes/a.html", "/docs/Foo/#Bar") // => ../docs/Foo/#Bar
+//	relativeDestination("/notes/a/", "/notes/b/")          // => ../b/
+func relativeDestination(page string, dest []byte) []byte {
+	if len(dest) == 0 || dest[0] != '/' || bytes.HasPrefix(dest, []byte("//")) {
+		return dest
+	}
+
+	end := len(dest)
+	if idx := bytes.IndexAny(dest, "?#"); idx >= 0 {
+		end = idx
+	}
+	target, rest := string(dest[:end]), dest[end:]
+
+	// The page's directory is everything up to its last "/",
+	// so "/notes/a/" is in "/notes/a" and "/notes/a.html" in "/notes".
+	from := splitPath(page[:strings.LastIndexByte(page, '/')+1])
+	to := splitPath(target)
+
+	var common int
+	for common < len(from) && common < len(to) && from[common] == to[common] {
+		common++
+	}
+
+	var sb strings.Builder
+	for i := common; i < len(from); i++ {
+		sb.WriteString("../")
+	}
+	sb.WriteString(strings.Join(to[common:], "/"))
+	if strings.HasSuffix(target, "/") && len(to) > common {
+		sb.WriteByte('/')
+	}
+	if sb.Len() == 0 {
+		sb.WriteString("./")
+	}
+
+	out := make([]byte, 0, sb.Len()+len(rest))
+	out = append(out, sb.String()...)
+	return append(out, rest...)
+}
@@ -0,0 +1,140 @@
+package wikilink
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+)
+
+func TestRelativeDestination(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		page string
+		give string
+		want string
+	}{
+		{page: "/a.html", give: "/b.html", want: "b.html"},
+		{page: "/notes/a.html", give: "/docs/Foo/#Bar", want: "../docs/Foo/#Bar"},
+		{page: "/notes/a.html", give: "/notes/b.html?x=1", want: "b.html?x=1"},
+		{page: "/notes/a.html", give: "/notes/", want: "./"},
+		{page: "/notes/a.html", give: "/", want: "../"},
+		{page: "/notes/a/", give: "/notes/b/", want: "../b/"},
+		{page: "/notes/a/", give: "/notes/a/#top", want: "./#top"},
+		{page: "/a/b/c/d.html", give: "/a/x/y.png", want: "../../x/y.png"},
+		{page: "/notes/a.html", give: "b.html", want: "b.html"},
+		{page: "/notes/a.html", give: "#Bar", want: "#Bar"},
+		{page: "/notes/a.html", give: "//cdn.example.com/x.png", want: "//cdn.example.com/x.png"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.page+" "+tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, string(relativeDestination(tt.page, []byte(tt.give))))
+		})
+	}
+}
+
+func TestHTMLPageURL(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "/notes/Foo.html", htmlPageURL("notes/Foo.md"))
+	assert.Equal(t, "/index.html", htmlPageURL("./index.md"))
+}
+
+func TestRenderer_RelativeDestinations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		source  string
+		pageURL func(string) string
+		want    string
+	}{
+		{
+			desc:   "nested",
+			source: "notes/a.md",
+			want:   `<p><a href="../docs/Foo/">docs/Foo</a> <a href="https://example.com/">https://example.com/</a> <img src="../img/cat.png"></p>`,
+		},
+		{
+			desc:   "root",
+			source: "index.md",
+			want:   `<p><a href="docs/Foo/">docs/Foo</a> <a href="https://example.com/">https://example.com/</a> <img src="img/cat.png"></p>`,
+		},
+		{
+			desc:   "pretty",
+			source: "notes/a.md",
+			pageURL: func(source string) string {
+				return "/" + strings.TrimSuffix(source, ".md") + "/"
+			},
+			want: `<p><a href="../../docs/Foo/">docs/Foo</a> <a href="https://example.com/">https://example.com/</a> <img src="../../img/cat.png"></p>`,
+		},
+		{
+			desc: "no source",
+			want: `<p><a href="/docs/Foo/">docs/Foo</a> <a href="https://example.com/">https://example.com/</a> <img src="/img/cat.png"></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			md := goldmark.New(goldmark.WithExtensions(&Extender{
+				Resolver:             NewRootResolver("/"),
+				RelativeDestinations: true,
+				PageURL:              tt.pageURL,
+			}))
+
+			ctx := parser.NewContext()
+			if len(tt.source) > 0 {
+				SetContextSource(ctx, tt.source)
+			}
+
+			var buf bytes.Buffer
+			give := "[[docs/Foo]] [[https://example.com/]] ![[img/cat.png]]"
+			require.NoError(t, md.Convert([]byte(give), &buf, parser.WithContext(ctx)))
+			assert.Equal(t, tt.want, strings.TrimSpace(buf.String()))
+		})
+	}
+}
+
+func TestRenderer_RelativeDestinations_embedHandlers(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"code/snip.go":              {Data: []byte("package snip\n")},
+		"img/sketch.excalidraw.md":  {},
+		"img/sketch.excalidraw.svg": {},
+		"video/clip.mp4":            {},
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(&Extender{
+		Resolver:             NewRootResolver("/"),
+		RelativeDestinations: true,
+		EmbedHandlers: map[string]EmbedHandler{
+			".go":            &CodeEmbed{FS: files},
+			".excalidraw.md": &ExcalidrawEmbed{FS: files},
+		},
+	}))
+
+	ctx := parser.NewContext()
+	SetContextSource(ctx, "notes/a.md")
+
+	var buf bytes.Buffer
+	give := "![[code/snip.go]]\n\n![[img/sketch.excalidraw.md]]\n\n![[video/clip.mp4]]"
+	require.NoError(t, md.Convert([]byte(give), &buf, parser.WithContext(ctx)))
+	assert.Equal(t, `<pre><code class="language-go">package snip
+</code></pre>
+<p><img src="../img/sketch.excalidraw.svg" alt="sketch"></p>
+<p><video controls src="../video/clip.mp4"><a href="../video/clip.mp4">video/clip.mp4</a></video></p>`,
+		strings.TrimSpace(buf.String()))
+}
@@ -0,0 +1,255 @@
+package wikilink
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Renamer rewrites wikilinks to a note when the note is renamed.
+// Use it to build tools that maintain a vault of Markdown notes.
+//
+//	var r wikilink.Renamer
+//	patches, err := r.RenameFS(os.DirFS(vault), "Old Name", "New Name")
+//	for _, p := range patches {
+//		os.WriteFile(filepath.Join(vault, p.Path), p.Result, 0o644)
+//	}
+//
+// Only the targets of wikilinks are changed.
+// Fragments, aliases, and everything else in the sources are kept as-is.
+//
+//	[[Old Name#Intro|the intro]] // => [[New Name#Intro|the intro]]
+//	![[Old Name]]                // => ![[New Name]]
+//
+// Targets are matched exactly, with or without a ".md" extension,
+// unless Index is set.
+// Wikilinks inside code spans and code blocks are not changed.
+//
+// The zero value is ready to use.
+// A Renamer is safe for concurrent use.
+type Renamer struct {
+	// Parser finds wikilinks in the sources.
+	// Set this to the Parser used to render the vault
+	// if it's configured differently, e.g. with a FragmentSeparator.
+	//
+	// Defaults to a zero Parser.
+	Parser *Parser
+
+	// Index, if set, holds the notes of the vault before the rename.
+	// Wikilinks are then renamed exactly when their targets find
+	// the renamed note in it, the way IndexResolver looks them up,
+	// so that renaming "notes/Old" changes [[Old]], [[old]],
+	// and [[notes/Old]], but not [[archive/Old]].
+	//
+	// With Index set, from and to are the paths of the note,
+	// without the ".md" extension.
+	// Targets written with a path get the new path,
+	// and those written as a name alone get the new name.
+	Index *Index
+
+	// Skipped, if set, is called with each wikilink to the renamed note
+	// that can't be changed because its target spans lines,
+	// as allowed by Parser.AllowSoftLineBreaks,
+	// and the line it starts on, so that it can be fixed by hand.
+	// For RenameFS, n.Source() is the path of the file containing it.
+	Skipped func(n *Node, line int)
+
+	once   sync.Once
+	parser parser.Parser
+}
+
+// RenameEdit replaces Source[Start:Stop] with Text.
+type RenameEdit struct {
+	Start, Stop int
+	Text        string
+}
+
+// RenamePatch lists the changes to a single file.
+type RenamePatch struct {
+	// Path of the file in the fs.FS.
+	Path string
+
+	// Edits to the original contents of the file, in order.
+	Edits []RenameEdit
+
+	// Result is the contents of the file with the edits applied.
+	Result []byte
+}
+
+func (r *Renamer) init() {
+	r.once.Do(func() {
+		p := r.Parser
+		if p == nil {
+			p = &Parser{}
+		}
+		r.parser = parser.NewParser(
+			parser.WithBlockParsers(parser.DefaultBlockParsers()...),
+			parser.WithInlineParsers(append(
+				parser.DefaultInlineParsers(),
+				util.Prioritized(p, 199),
+			)...),
+			parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+		)
+	})
+}
+
+// Edits returns the edits needed to rename wikilinks to from
+// in src so that they point to to instead.
+func (r *Renamer) Edits(src []byte, from, to string) []RenameEdit {
+	return r.edits(src, "", from, to)
+}
+
+// edits is Edits for the file at the given path, if known.
+func (r *Renamer) edits(src []byte, source, from, to string) []RenameEdit {
+	r.init()
+
+	from = strings.TrimSuffix(from, ".md")
+	to = strings.TrimSuffix(to, ".md")
+
+	pc := parser.NewContext()
+	if len(source) > 0 {
+		SetContextSource(pc, source)
+	}
+
+	var edits []RenameEdit
+	doc := r.parser.Parse(text.NewReader(src), parser.WithContext(pc))
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		n, ok := node.(*Node)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+
+		target, ok := r.renamedTarget(string(n.Target), from, to)
+		if !ok {
+			return ast.WalkSkipChildren, nil
+		}
+		if n.targetSegment.Len() == 0 {
+			// The target spans multiple lines.
+			if r.Skipped != nil {
+				r.Skipped(n, nodeLine(n, src))
+			}
+			return ast.WalkSkipChildren, nil
+		}
+
+		edits = append(edits, RenameEdit{
+			Start: n.targetSegment.Start,
+			Stop:  n.targetSegment.Stop,
+			Text:  escapeTarget(target, r.fragmentSeparator()),
+		})
+		return ast.WalkSkipChildren, nil
+	})
+	return edits
+}
+
+// renamedTarget reports whether target refers to the note from,
+// and returns the target that refers to the note to instead.
+func (r *Renamer) renamedTarget(target, from, to string) (string, bool) {
+	var ext string
+	if strings.HasSuffix(target, ".md") {
+		ext = ".md"
+	}
+
+	if r.Index == nil {
+		if strings.TrimSuffix(target, ext) != from {
+			return "", false
+		}
+		return to + ext, true
+	}
+
+	ids := lookupTarget(r.Index, target)
+	if len(ids) != 1 || ids[0] != from {
+		return "", false
+	}
+	if strings.IndexByte(target, '/') < 0 {
+		to = path.Base(to)
+	}
+	return to + ext, true
+}
+
+// Rename returns a copy of src with wikilinks to from
+// changed to point to to, and whether anything was changed.
+func (r *Renamer) Rename(src []byte, from, to string) ([]byte, bool) {
+	edits := r.Edits(src, from, to)
+	if len(edits) == 0 {
+		return src, false
+	}
+	return applyRenameEdits(src, edits), true
+}
+
+// RenameFS finds wikilinks to from in all Markdown files in fsys
+// and returns patches that change them to point to to.
+// Files that don't need any changes are skipped.
+//
+// fsys is not modified. Write the results of the patches to apply them.
+// Patches are returned in lexical order of their paths.
+func (r *Renamer) RenameFS(fsys fs.FS, from, to string) ([]RenamePatch, error) {
+	var patches []RenamePatch
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(path.Ext(p), ".md") {
+			return nil
+		}
+
+		src, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		edits := r.edits(src, p, from, to)
+		if len(edits) == 0 {
+			return nil
+		}
+		patches = append(patches, RenamePatch{
+			Path:   p,
+			Edits:  edits,
+			Result: applyRenameEdits(src, edits),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return patches, nil
+}
+
+func (r *Renamer) fragmentSeparator() byte {
+	if r.Parser == nil {
+		return '#'
+	}
+	return r.Parser.fragmentSeparator()
+}
+
+// applyRenameEdits returns a copy of src with the edits applied.
+// The edits must be in order and must not overlap.
+func applyRenameEdits(src []byte, edits []RenameEdit) []byte {
+	out := make([]byte, 0, len(src))
+	var last int
+	for _, e := range edits {
+		out = append(out, src[last:e.Start]...)
+		out = append(out, e.Text...)
+		last = e.Stop
+	}
+	return append(out, src[last:]...)
+}
+
+// escapeTarget escapes characters in target that would otherwise
+// take on a special meaning in a wikilink.
+func escapeTarget(target string, sep byte) string {
+	var sb strings.Builder
+	sb.Grow(len(target))
+	for i := 0; i < len(target); i++ {
+		c := target[i]
+		if c == sep || strings.IndexByte(_escapable, c) >= 0 {
+			sb.WriteByte('\\')
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
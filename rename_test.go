@@ -0,0 +1,199 @@
+package wikilink
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenamer_Rename(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give string
+		want string // same as give if empty
+	}{
+		{
+			desc: "simple",
+			give: "See [[Old]].\n",
+			want: "See [[New Name]].\n",
+		},
+		{
+			desc: "fragment and alias",
+			give: "See [[Old#Intro|the intro]] and [[Old|old]].\n",
+			want: "See [[New Name#Intro|the intro]] and [[New Name|old]].\n",
+		},
+		{
+			desc: "embed",
+			give: "![[Old]]\n",
+			want: "![[New Name]]\n",
+		},
+		{
+			desc: "extension",
+			give: "[[Old.md]]\n",
+			want: "[[New Name.md]]\n",
+		},
+		{
+			desc: "spacing kept",
+			give: "- [[Old]]  and  [[Other]]\n- [[Old#a]]\n",
+			want: "- [[New Name]]  and  [[Other]]\n- [[New Name#a]]\n",
+		},
+		{
+			desc: "other targets",
+			give: "[[Older]] [[old]] [[Dir/Old]]\n",
+		},
+		{
+			desc: "code",
+			give: "`[[Old]]`\n\n    [[Old]]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			want := tt.want
+			if want == "" {
+				want = tt.give
+			}
+
+			var r Renamer
+			got, changed := r.Rename([]byte(tt.give), "Old", "New Name")
+			assert.Equal(t, want, string(got))
+			assert.Equal(t, tt.want != "", changed, "changed")
+		})
+	}
+}
+
+func TestRenamer_escapes(t *testing.T) {
+	t.Parallel()
+
+	var r Renamer
+	got, _ := r.Rename([]byte(`[[C\# notes#Generics]]`), "C# notes", "C#|F# notes")
+	assert.Equal(t, `[[C\#\|F\# notes#Generics]]`, string(got))
+
+	n := ParseTarget(string(got))
+	require.NotNil(t, n)
+	assert.Equal(t, "C#|F# notes", string(n.Target))
+	assert.Equal(t, "Generics", string(n.Fragment))
+}
+
+func TestRenamer_FragmentSeparator(t *testing.T) {
+	t.Parallel()
+
+	r := Renamer{Parser: &Parser{FragmentSeparator: '>'}}
+	got, _ := r.Rename([]byte(`[[Old>Intro]]`), "Old", "a>b")
+	assert.Equal(t, `[[a\>b>Intro]]`, string(got))
+}
+
+func TestRenamer_RenameFS(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"index.md":       {Data: []byte("# Index\n\n[[Old]] and [[Other]]\n")},
+		"notes/a.md":     {Data: []byte("Nothing to see here.\n")},
+		"notes/b.MD":     {Data: []byte("![[Old#^block]]\n")},
+		"notes/Old.md":   {Data: []byte("I am old.\n")},
+		"attachments.js": {Data: []byte("// [[Old]]\n")},
+	}
+
+	var r Renamer
+	patches, err := r.RenameFS(fsys, "Old", "New")
+	require.NoError(t, err)
+
+	assert.Equal(t, []RenamePatch{
+		{
+			Path:   "index.md",
+			Edits:  []RenameEdit{{Start: 11, Stop: 14, Text: "New"}},
+			Result: []byte("# Index\n\n[[New]] and [[Other]]\n"),
+		},
+		{
+			Path:   "notes/b.MD",
+			Edits:  []RenameEdit{{Start: 3, Stop: 6, Text: "New"}},
+			Result: []byte("![[New#^block]]\n"),
+		},
+	}, patches)
+}
+
+func TestRenamer_Index(t *testing.T) {
+	t.Parallel()
+
+	idx := new(Index)
+	idx.Add("notes/Old")
+	idx.Add("notes/Other")
+	idx.Add("archive/Other")
+
+	tests := []struct {
+		desc string
+		give string
+		want string // same as give if empty
+	}{
+		{
+			desc: "name",
+			give: "See [[Old]].\n",
+			want: "See [[New Name]].\n",
+		},
+		{
+			desc: "case",
+			give: "See [[old#Intro|the intro]].\n",
+			want: "See [[New Name#Intro|the intro]].\n",
+		},
+		{
+			desc: "path",
+			give: "See [[notes/Old.md]] and ![[Notes/OLD]].\n",
+			want: "See [[drafts/New Name.md]] and ![[drafts/New Name]].\n",
+		},
+		{
+			desc: "other notes",
+			give: "[[Other]] [[archive/Old]] [[Older]]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			want := tt.want
+			if want == "" {
+				want = tt.give
+			}
+
+			r := Renamer{Index: idx}
+			got, changed := r.Rename([]byte(tt.give), "notes/Old", "drafts/New Name")
+			assert.Equal(t, want, string(got))
+			assert.Equal(t, tt.want != "", changed, "changed")
+		})
+	}
+}
+
+func TestRenamer_Skipped(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"index.md": {Data: []byte("Intro\n\n[[Old Name]] and [[Old\nName|old]] and [[Old\nNames]]\n")},
+	}
+
+	type skip struct {
+		source string
+		target string
+		line   int
+	}
+	var skipped []skip
+	r := Renamer{
+		Parser: &Parser{AllowSoftLineBreaks: true},
+		Skipped: func(n *Node, line int) {
+			skipped = append(skipped, skip{n.Source(), string(n.Target), line})
+		},
+	}
+	patches, err := r.RenameFS(fsys, "Old Name", "New")
+	require.NoError(t, err)
+
+	require.Len(t, patches, 1)
+	assert.Equal(t, "Intro\n\n[[New]] and [[Old\nName|old]] and [[Old\nNames]]\n", string(patches[0].Result))
+	assert.Equal(t, []skip{{"index.md", "Old Name", 3}}, skipped)
+}
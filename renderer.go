@@ -3,8 +3,13 @@ package wikilink
 import (
 	"bytes"
 	"fmt"
+	"log/slog"
+	"net/url"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"sync"
+	"unicode/utf8"
 
 	"github.com/yuin/goldmark/ast"
 	"github.com/yuin/goldmark/renderer"
@@ -31,14 +36,305 @@ type Renderer struct {
 	//   bar
 	//
 	// Defaults to DefaultResolver if unspecified.
+	// Use SetContextResolver to override this for a single document.
 	Resolver Resolver
 
+	// URLResolver determines destinations for wikilinks whose targets
+	// are absolute URLs, like [[https://example.com]].
+	// These links bypass Resolver entirely.
+	//
+	// Defaults to a resolver that uses the URL as-is.
+	URLResolver Resolver
+
+	// URLSchemes is the list of URL schemes that identify a target as
+	// an absolute URL. Schemes are matched case-insensitively.
+	//
+	// Defaults to DefaultURLSchemes if unspecified.
+	URLSchemes []string
+
+	// EmbedHandlers renders embeds (![[...]]) of files by their
+	// extension. Keys are lowercase extensions including the leading
+	// ".", like ".csv".
+	//
+	// Handlers here take precedence over the built-in handlers,
+	// which render images as <img>, audio as <audio>, video as <video>,
+	// and PDFs as <object>. Map an extension to nil to render embeds of
+	// that type as links.
+	// Embeds of other files are rendered as links.
+	//
+	// Use RegisterEmbedHandler to add to this map.
+	EmbedHandlers map[string]EmbedHandler
+
+	// ImageSetResolver, if set, supplies alternative sources for image
+	// embeds. When it reports at least one source, the Renderer emits
+	// srcset and sizes attributes on the <img> tag alongside src.
+	//
+	// Leave this unset to render image embeds with src alone.
+	ImageSetResolver ImageSetResolver
+
+	// ImageLoading is the value of the loading attribute placed on
+	// <img> tags rendered for image embeds, e.g. "lazy" or "eager".
+	//
+	// The attribute is omitted if this is empty.
+	ImageLoading string
+
+	// ImageDecoding is the value of the decoding attribute placed on
+	// <img> tags rendered for image embeds, e.g. "async".
+	//
+	// The attribute is omitted if this is empty.
+	ImageDecoding string
+
+	// ImageFigure specifies whether image embeds with a label should be
+	// rendered inside a <figure> with the label as its <figcaption>.
+	//
+	//	![[chart.png|Quarterly revenue]]
+	//
+	// By default, the above renders as,
+	//
+	//	<img src="chart.png" alt="Quarterly revenue">
+	//
+	// With ImageFigure set, it renders as,
+	//
+	//	<figure><img src="chart.png" alt="Quarterly revenue"><figcaption>Quarterly revenue</figcaption></figure>
+	//
+	// Image embeds without a label are always rendered as bare images,
+	// as are those next to other text, like "See ![[chart.png|Revenue]]",
+	// because a <p> can't hold a <figure>.
+	// Labeled image embeds on their own are rendered
+	// in place of their paragraph.
+	ImageFigure bool
+
+	// ImageAltFromFilename specifies whether image embeds without a
+	// label should use the file name of the target, without its
+	// extension, as alt text.
+	//
+	//	![[photos/red-panda.png]]
+	//	// => <img src="photos/red-panda.png" alt="red-panda">
+	//
+	// By default, these images have no alt attribute.
+	ImageAltFromFilename bool
+
+	// MarkUnresolved specifies whether wikilinks that the Resolver did
+	// not find a destination for should be wrapped in a <span> that
+	// tells assistive technology it's a link that isn't available.
+	//
+	//	<span class="wikilink-unresolved" role="link" aria-disabled="true">Foo</span>
+	//
+	// By default, these are rendered as plain text.
+	MarkUnresolved bool
+
+	// UnresolvedAttrs, if set, replaces the attributes of the <span>
+	// that MarkUnresolved wraps unresolved wikilinks in.
+	// Values are HTML-escaped, and attributes other than "class" and
+	// "title" are written in order of their names.
+	//
+	//	UnresolvedAttrs: map[string]string{"class": "broken", "aria-disabled": "true"}
+	//	// => <span class="broken" aria-disabled="true">Foo</span>
+	UnresolvedAttrs map[string]string
+
+	// IconLabel returns the accessible name of a wikilink whose label
+	// has no text, like an icon without alt text,
+	// which is written as its aria-label attribute.
+	//
+	//	[[Home|![](home.svg)]] // with a LabelParser
+	//	// => <a href="Home.html" aria-label="Home"><img src="home.svg" alt=""></a>
+	//
+	// Defaults to the target of the wikilink, or its fragment for
+	// wikilinks to headings in the same document.
+	// Wikilinks with an aria-label attribute of their own are left as-is.
+	IconLabel func(n *Node) string
+
+	// CommentUnresolved specifies whether wikilinks that the Resolver did
+	// not find a destination for should be followed by an HTML comment
+	// naming their target, so that tools, or grep in CI,
+	// can find dead links in the rendered HTML.
+	//
+	//	Foo<!-- wikilink-missing: Foo -->
+	//
+	// Any "--" in the target is written as "- -" to keep the comment valid.
+	CommentUnresolved bool
+
+	// MarkPrivate specifies whether wikilinks that a DetailedResolver
+	// reported as Private should be wrapped in a <span>,
+	// which can be styled to show a lock icon, for example.
+	//
+	//	<span class="wikilink-private">Foo</span>
+	//
+	// By default, these are rendered as plain text.
+	MarkPrivate bool
+
+	// MissingURL, if set, is the destination of wikilinks that the
+	// Resolver did not find a destination for, like a 404 or search page.
+	// This keeps them clickable while signaling the miss:
+	// they get the "wikilink-missing" class and are reported as
+	// LinkMissing.
+	//
+	// The following placeholders are replaced with query-escaped values:
+	// {target}, {fragment}, and {source}, the name of the document
+	// set with SetContextSource.
+	//
+	//	Renderer{MissingURL: "/404?from={source}&target={target}"}
+	//	// [[Foo Bar]] => <a href="/404?from=a.md&target=Foo+Bar" class="wikilink-missing">
+	//
+	// This takes precedence over MarkUnresolved and CommentUnresolved.
+	MissingURL string
+
+	// FragmentPrefix is added to the start of the fragment of every
+	// resolved destination, if any. Use this to match the anchor scheme
+	// of your HTML pipeline.
+	//
+	//	Renderer{FragmentPrefix: "user-content-"}
+	//	// [[Foo#Bar]] => "Foo.html#user-content-Bar"
+	//
+	// This does not apply to absolute URLs.
+	FragmentPrefix string
+
+	// Rel, if set, determines the rel attribute of the <a> tag rendered
+	// for each wikilink from the wikilink and its resolved destination.
+	// Use this to mark links into user-contributed areas of a site
+	// as "nofollow ugc" while leaving the rest alone.
+	//
+	//	Renderer{
+	//		Rel: func(n *wikilink.Node, dest []byte) string {
+	//			if bytes.HasPrefix(n.Target, []byte("community/")) {
+	//				return "nofollow ugc"
+	//			}
+	//			return ""
+	//		},
+	//	}
+	//
+	// The attribute is omitted if Rel returns an empty string.
+	Rel func(n *Node, dest []byte) string
+
+	// DownloadExtensions is a list of file extensions, like ".pdf",
+	// that mark the targets of non-embedded wikilinks as downloadable
+	// files. Links to these files get a download attribute and a class
+	// naming the file type. Extensions are matched case-insensitively.
+	//
+	//	Renderer{DownloadExtensions: wikilink.DefaultDownloadExtensions}
+	//	// [[report.pdf]] => <a href="report.pdf" download class="wikilink-file-pdf">
+	//
+	// Links are rendered without these attributes if this is empty.
+	DownloadExtensions []string
+
+	// NormalizeDestinations specifies whether the paths of resolved
+	// destinations should be cleaned up before they're rendered.
+	// Duplicate slashes are collapsed, and "." and ".." segments are
+	// resolved. Use this with resolvers that build destinations by
+	// concatenation and may produce paths like "/root//Foo/".
+	//
+	//	/root//Foo/    // => /root/Foo/
+	//	./Foo.html#Bar // => Foo.html#Bar
+	//
+	// This does not apply to destinations with a scheme or authority,
+	// like "https://example.com//a", "obsidian://open?...",
+	// or "//cdn.example.com//a", even if a resolver produced them.
+	NormalizeDestinations bool
+
+	// RelativeDestinations specifies whether root-relative destinations,
+	// like "/docs/Foo/", should be rewritten relative to the page being
+	// rendered, like "../docs/Foo/".
+	// Use this for sites deployed to subdirectories
+	// or viewed from the file system.
+	//
+	// The page being rendered is found with PageURL from the name of the
+	// document set with SetContextSource.
+	// Destinations are left as-is for documents without a name.
+	// This does not apply to absolute URLs.
+	RelativeDestinations bool
+
+	// PageURL returns the URL path of the page rendered from the document
+	// with the given name, as set with SetContextSource.
+	// It's used by RelativeDestinations.
+	//
+	//	// Pretty URLs: "notes/Foo.md" is served at "/notes/Foo/".
+	//	PageURL: func(source string) string {
+	//		return "/" + strings.TrimSuffix(source, ".md") + "/"
+	//	},
+	//
+	// Defaults to the name with its extension changed to ".html",
+	// so "notes/Foo.md" is at "/notes/Foo.html".
+	PageURL func(source string) string
+
+	// RawUnicode specifies whether non-ASCII characters in destinations
+	// are written to the HTML as-is instead of being percent-encoded.
+	// Both forms are valid in HTML5, but servers and link checkers
+	// disagree about which they expect.
+	//
+	//	[[数据]] // => href="%E6%95%B0%E6%8D%AE.html" by default
+	//	[[数据]] // => href="数据.html" with RawUnicode
+	RawUnicode bool
+
+	// Report, if set, records every wikilink rendered by this Renderer
+	// and the outcome of resolving it.
+	Report *LinkReport
+
+	// Logger, if set, receives a record for every wikilink rendered by
+	// this Renderer. Unresolved, ambiguous, and invalid targets are
+	// logged at warn level, other resolver failures at error level,
+	// and successful resolutions at debug level.
+	Logger *slog.Logger
+
+	// Metrics, if set, counts the outcomes of every wikilink rendered
+	// by this Renderer.
+	Metrics *Metrics
+
+	// Assets, if set, collects the files, like images and PDFs,
+	// linked to or embedded by wikilinks rendered by this Renderer.
+	Assets *AssetSet
+
+	// AssetBaseURLs maps file extensions, like ".png", to base URLs,
+	// like that of a CDN, that are added to the start of the
+	// destinations of wikilinks to files with those extensions.
+	// Links to pages are left alone.
+	// Extensions are matched case-insensitively.
+	//
+	//	Renderer{
+	//		Resolver:      wikilink.NewRootResolver("/"),
+	//		AssetBaseURLs: map[string]string{".png": "https://cdn.example.com/"},
+	//	}
+	//	// ![[img/cat.png]] => <img src="https://cdn.example.com/img/cat.png">
+	//
+	// Destinations are treated as relative to the root of the site,
+	// so use this with resolvers that build root-relative destinations.
+	// This does not apply to absolute URLs or unresolved wikilinks.
+	// AssetSet records destinations without the base URL.
+	AssetBaseURLs map[string]string
+
+	// Fingerprint, if set, rewrites the destinations of wikilinks to
+	// files, like images and PDFs, for cache busting.
+	// It receives the resolved destination and returns the one to use,
+	// usually with a hash of the file's contents in the query or name.
+	//
+	//	[[logo.png]] // => <a href="logo.png?v=3a7bd3e2">
+	//
+	// Use FingerprintQuery for the common case.
+	// This runs before AssetBaseURLs is applied,
+	// so AssetSet records the fingerprinted names of renamed files.
+	// Rendering fails if Fingerprint returns an error.
+	Fingerprint func(n *Node, dest []byte) ([]byte, error)
+
+	// Observer, if set, is called with every wikilink rendered by this
+	// Renderer and the result of resolving it. Use it to collect
+	// analytics or assets, or to debug resolution, without wrapping
+	// every Resolver.
+	//
+	// dest is the final destination, after FragmentPrefix is applied.
+	// Observer must not modify n or dest.
+	Observer func(n *Node, dest []byte, err error)
+
+	// Trace specifies whether the steps taken to resolve each wikilink,
+	// like cache hits, translations, and fallbacks to other resolvers,
+	// should be recorded. Use it to find out why a link went where it did.
+	//
+	// Steps are logged with the "trace" attribute if Logger is set,
+	// and are available to the Observer from Node.Trace.
+	// Resolvers record steps with Node.Tracef.
+	Trace bool
+
 	once sync.Once // guards init
 
-	// hasDest records whether a node had a destination when we resolved
-	// it. This is needed to decide whether a closing </a> must be added
-	// when exiting a Node render.
-	hasDest sync.Map // *Node => struct{}
+	defaultEmbeds map[string]EmbedHandler // built-in embed handlers
 }
 
 func (r *Renderer) init() {
@@ -46,6 +342,13 @@ func (r *Renderer) init() {
 		if r.Resolver == nil {
 			r.Resolver = DefaultResolver
 		}
+		if r.URLResolver == nil {
+			r.URLResolver = urlResolver{}
+		}
+		if r.URLSchemes == nil {
+			r.URLSchemes = DefaultURLSchemes
+		}
+		r.defaultEmbeds = defaultEmbedHandlers(r)
 	})
 }
 
@@ -54,6 +357,20 @@ func (r *Renderer) init() {
 // wikilink in the AST.
 func (r *Renderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
 	reg.Register(Kind, r.Render)
+	reg.Register(KindEmbedBlock, r.renderEmbedBlock)
+}
+
+// renderEmbedBlock ends an EmbedBlock,
+// closing the <p> that its embed was written in, if any.
+func (r *Renderer) renderEmbedBlock(w util.BufWriter, _ []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		return ast.WalkContinue, nil
+	}
+	if b, ok := node.(*EmbedBlock); ok && b.paragraph {
+		_, _ = w.WriteString("</p>")
+	}
+	_ = w.WriteByte('\n')
+	return ast.WalkContinue, nil
 }
 
 // Render renders the provided Node. It must be a Wikilink [Node].
@@ -62,8 +379,9 @@ func (r *Renderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
 // using the WithNodeRenderers option.
 //
 // All nodes will be rendered as links (with <a> tags),
-// except for embed links (![[..]]) that refer to images.
-// Those will be rendered as images (with <img> tags).
+// except for embed links (![[..]]) that have an EmbedHandler.
+// By default, embeds of images, audio, video, and PDFs are rendered
+// with <img>, <audio>, <video>, and <object> tags respectively.
 func (r *Renderer) Render(w util.BufWriter, src []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
 	r.init()
 
@@ -81,59 +399,461 @@ func (r *Renderer) Render(w util.BufWriter, src []byte, node ast.Node, entering
 }
 
 func (r *Renderer) enter(w util.BufWriter, n *Node, src []byte) (ast.WalkStatus, error) {
-	dest, err := r.Resolver.ResolveWikilink(n)
+	resolver := r.Resolver
+	if n.resolver != nil {
+		resolver = n.resolver
+	}
+	isURL := hasURLScheme(n.Target, r.URLSchemes)
+	if isURL {
+		resolver = r.URLResolver
+	}
+	if r.Trace {
+		n.trace = new(resolveTrace)
+		switch {
+		case isURL:
+			n.Tracef("URL resolver")
+		case n.resolver != nil:
+			n.Tracef("resolver from SetContextResolver")
+		}
+	}
+
+	n.metrics = r.Metrics
+	res, err := ResolveDetails(resolver, n)
+	dest := res.Destination
+	if err == nil && !isURL && r.NormalizeDestinations && len(dest) > 0 {
+		dest = normalizeDestination(dest)
+	}
+	if err == nil && !isURL && len(r.FragmentPrefix) > 0 {
+		dest = prefixFragment(dest, r.FragmentPrefix)
+	}
+	status := linkStatus(dest, err)
+	if err == nil && res.Missing {
+		status = LinkMissing
+	}
+	placeholder := status == LinkMissing && len(dest) == 0 && len(r.MissingURL) > 0
+	if placeholder {
+		dest = expandMissingURL(r.MissingURL, n)
+		res.Missing = true
+	}
+	if status == LinkOK && !isURL && r.Fingerprint != nil && isAsset(n) {
+		fp, err := r.Fingerprint(n, dest)
+		if err != nil {
+			return ast.WalkStop, &RenderError{Op: "fingerprint", Node: n, Line: nodeLine(n, src), Err: err}
+		}
+		dest = fp
+	}
+	localDest := dest // before AssetBaseURLs and RelativeDestinations
+	if err == nil && !isURL {
+		dest = r.pageDest(n, status, dest)
+	}
+	if r.Report != nil {
+		r.report(n, src, dest, status)
+	}
+	if r.Logger != nil {
+		r.log(n, src, dest, err, status)
+	}
+	if r.Metrics != nil {
+		r.Metrics.add(status)
+	}
+	if r.Observer != nil {
+		r.Observer(n, dest, err)
+	}
 	if err != nil {
-		return ast.WalkStop, fmt.Errorf("resolve %q: %w", n.Target, err)
+		return ast.WalkStop, &RenderError{Op: "resolve", Node: n, Line: nodeLine(n, src), Err: err}
+	}
+
+	var h EmbedHandler
+	if n.Embed && !placeholder && !res.Private && len(dest) > 0 {
+		h = r.embedHandler(n)
+	}
+	if b, ok := n.Parent().(*EmbedBlock); ok {
+		// Embeds that aren't block-level HTML
+		// still need the paragraph they were lifted out of.
+		b.paragraph = !isBlockEmbed(h, src, n)
+		if b.paragraph {
+			_, _ = w.WriteString("<p>")
+		}
+	}
+
+	if res.Private {
+		if r.MarkPrivate {
+			n.closer = "</span>"
+			_, _ = w.WriteString(`<span class="wikilink-private">`)
+		}
+		return ast.WalkContinue, nil
+	}
+	if r.Assets != nil && !isURL && status == LinkOK && isAsset(n) {
+		r.Assets.add(n, localDest)
 	}
 	if len(dest) == 0 {
+		if r.MarkUnresolved {
+			n.closer = "</span>"
+			r.writeUnresolvedSpan(w)
+		}
+		if r.CommentUnresolved {
+			n.closer += missingComment(n)
+		}
 		return ast.WalkContinue, nil
 	}
 
-	img := resolveAsImage(n)
-	if !img {
-		r.hasDest.Store(n, struct{}{})
-		_, _ = w.WriteString(`<a href="`)
-		_, _ = w.Write(util.URLEscape(dest, true /* resolve references */))
-		_, _ = w.WriteString(`">`)
-		return ast.WalkContinue, nil
+	if h != nil {
+		// Handlers read files at the local destination
+		// and write URLs with EmbedURL.
+		if !isURL {
+			n.embedURL = func(dest []byte) []byte {
+				return r.pageDest(n, status, dest)
+			}
+			defer func() { n.embedURL = nil }()
+		}
+		if err := h.RenderEmbed(w, src, n, localDest); err != nil {
+			return ast.WalkStop, &RenderError{Op: "render embed", Node: n, Line: nodeLine(n, src), Err: err}
+		}
+		return ast.WalkSkipChildren, nil
+	}
+
+	n.closer = "</a>"
+	_, _ = w.WriteString(`<a href="`)
+	_, _ = w.Write(r.escapeURL(dest))
+	_ = w.WriteByte('"')
+	if r.Rel != nil {
+		if rel := r.Rel(n, dest); len(rel) > 0 {
+			writeAttr(w, "rel", rel)
+		}
+	}
+
+	var classes []string
+	if ext, ok := r.downloadExtension(n); ok {
+		_, _ = w.WriteString(` download`)
+		classes = append(classes, "wikilink-file-"+ext)
+	}
+	if n.Hashtag {
+		classes = append(classes, "wikilink-hashtag")
+	}
+	if res.Missing {
+		classes = append(classes, "wikilink-missing")
+	}
+	attrs := nodeAttrs(n)
+	if class := attrs["class"]; len(class) > 0 {
+		classes = append(classes, class)
+	}
+	if class := res.Attrs["class"]; len(class) > 0 {
+		classes = append(classes, class)
+	}
+	if len(classes) > 0 {
+		writeAttr(w, "class", strings.Join(classes, " "))
+	}
+	title := res.Title
+	if len(title) == 0 {
+		title = attrs["title"]
+	}
+	if len(title) > 0 {
+		writeAttr(w, "title", title)
+	}
+	if len(attrs["aria-label"]) == 0 && len(res.Attrs["aria-label"]) == 0 && isIconLink(n, src) {
+		writeAttr(w, "aria-label", r.iconLabel(n))
+	}
+	if attrs == nil {
+		attrs = res.Attrs
+	} else {
+		for name, value := range res.Attrs {
+			attrs[name] = value
+		}
+	}
+	writeResolutionAttrs(w, attrs)
+	_ = w.WriteByte('>')
+	return ast.WalkContinue, nil
+}
+
+// writeUnresolvedSpan opens the <span> that MarkUnresolved wraps
+// unresolved wikilinks in.
+func (r *Renderer) writeUnresolvedSpan(w util.BufWriter) {
+	if r.UnresolvedAttrs == nil {
+		_, _ = w.WriteString(`<span class="wikilink-unresolved" role="link" aria-disabled="true">`)
+		return
+	}
+
+	_, _ = w.WriteString(`<span`)
+	if class := r.UnresolvedAttrs["class"]; len(class) > 0 {
+		writeAttr(w, "class", class)
+	}
+	if title := r.UnresolvedAttrs["title"]; len(title) > 0 {
+		writeAttr(w, "title", title)
+	}
+	writeResolutionAttrs(w, r.UnresolvedAttrs)
+	_ = w.WriteByte('>')
+}
+
+// isIconLink reports whether the label of n has content but no text,
+// like an image without alt text.
+func isIconLink(n *Node, src []byte) bool {
+	return n.HasChildren() && len(bytes.TrimSpace(n.Text(src))) == 0
+}
+
+// iconLabel returns the aria-label for a wikilink whose label has no text.
+func (r *Renderer) iconLabel(n *Node) string {
+	if r.IconLabel != nil {
+		return r.IconLabel(n)
+	}
+	if len(n.Target) == 0 {
+		return string(n.Fragment)
+	}
+	return string(n.Target)
+}
+
+// pageDest rewrites the local destination of n into the destination
+// written to the page, applying AssetBaseURLs and RelativeDestinations.
+func (r *Renderer) pageDest(n *Node, status LinkStatus, dest []byte) []byte {
+	if status == LinkOK && len(r.AssetBaseURLs) > 0 {
+		if base, ok := r.assetBaseURL(n); ok {
+			dest = joinBaseURL(base, dest)
+		}
+	}
+	if r.RelativeDestinations && len(n.source) > 0 {
+		dest = relativeDestination(r.pageURL(n.source), dest)
+	}
+	return dest
+}
+
+// renderImage renders an image embed as an <img> tag.
+func (r *Renderer) renderImage(w util.BufWriter, src []byte, n *Node, dest []byte) error {
+	dest = n.EmbedURL(dest)
+
+	caption := imageCaption(src, n)
+	label := caption
+	if len(label) == 0 && r.ImageAltFromFilename {
+		name := filepath.Base(string(n.Target))
+		label = []byte(strings.TrimSuffix(name, filepath.Ext(name)))
+	}
+
+	figure := r.imageFigure(src, n)
+	if figure {
+		_, _ = w.WriteString(`<figure>`)
 	}
 
 	_, _ = w.WriteString(`<img src="`)
-	_, _ = w.Write(util.URLEscape(dest, true /* resolve references */))
+	_, _ = w.Write(r.escapeURL(dest))
+	if err := r.writeImageSet(w, n, dest); err != nil {
+		return err
+	}
+	if len(label) > 0 {
+		_, _ = w.WriteString(`" alt="`)
+		_, _ = w.Write(util.EscapeHTML(label))
+	}
+	if len(r.ImageLoading) > 0 {
+		_, _ = w.WriteString(`" loading="`)
+		_, _ = w.Write(util.EscapeHTML([]byte(r.ImageLoading)))
+	}
+	if len(r.ImageDecoding) > 0 {
+		_, _ = w.WriteString(`" decoding="`)
+		_, _ = w.Write(util.EscapeHTML([]byte(r.ImageDecoding)))
+	}
+	_ = w.WriteByte('"')
+	if attrs := nodeAttrs(n); len(attrs) > 0 {
+		if class := attrs["class"]; len(class) > 0 {
+			writeAttr(w, "class", class)
+		}
+		if title := attrs["title"]; len(title) > 0 {
+			writeAttr(w, "title", title)
+		}
+		writeResolutionAttrs(w, attrs)
+	}
+	_ = w.WriteByte('>')
+
+	if figure {
+		_, _ = w.WriteString(`<figcaption>`)
+		_, _ = w.Write(util.EscapeHTML(caption))
+		_, _ = w.WriteString(`</figcaption></figure>`)
+	}
+	return nil
+}
+
+// imageCaption returns the label written for an image embed, if any,
+// which is its alt text and the caption of its <figure>.
+func imageCaption(src []byte, n *Node) []byte {
 	// The label portion of the link becomes the alt text
 	// only if it isn't the same as the target.
 	// This way, [[foo.jpg]] does not become alt="foo.jpg",
 	// but [[foo.jpg|bar]] does become alt="bar".
 	if n.ChildCount() == 1 {
-		label := n.FirstChild().Text(src)
-		if !bytes.Equal(label, n.Target) {
-			_, _ = w.WriteString(`" alt="`)
-			_, _ = w.Write(util.EscapeHTML(label))
+		if l := labelText(src, n.FirstChild()); !bytes.Equal(l, n.Target) {
+			return l
 		}
 	}
-	_, _ = w.WriteString(`">`)
-	return ast.WalkSkipChildren, nil
+	return nil
 }
 
-func (r *Renderer) exit(w util.BufWriter, n *Node) {
-	if _, ok := r.hasDest.LoadAndDelete(n); ok {
-		_, _ = w.WriteString("</a>")
-	}
+// labelText returns the text of the label node n in src,
+// without the backslash escapes of the wikilink syntax,
+// like "\|", for use outside of the label's own text nodes,
+// like alt text.
+func labelText(src []byte, n ast.Node) []byte {
+	return unescape(n.Text(src), '#')
 }
 
-// returns true if the wikilink should be resolved to an image node
-func resolveAsImage(n *Node) bool {
-	if !n.Embed {
-		return false
-	}
+// imageFigure reports whether an image embed is rendered
+// as a <figure> with its label as the caption.
+// Labels taken from the file name with ImageAltFromFilename
+// are only used as alt text.
+func (r *Renderer) imageFigure(src []byte, n *Node) bool {
+	return r.ImageFigure && len(imageCaption(src, n)) > 0 && canHoldBlock(n)
+}
 
-	filename := string(n.Target)
-	switch ext := filepath.Ext(filename); ext {
-	// Common image file types taken from
-	// https://developer.mozilla.org/en-US/docs/Web/Media/Formats/Image_types
-	case ".apng", ".avif", ".gif", ".jpg", ".jpeg", ".jfif", ".pjpeg", ".pjp", ".png", ".svg", ".webp":
+// canHoldBlock reports whether the parent of n can hold the
+// block-level HTML of n in place of n:
+// whether n is on its own in an EmbedBlock or a tight list item,
+// or was rendered without a document.
+func canHoldBlock(n *Node) bool {
+	switch p := n.Parent().(type) {
+	case nil, *EmbedBlock:
 		return true
+	case *ast.TextBlock:
+		return p.ChildCount() == 1
 	default:
 		return false
 	}
 }
+
+// writeImageSet writes the srcset and sizes attributes for an image embed
+// if an ImageSetResolver is configured and has alternatives for it.
+// It expects to be called while inside an open attribute value.
+func (r *Renderer) writeImageSet(w util.BufWriter, n *Node, dest []byte) error {
+	if r.ImageSetResolver == nil {
+		return nil
+	}
+
+	set, err := r.ImageSetResolver.ResolveImageSet(n, dest)
+	if err != nil {
+		return fmt.Errorf("resolve image set: %w", err)
+	}
+	if set == nil || len(set.Sources) == 0 {
+		return nil
+	}
+
+	_, _ = w.WriteString(`" srcset="`)
+	for i, src := range set.Sources {
+		if i > 0 {
+			_, _ = w.WriteString(", ")
+		}
+		_, _ = w.Write(r.escapeURL(src.URL))
+		if len(src.Descriptor) > 0 {
+			_ = w.WriteByte(' ')
+			_, _ = w.Write(util.EscapeHTML([]byte(src.Descriptor)))
+		}
+	}
+	if len(set.Sizes) > 0 {
+		_, _ = w.WriteString(`" sizes="`)
+		_, _ = w.Write(util.EscapeHTML([]byte(set.Sizes)))
+	}
+	return nil
+}
+
+func (r *Renderer) report(n *Node, src, dest []byte, status LinkStatus) {
+	r.Report.add(LinkReportEntry{
+		Source:      n.source,
+		Line:        nodeLine(n, src),
+		Target:      string(n.Target),
+		Fragment:    string(n.Fragment),
+		Destination: string(dest),
+		Status:      status,
+		Type:        string(n.LinkType),
+	})
+}
+
+// escapeURL escapes a destination for use in an HTML attribute,
+// percent-encoding non-ASCII characters unless RawUnicode is set.
+func (r *Renderer) escapeURL(dest []byte) []byte {
+	if !r.RawUnicode {
+		return util.URLEscape(dest, true /* resolve references */)
+	}
+
+	// Escape runs of ASCII characters and copy everything else as-is.
+	out := make([]byte, 0, len(dest))
+	for len(dest) > 0 {
+		i := 0
+		for i < len(dest) && dest[i] < utf8.RuneSelf {
+			i++
+		}
+		out = append(out, util.URLEscape(dest[:i], true /* resolve references */)...)
+		dest = dest[i:]
+
+		i = 0
+		for i < len(dest) && dest[i] >= utf8.RuneSelf {
+			i++
+		}
+		out = append(out, dest[:i]...)
+		dest = dest[i:]
+	}
+	return out
+}
+
+// prefixFragment adds prefix to the start of the fragment in dest, if any.
+func prefixFragment(dest []byte, prefix string) []byte {
+	idx := bytes.IndexByte(dest, '#')
+	if idx < 0 {
+		return dest
+	}
+
+	out := make([]byte, 0, len(dest)+len(prefix))
+	out = append(out, dest[:idx+1]...)
+	out = append(out, prefix...)
+	out = append(out, dest[idx+1:]...)
+	return out
+}
+
+// nodeLine reports the 1-indexed line in src on which the provided node
+// starts, or 0 if it's unknown.
+func nodeLine(n *Node, src []byte) int {
+	start := n.segment.Start
+	if n.segment.IsEmpty() {
+		// Not parsed by Parser: fall back to the label, if any.
+		t, ok := n.FirstChild().(*ast.Text)
+		if !ok {
+			return 0
+		}
+		start = t.Segment.Start
+	}
+	if start > len(src) {
+		return 0
+	}
+	return bytes.Count(src[:start], []byte{'\n'}) + 1
+}
+
+// _missingURLPlaceholders matches the placeholders in MissingURL.
+var _missingURLPlaceholders = regexp.MustCompile(`\{(target|fragment|source)\}`)
+
+// expandMissingURL replaces the placeholders in tmpl
+// with the query-escaped values for n.
+func expandMissingURL(tmpl string, n *Node) []byte {
+	return []byte(_missingURLPlaceholders.ReplaceAllStringFunc(tmpl, func(p string) string {
+		var v string
+		switch p {
+		case "{target}":
+			v = string(n.Target)
+		case "{fragment}":
+			v = string(n.Fragment)
+		case "{source}":
+			v = n.source
+		}
+		return url.QueryEscape(v)
+	}))
+}
+
+// missingComment returns the HTML comment that marks n as unresolved
+// for CommentUnresolved.
+func missingComment(n *Node) string {
+	target := string(n.Target)
+	if len(n.Fragment) > 0 {
+		target += "#" + string(n.Fragment)
+	}
+	for strings.Contains(target, "--") {
+		target = strings.ReplaceAll(target, "--", "- -")
+	}
+	return "<!-- wikilink-missing: " + target + " -->"
+}
+
+func (r *Renderer) exit(w util.BufWriter, n *Node) {
+	if len(n.closer) > 0 {
+		_, _ = w.WriteString(n.closer)
+		n.closer = ""
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"bytes"
 	"errors"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
 )
 
 func TestRenderer(t *testing.T) {
@@ -67,13 +70,13 @@ func TestRenderer(t *testing.T) {
 				wantExiting:  `</a>`,
 			},
 			{
-				desc: "pdf embed", // unsupported at this time
+				desc: "pdf embed",
 				give: &Node{
 					Target: []byte("foo.pdf"),
 					Embed:  true,
 				},
-				wantEntering: `<a href="foo.pdf">`,
-				wantExiting:  `</a>`,
+				wantEntering: `<object type="application/pdf" data="foo.pdf"><a href="foo.pdf"></a></object>`,
+				wantExiting:  ``,
 			},
 			{
 				desc: "page fragment",
@@ -174,6 +177,648 @@ func TestRenderer(t *testing.T) {
 	})
 }
 
+func TestRenderer_ImageSet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give *ImageSet
+		want string
+	}{
+		{
+			desc: "nil set",
+			want: `<img src="foo.png">`,
+		},
+		{
+			desc: "empty set",
+			give: &ImageSet{Sizes: "50vw"},
+			want: `<img src="foo.png">`,
+		},
+		{
+			desc: "descriptors",
+			give: &ImageSet{
+				Sources: []ImageSource{
+					{URL: []byte("foo 480.png"), Descriptor: "480w"},
+					{URL: []byte("foo-960.png"), Descriptor: "960w"},
+				},
+				Sizes: "(max-width: 600px) 480px, 960px",
+			},
+			want: `<img src="foo.png" srcset="foo%20480.png 480w, foo-960.png 960w" sizes="(max-width: 600px) 480px, 960px">`,
+		},
+		{
+			desc: "no descriptors or sizes",
+			give: &ImageSet{
+				Sources: []ImageSource{{URL: []byte("foo@2x.png")}},
+			},
+			want: `<img src="foo.png" srcset="foo@2x.png">`,
+		},
+		{
+			desc: "single source",
+			give: &ImageSet{
+				Sources: []ImageSource{{URL: []byte("foo@2x.png"), Descriptor: "2x"}},
+			},
+			want: `<img src="foo.png" srcset="foo@2x.png 2x">`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buff bytes.Buffer
+			w := bufio.NewWriter(&buff)
+
+			r := Renderer{
+				ImageSetResolver: imageSetResolverFunc(func(n *Node, dest []byte) (*ImageSet, error) {
+					assert.Equal(t, "foo.png", string(dest), "destination mismatch")
+					return tt.give, nil
+				}),
+			}
+
+			n := &Node{Target: []byte("foo.png"), Embed: true}
+			_, err := r.Render(w, nil /* source */, n, true /* entering */)
+			require.NoError(t, err, "should not fail")
+			require.NoError(t, w.Flush(), "flush")
+
+			assert.Equal(t, tt.want, buff.String(), "output mismatch")
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		r := Renderer{
+			ImageSetResolver: imageSetResolverFunc(func(*Node, []byte) (*ImageSet, error) {
+				return nil, errors.New("great sadness")
+			}),
+		}
+		_, err := r.Render(
+			bufio.NewWriter(io.Discard),
+			nil, // source
+			&Node{Target: []byte("foo.png"), Embed: true},
+			true, // entering
+		)
+		require.Error(t, err, "render must fail")
+		assert.Contains(t, err.Error(), "great sadness")
+	})
+}
+
+type imageSetResolverFunc func(*Node, []byte) (*ImageSet, error)
+
+func (f imageSetResolverFunc) ResolveImageSet(n *Node, dest []byte) (*ImageSet, error) {
+	return f(n, dest)
+}
+
+func TestRenderer_ImageLoading(t *testing.T) {
+	t.Parallel()
+
+	var buff bytes.Buffer
+	w := bufio.NewWriter(&buff)
+
+	src := []byte("foo")
+	n := &Node{Target: []byte("foo.png"), Embed: true}
+	n.AppendChild(n, ast.NewTextSegment(text.NewSegment(0, len(src))))
+
+	r := Renderer{
+		ImageLoading:  "lazy",
+		ImageDecoding: "async",
+	}
+	_, err := r.Render(w, src, n, true /* entering */)
+	require.NoError(t, err, "should not fail")
+	require.NoError(t, w.Flush(), "flush")
+
+	assert.Equal(t, `<img src="foo.png" alt="foo" loading="lazy" decoding="async">`, buff.String(),
+		"output mismatch")
+}
+
+func TestRenderer_ImageFigure(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc            string
+		target          string
+		label           string
+		altFromFilename bool
+		want            string
+	}{
+		{
+			desc:   "label",
+			target: "chart.png",
+			label:  "Quarterly <revenue>",
+			want:   `<figure><img src="chart.png" alt="Quarterly &lt;revenue&gt;"><figcaption>Quarterly &lt;revenue&gt;</figcaption></figure>`,
+		},
+		{
+			desc:   "no label",
+			target: "chart.png",
+			label:  "chart.png",
+			want:   `<img src="chart.png">`,
+		},
+		{
+			desc:            "alt from filename",
+			target:          "chart.png",
+			label:           "chart.png",
+			altFromFilename: true,
+			want:            `<img src="chart.png" alt="chart">`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buff bytes.Buffer
+			w := bufio.NewWriter(&buff)
+
+			src := []byte(tt.label)
+			n := &Node{Target: []byte(tt.target), Embed: true}
+			n.AppendChild(n, ast.NewTextSegment(text.NewSegment(0, len(src))))
+
+			r := Renderer{ImageFigure: true, ImageAltFromFilename: tt.altFromFilename}
+			_, err := r.Render(w, src, n, true /* entering */)
+			require.NoError(t, err, "should not fail")
+			require.NoError(t, w.Flush(), "flush")
+
+			assert.Equal(t, tt.want, buff.String(), "output mismatch")
+		})
+	}
+}
+
+func TestRenderer_ImageLabelEscapes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give string
+		want string
+	}{
+		{
+			desc: "pipe",
+			give: `![[x.png|cap\|tion]]`,
+			want: `<figure><img src="x.png" alt="cap|tion"><figcaption>cap|tion</figcaption></figure>`,
+		},
+		{
+			desc: "brackets",
+			give: `![[x.png|\[draft\] chart]]`,
+			want: `<figure><img src="x.png" alt="[draft] chart"><figcaption>[draft] chart</figcaption></figure>`,
+		},
+		{
+			desc: "escaped target",
+			give: `![[C\# logo.png]]`,
+			want: `<p><img src="C%23%20logo.png"></p>`,
+		},
+		{
+			desc: "media",
+			give: `![[song.mp3|A\|B]]`,
+			want: `<p><audio controls src="song.mp3"><a href="song.mp3">A|B</a></audio></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			md := goldmark.New(goldmark.WithExtensions(&Extender{ImageFigure: true}))
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want, strings.TrimSpace(buf.String()))
+		})
+	}
+}
+
+func TestRenderer_ImageAltFromFilename(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc   string
+		target string
+		label  string
+		want   string
+	}{
+		{
+			desc:   "no label",
+			target: "photos/red-panda.png",
+			label:  "photos/red-panda.png",
+			want:   `<img src="photos/red-panda.png" alt="red-panda">`,
+		},
+		{
+			desc:   "label",
+			target: "photos/red-panda.png",
+			label:  "A red panda",
+			want:   `<img src="photos/red-panda.png" alt="A red panda">`,
+		},
+		{
+			desc:   "escaped",
+			target: "a&b.png",
+			label:  "a&b.png",
+			want:   `<img src="a&b.png" alt="a&amp;b">`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buff bytes.Buffer
+			w := bufio.NewWriter(&buff)
+
+			src := []byte(tt.label)
+			n := &Node{Target: []byte(tt.target), Embed: true}
+			n.AppendChild(n, ast.NewTextSegment(text.NewSegment(0, len(src))))
+
+			r := Renderer{ImageAltFromFilename: true}
+			_, err := r.Render(w, src, n, true /* entering */)
+			require.NoError(t, err, "should not fail")
+			require.NoError(t, w.Flush(), "flush")
+
+			assert.Equal(t, tt.want, buff.String(), "output mismatch")
+		})
+	}
+}
+
+func TestRenderer_MarkUnresolved(t *testing.T) {
+	t.Parallel()
+
+	var buff bytes.Buffer
+	w := bufio.NewWriter(&buff)
+
+	r := Renderer{
+		Resolver:       resolverFunc(noopResolver),
+		MarkUnresolved: true,
+	}
+	n := &Node{Target: []byte("foo")}
+	_, err := r.Render(w, nil /* source */, n, true /* entering */)
+	require.NoError(t, err, "entering")
+	_, err = r.Render(w, nil /* source */, n, false /* entering */)
+	require.NoError(t, err, "exiting")
+	require.NoError(t, w.Flush(), "flush")
+
+	assert.Equal(t,
+		`<span class="wikilink-unresolved" role="link" aria-disabled="true"></span>`,
+		buff.String())
+}
+
+func TestRenderer_UnresolvedAttrs(t *testing.T) {
+	t.Parallel()
+
+	var buff bytes.Buffer
+	w := bufio.NewWriter(&buff)
+
+	r := Renderer{
+		Resolver:       resolverFunc(noopResolver),
+		MarkUnresolved: true,
+		UnresolvedAttrs: map[string]string{
+			"class":         "broken",
+			"title":         "Not <written> yet",
+			"aria-disabled": "true",
+		},
+	}
+	n := &Node{Target: []byte("foo")}
+	_, err := r.Render(w, nil /* source */, n, true /* entering */)
+	require.NoError(t, err, "entering")
+	_, err = r.Render(w, nil /* source */, n, false /* entering */)
+	require.NoError(t, err, "exiting")
+	require.NoError(t, w.Flush(), "flush")
+
+	assert.Equal(t,
+		`<span class="broken" title="Not &lt;written&gt; yet" aria-disabled="true"></span>`,
+		buff.String())
+}
+
+func TestRenderer_IconLabel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc  string
+		give  string
+		label func(*Node) string
+		want  string
+	}{
+		{
+			desc: "icon",
+			give: "[[Home|![](home.svg)]]",
+			want: `<p><a href="Home.html" aria-label="Home"><img src="home.svg" alt=""></a></p>`,
+		},
+		{
+			desc: "heading",
+			give: "[[#Top|![](up.svg)]]",
+			want: `<p><a href="#Top" aria-label="Top"><img src="up.svg" alt=""></a></p>`,
+		},
+		{
+			desc: "alt text",
+			give: "[[Home|![home](home.svg)]]",
+			want: `<p><a href="Home.html"><img src="home.svg" alt="home"></a></p>`,
+		},
+		{
+			desc: "text",
+			give: "[[Home|go home]]",
+			want: `<p><a href="Home.html">go home</a></p>`,
+		},
+		{
+			desc:  "custom",
+			give:  "[[Home|![](home.svg)]]",
+			label: func(n *Node) string { return "Go to " + string(n.Target) },
+			want:  `<p><a href="Home.html" aria-label="Go to Home"><img src="home.svg" alt=""></a></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			md := goldmark.New(goldmark.WithExtensions(&Extender{
+				MarkdownLabels: true,
+				IconLabel:      tt.label,
+			}))
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want, strings.TrimSpace(buf.String()))
+		})
+	}
+}
+
+func TestRenderer_CommentUnresolved(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		mark bool
+		give *Node
+		want string
+	}{
+		{
+			desc: "target",
+			give: &Node{Target: []byte("foo")},
+			want: `<!-- wikilink-missing: foo -->`,
+		},
+		{
+			desc: "fragment",
+			give: &Node{Target: []byte("foo"), Fragment: []byte("bar")},
+			want: `<!-- wikilink-missing: foo#bar -->`,
+		},
+		{
+			desc: "dashes",
+			give: &Node{Target: []byte("a-->b---c")},
+			want: `<!-- wikilink-missing: a- ->b- - -c -->`,
+		},
+		{
+			desc: "marked",
+			mark: true,
+			give: &Node{Target: []byte("foo")},
+			want: `<span class="wikilink-unresolved" role="link" aria-disabled="true"></span><!-- wikilink-missing: foo -->`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buff bytes.Buffer
+			w := bufio.NewWriter(&buff)
+
+			r := Renderer{
+				Resolver:          resolverFunc(noopResolver),
+				MarkUnresolved:    tt.mark,
+				CommentUnresolved: true,
+			}
+			_, err := r.Render(w, nil /* source */, tt.give, true /* entering */)
+			require.NoError(t, err, "entering")
+			_, err = r.Render(w, nil /* source */, tt.give, false /* entering */)
+			require.NoError(t, err, "exiting")
+			require.NoError(t, w.Flush(), "flush")
+
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+func TestRenderer_MissingURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		resolver Resolver
+		give     *Node
+		want     string
+	}{
+		{
+			desc: "placeholders",
+			give: &Node{Target: []byte("Foo Bar"), Fragment: []byte("a&b"), source: "notes/a.md"},
+			want: `<a href="/404?from=notes%2Fa.md&target=Foo+Bar&fragment=a%26b" class="wikilink-missing"></a>`,
+		},
+		{
+			desc: "embed",
+			give: &Node{Target: []byte("foo.png"), Embed: true},
+			want: `<a href="/404?from=&target=foo.png&fragment=" class="wikilink-missing"></a>`,
+		},
+		{
+			desc: "resolved",
+			resolver: resolverFunc(func(n *Node) ([]byte, error) {
+				return []byte("foo.html"), nil
+			}),
+			give: &Node{Target: []byte("foo")},
+			want: `<a href="foo.html"></a>`,
+		},
+		{
+			desc: "missing with destination",
+			resolver: detailedResolverFunc(func(n *Node) (Resolution, error) {
+				return Resolution{Destination: []byte("new?title=foo"), Missing: true}, nil
+			}),
+			give: &Node{Target: []byte("foo")},
+			want: `<a href="new?title=foo" class="wikilink-missing"></a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			resolver := tt.resolver
+			if resolver == nil {
+				resolver = resolverFunc(noopResolver)
+			}
+
+			var buff bytes.Buffer
+			w := bufio.NewWriter(&buff)
+
+			r := Renderer{
+				Resolver:          resolver,
+				MissingURL:        "/404?from={source}&target={target}&fragment={fragment}",
+				MarkUnresolved:    true,
+				CommentUnresolved: true,
+			}
+			_, err := r.Render(w, nil /* source */, tt.give, true /* entering */)
+			require.NoError(t, err, "entering")
+			_, err = r.Render(w, nil /* source */, tt.give, false /* entering */)
+			require.NoError(t, err, "exiting")
+			require.NoError(t, w.Flush(), "flush")
+
+			assert.Equal(t, tt.want, buff.String())
+		})
+	}
+}
+
+func TestRenderer_FragmentPrefix(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give *Node
+		want string
+	}{
+		{
+			desc: "fragment",
+			give: &Node{Target: []byte("foo"), Fragment: []byte("bar")},
+			want: `<a href="foo.html#user-content-bar">`,
+		},
+		{
+			desc: "fragment only",
+			give: &Node{Fragment: []byte("bar")},
+			want: `<a href="#user-content-bar">`,
+		},
+		{
+			desc: "no fragment",
+			give: &Node{Target: []byte("foo")},
+			want: `<a href="foo.html">`,
+		},
+		{
+			desc: "url",
+			give: &Node{Target: []byte("https://example.com/"), Fragment: []byte("bar")},
+			want: `<a href="https://example.com/#bar">`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buff bytes.Buffer
+			w := bufio.NewWriter(&buff)
+
+			r := Renderer{FragmentPrefix: "user-content-"}
+			_, err := r.Render(w, nil /* source */, tt.give, true /* entering */)
+			require.NoError(t, err, "should not fail")
+			require.NoError(t, w.Flush(), "flush")
+
+			assert.Equal(t, tt.want, buff.String(), "output mismatch")
+		})
+	}
+}
+
+func TestRenderer_Rel(t *testing.T) {
+	t.Parallel()
+
+	rel := func(n *Node, dest []byte) string {
+		switch {
+		case bytes.HasPrefix(n.Target, []byte("community/")):
+			return "nofollow ugc"
+		case bytes.HasPrefix(dest, []byte("https:")):
+			return "external"
+		default:
+			return ""
+		}
+	}
+
+	tests := []struct {
+		desc string
+		give *Node
+		want string
+	}{
+		{
+			desc: "no rel",
+			give: &Node{Target: []byte("docs/intro")},
+			want: `<a href="docs/intro.html">`,
+		},
+		{
+			desc: "namespace",
+			give: &Node{Target: []byte("community/faq")},
+			want: `<a href="community/faq.html" rel="nofollow ugc">`,
+		},
+		{
+			desc: "destination",
+			give: &Node{Target: []byte("https://example.com")},
+			want: `<a href="https://example.com" rel="external">`,
+		},
+		{
+			desc: "image",
+			give: &Node{Target: []byte("community/cat.png"), Embed: true},
+			want: `<img src="community/cat.png">`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buff bytes.Buffer
+			w := bufio.NewWriter(&buff)
+
+			r := Renderer{Rel: rel}
+			_, err := r.Render(w, nil /* source */, tt.give, true /* entering */)
+			require.NoError(t, err, "should not fail")
+			require.NoError(t, w.Flush(), "flush")
+
+			assert.Equal(t, tt.want, buff.String(), "output mismatch")
+		})
+	}
+}
+
+func TestRenderer_RawUnicode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		raw  bool
+		give *Node
+		want string
+	}{
+		{
+			desc: "encoded",
+			give: &Node{Target: []byte("数据 库"), Fragment: []byte("é")},
+			want: `<a href="%E6%95%B0%E6%8D%AE%20%E5%BA%93.html#%C3%A9">`,
+		},
+		{
+			desc: "raw",
+			raw:  true,
+			give: &Node{Target: []byte("数据 库"), Fragment: []byte("é")},
+			want: `<a href="数据%20库.html#é">`,
+		},
+		{
+			desc: "raw image",
+			raw:  true,
+			give: &Node{Target: []byte("猫 狗.png"), Embed: true},
+			want: `<img src="猫%20狗.png">`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buff bytes.Buffer
+			w := bufio.NewWriter(&buff)
+
+			r := Renderer{RawUnicode: tt.raw}
+			_, err := r.Render(w, nil /* source */, tt.give, true /* entering */)
+			require.NoError(t, err, "should not fail")
+			require.NoError(t, w.Flush(), "flush")
+
+			assert.Equal(t, tt.want, buff.String(), "output mismatch")
+		})
+	}
+}
+
 func TestRenderer_IncorrectNode(t *testing.T) {
 	t.Parallel()
 
@@ -201,6 +846,42 @@ func TestRenderer_ResolveError(t *testing.T) {
 	assert.Contains(t, err.Error(), "great sadness")
 }
 
+func TestRenderer_Observer(t *testing.T) {
+	t.Parallel()
+
+	type call struct {
+		target string
+		dest   string
+		err    error
+	}
+
+	sadness := errors.New("great sadness")
+	var calls []call
+	r := Renderer{
+		Resolver: resolverFunc(func(n *Node) ([]byte, error) {
+			if string(n.Target) == "bad" {
+				return nil, sadness
+			}
+			return DefaultResolver.ResolveWikilink(n)
+		}),
+		FragmentPrefix: "h-",
+		Observer: func(n *Node, dest []byte, err error) {
+			calls = append(calls, call{string(n.Target), string(dest), err})
+		},
+	}
+
+	w := bufio.NewWriter(io.Discard)
+	_, err := r.Render(w, nil /* src */, &Node{Target: []byte("foo"), Fragment: []byte("bar")}, true /* entering */)
+	require.NoError(t, err)
+	_, err = r.Render(w, nil /* src */, &Node{Target: []byte("bad")}, true /* entering */)
+	require.Error(t, err)
+
+	assert.Equal(t, []call{
+		{target: "foo", dest: "foo.html#h-bar"},
+		{target: "bad", err: sadness},
+	}, calls)
+}
+
 func noopResolver(*Node) ([]byte, error) {
 	return nil, nil
 }
@@ -0,0 +1,197 @@
+package wikilink
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LinkStatus is the outcome of resolving a wikilink.
+type LinkStatus string
+
+const (
+	// LinkOK indicates that the wikilink resolved to a destination.
+	LinkOK LinkStatus = "ok"
+
+	// LinkMissing indicates that the resolver did not return a
+	// destination for the wikilink,
+	// or that it failed with an ErrTargetNotFound error.
+	LinkMissing LinkStatus = "missing"
+
+	// LinkAmbiguous indicates that the resolver failed with an
+	// ErrAmbiguousTarget error.
+	LinkAmbiguous LinkStatus = "ambiguous"
+
+	// LinkInvalid indicates that the resolver failed with an
+	// ErrInvalidTarget error.
+	LinkInvalid LinkStatus = "invalid"
+
+	// LinkError indicates that the resolver failed with any other error.
+	LinkError LinkStatus = "error"
+)
+
+// LinkReport records every wikilink rendered by a Renderer.
+// Use it to produce a report of all links in a document set
+// after converting it.
+//
+//	report := new(wikilink.LinkReport)
+//	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+//		Report: report,
+//	}))
+//	for _, doc := range docs {
+//		ctx := parser.NewContext()
+//		wikilink.SetContextSource(ctx, doc.Path)
+//		md.Convert(doc.Body, &buf, parser.WithContext(ctx))
+//	}
+//	report.WriteJSON(f)
+//
+// A LinkReport is safe for concurrent use.
+// The zero value is an empty report ready to use.
+type LinkReport struct {
+	mu      sync.Mutex
+	entries []LinkReportEntry
+}
+
+// LinkReportEntry is a single wikilink recorded in a LinkReport.
+type LinkReportEntry struct {
+	// Source is the name of the document containing the wikilink
+	// as set with SetContextSource.
+	Source string `json:"source"`
+
+	// Line is the 1-indexed line number of the wikilink in the source,
+	// or 0 if it is not known.
+	Line int `json:"line"`
+
+	// Target and Fragment of the wikilink.
+	Target   string `json:"target"`
+	Fragment string `json:"fragment,omitempty"`
+
+	// Destination is the resolved destination of the wikilink, if any.
+	Destination string `json:"destination,omitempty"`
+
+	// Status is the outcome of resolving the wikilink.
+	Status LinkStatus `json:"status"`
+
+	// Type is the type of a typed link, like "supports:: [[Claim]]",
+	// if any. Use it to label the edges of a graph of the links.
+	Type string `json:"type,omitempty"`
+}
+
+func (r *LinkReport) add(e LinkReportEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, e)
+}
+
+// Entries returns a copy of the entries recorded so far.
+//
+// Entries are sorted by source, then line, then target
+// so that reports are reproducible across builds
+// even if documents are rendered concurrently.
+// Entries that are otherwise equal are kept in the order they were rendered.
+func (r *LinkReport) Entries() []LinkReportEntry {
+	r.mu.Lock()
+	entries := append([]LinkReportEntry(nil), r.entries...)
+	r.mu.Unlock()
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		ei, ej := entries[i], entries[j]
+		if ei.Source != ej.Source {
+			return ei.Source < ej.Source
+		}
+		if ei.Line != ej.Line {
+			return ei.Line < ej.Line
+		}
+		return ei.Target < ej.Target
+	})
+	return entries
+}
+
+// Backlinks returns the entries for the resolved wikilinks
+// to the file with the given ID in idx, like "notes/Foo",
+// sorted like Entries.
+//
+// Wikilinks are matched to files by their targets the way IndexResolver
+// does, so render the documents with an IndexResolver for idx,
+// and set their sources with SetContextSource to their paths in the vault,
+// like "notes/Bar.md".
+// Links from the file to itself, like [[#Intro]], are left out.
+func (r *LinkReport) Backlinks(idx *Index, id string) []LinkReportEntry {
+	var backlinks []LinkReportEntry
+	for _, e := range r.Entries() {
+		if e.Status != LinkOK || len(e.Target) == 0 || sourceID(e.Source) == id {
+			continue
+		}
+		if ids := lookupTarget(idx, e.Target); len(ids) == 1 && ids[0] == id {
+			backlinks = append(backlinks, e)
+		}
+	}
+	return backlinks
+}
+
+// sourceID returns the ID in an Index of the file
+// with the given source, like "notes/Foo" for "notes/Foo.md".
+func sourceID(source string) string {
+	source = strings.TrimPrefix(path.Clean("/"+source), "/")
+	if strings.EqualFold(path.Ext(source), ".md") {
+		source = source[:len(source)-len(".md")]
+	}
+	return source
+}
+
+// WriteJSON writes the report to w as a JSON array of entries.
+func (r *LinkReport) WriteJSON(w io.Writer) error {
+	entries := r.Entries()
+	if entries == nil {
+		entries = []LinkReportEntry{} // [] instead of null
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// WriteCSV writes the report to w as CSV with a header row.
+func (r *LinkReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"source", "line", "target", "fragment", "destination", "status", "type"})
+	for _, e := range r.Entries() {
+		_ = cw.Write([]string{
+			e.Source,
+			strconv.Itoa(e.Line),
+			e.Target,
+			e.Fragment,
+			e.Destination,
+			string(e.Status),
+			e.Type,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// linkStatus reports the status of a wikilink
+// given the result of resolving it.
+func linkStatus(dest []byte, err error) LinkStatus {
+	switch {
+	case errors.Is(err, ErrTargetNotFound):
+		return LinkMissing
+	case errors.Is(err, ErrAmbiguousTarget):
+		return LinkAmbiguous
+	case errors.Is(err, ErrInvalidTarget):
+		return LinkInvalid
+	case err != nil:
+		return LinkError
+	case len(dest) == 0:
+		return LinkMissing
+	default:
+		return LinkOK
+	}
+}
@@ -0,0 +1,188 @@
+package wikilink_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	wikilink "github.com/kentxxq/goldmark-wikilink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/parser"
+)
+
+func TestLinkReport(t *testing.T) {
+	t.Parallel()
+
+	report := new(wikilink.LinkReport)
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		Resolver: _resolver,
+		Report:   report,
+	}))
+
+	ctx := parser.NewContext()
+	wikilink.SetContextSource(ctx, "notes/index.md")
+	src := "# Index\n\nSee [[Foo#Bar]] and\n[[Does Not Exist|this]].\n\n- supports:: [[Claim]]\n"
+	require.NoError(t, md.Convert([]byte(src), io.Discard, parser.WithContext(ctx)))
+
+	assert.Equal(t, []wikilink.LinkReportEntry{
+		{
+			Source:      "notes/index.md",
+			Line:        3,
+			Target:      "Foo",
+			Fragment:    "Bar",
+			Destination: "Foo.html#Bar",
+			Status:      wikilink.LinkOK,
+		},
+		{
+			Source: "notes/index.md",
+			Line:   4,
+			Target: "Does Not Exist",
+			Status: wikilink.LinkMissing,
+		},
+		{
+			Source:      "notes/index.md",
+			Line:        6,
+			Target:      "Claim",
+			Destination: "Claim.html",
+			Status:      wikilink.LinkOK,
+			Type:        "supports",
+		},
+	}, report.Entries())
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, report.WriteJSON(&buf))
+		assert.JSONEq(t, `[
+			{
+				"source": "notes/index.md",
+				"line": 3,
+				"target": "Foo",
+				"fragment": "Bar",
+				"destination": "Foo.html#Bar",
+				"status": "ok"
+			},
+			{
+				"source": "notes/index.md",
+				"line": 4,
+				"target": "Does Not Exist",
+				"status": "missing"
+			},
+			{
+				"source": "notes/index.md",
+				"line": 6,
+				"target": "Claim",
+				"destination": "Claim.html",
+				"status": "ok",
+				"type": "supports"
+			}
+		]`, buf.String())
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, report.WriteCSV(&buf))
+		assert.Equal(t,
+			"source,line,target,fragment,destination,status,type\n"+
+				"notes/index.md,3,Foo,Bar,Foo.html#Bar,ok,\n"+
+				"notes/index.md,4,Does Not Exist,,,missing,\n"+
+				"notes/index.md,6,Claim,,Claim.html,ok,supports\n",
+			buf.String())
+	})
+}
+
+func TestLinkReport_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc string
+		give func(*wikilink.Node) error
+		want wikilink.LinkStatus
+	}{
+		{
+			desc: "error",
+			give: func(*wikilink.Node) error { return errors.New("great sadness") },
+			want: wikilink.LinkError,
+		},
+		{
+			desc: "not found",
+			give: func(n *wikilink.Node) error { return &wikilink.TargetNotFoundError{Node: n} },
+			want: wikilink.LinkMissing,
+		},
+		{
+			desc: "ambiguous",
+			give: func(n *wikilink.Node) error { return &wikilink.AmbiguousTargetError{Node: n} },
+			want: wikilink.LinkAmbiguous,
+		},
+		{
+			desc: "invalid",
+			give: func(n *wikilink.Node) error { return &wikilink.InvalidTargetError{Node: n} },
+			want: wikilink.LinkInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			report := new(wikilink.LinkReport)
+			md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+				Resolver: resolverFunc(func(n *wikilink.Node) ([]byte, error) {
+					return nil, tt.give(n)
+				}),
+				Report: report,
+			}))
+
+			require.Error(t, md.Convert([]byte("[[Foo]]"), io.Discard))
+			assert.Equal(t, []wikilink.LinkReportEntry{
+				{Line: 1, Target: "Foo", Status: tt.want},
+			}, report.Entries())
+		})
+	}
+}
+
+func TestLinkReport_Empty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, new(wikilink.LinkReport).WriteJSON(&buf))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
+func TestLinkReport_Order(t *testing.T) {
+	t.Parallel()
+
+	report := new(wikilink.LinkReport)
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		Report: report,
+	}))
+
+	docs := []struct{ source, body string }{
+		{"b.md", "[[Zed]] [[Alpha]]\n[[Beta]]"},
+		{"a.md", "[[Foo]]"},
+	}
+	for _, doc := range docs {
+		ctx := parser.NewContext()
+		wikilink.SetContextSource(ctx, doc.source)
+		require.NoError(t, md.Convert([]byte(doc.body), io.Discard, parser.WithContext(ctx)))
+	}
+
+	var got []string
+	for _, e := range report.Entries() {
+		got = append(got, fmt.Sprintf("%v:%v:%v", e.Source, e.Line, e.Target))
+	}
+	assert.Equal(t, []string{
+		"a.md:1:Foo",
+		"b.md:1:Alpha",
+		"b.md:1:Zed",
+		"b.md:2:Beta",
+	}, got)
+}
@@ -0,0 +1,160 @@
+package wikilink
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/util"
+)
+
+// Resolution is the result of resolving a wikilink
+// with a DetailedResolver.
+//
+// It lets resolvers tell the Renderer more about a wikilink
+// than its destination.
+// New fields may be added over time;
+// resolvers that don't set them keep working as before.
+type Resolution struct {
+	// Destination of the wikilink.
+	// See Resolver.ResolveWikilink for how this is used.
+	Destination []byte
+
+	// Title is used as the title attribute of the link, if non-empty.
+	//
+	//	<a href="Foo.html" title="Foo: an introduction">
+	Title string
+
+	// Attrs are additional attributes for the link.
+	// Values are HTML-escaped, and attributes are written in order
+	// of their names.
+	// A "class" attribute is merged with the classes added by the Renderer.
+	// "href" and "title" are ignored; use Destination and Title instead.
+	Attrs map[string]string
+
+	// Missing reports that the target of the wikilink does not exist,
+	// but the wikilink should still link to Destination,
+	// for example, to a page where it can be created.
+	//
+	// Such links get the "wikilink-missing" class
+	// and are reported as LinkMissing.
+	Missing bool
+
+	// Private reports that the target of the wikilink exists
+	// but must not be linked to, for example, because it's a private note
+	// on a published site.
+	//
+	// Such wikilinks are rendered as plain text without their destination,
+	// or in a <span> if the Renderer's MarkPrivate option is set.
+	// They're still reported as LinkOK with their destination.
+	Private bool
+}
+
+// DetailedResolver is an optional interface for resolvers
+// that report a Resolution rather than just a destination.
+//
+// The Renderer calls ResolveWikilinkDetails instead of ResolveWikilink
+// for resolvers that implement it.
+// Use Node.Context to get the context.Context for the wikilink.
+type DetailedResolver interface {
+	Resolver
+
+	// ResolveWikilinkDetails is like ResolveWikilink,
+	// but it reports the destination as part of a Resolution.
+	ResolveWikilinkDetails(*Node) (Resolution, error)
+}
+
+// ResolveDetails resolves n with r,
+// using ResolveWikilinkDetails if r is a DetailedResolver,
+// or ResolveNode otherwise.
+//
+// Resolvers that wrap other resolvers must use this,
+// and implement DetailedResolver themselves,
+// so that the Title, Attrs, Missing, and Private fields reported by
+// the resolvers they wrap aren't lost.
+// For example, a wrapped resolver's private target would otherwise
+// be linked to.
+func ResolveDetails(r Resolver, n *Node) (Resolution, error) {
+	if dr, ok := r.(DetailedResolver); ok {
+		return dr.ResolveWikilinkDetails(n)
+	}
+
+	dest, err := ResolveNode(r, n)
+	return Resolution{Destination: dest}, err
+}
+
+// writeResolutionAttrs writes the attributes of a Resolution in order of
+// their names, skipping those that the Renderer writes itself
+// and those with invalid names.
+func writeResolutionAttrs(w util.BufWriter, attrs map[string]string) {
+	if len(attrs) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		switch name {
+		case "href", "class", "title":
+			continue
+		}
+		if validAttrName(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		writeAttr(w, name, attrs[name])
+	}
+}
+
+// nodeAttrs returns the attributes of n that the Renderer may write,
+// as set with Parser.PipeAttributes: global HTML attributes and
+// "data-" attributes.
+// It returns nil if there aren't any.
+func nodeAttrs(n *Node) map[string]string {
+	var attrs map[string]string
+	for _, attr := range n.Attributes() {
+		value, ok := attr.Value.([]byte)
+		if !ok || !validAttrName(string(attr.Name)) {
+			continue
+		}
+		if !html.GlobalAttributeFilter.Contains(attr.Name) && !bytes.HasPrefix(attr.Name, _dataPrefix) {
+			continue
+		}
+		if attrs == nil {
+			attrs = make(map[string]string)
+		}
+		attrs[string(attr.Name)] = string(value)
+	}
+	return attrs
+}
+
+var _dataPrefix = []byte("data-")
+
+// writeAttr writes ` name="value"` with value HTML-escaped.
+func writeAttr(w util.BufWriter, name, value string) {
+	_ = w.WriteByte(' ')
+	_, _ = w.WriteString(name)
+	_, _ = w.WriteString(`="`)
+	_, _ = w.Write(util.EscapeHTML([]byte(value)))
+	_ = w.WriteByte('"')
+}
+
+// validAttrName reports whether name is safe to use as an HTML attribute
+// name: non-empty and made only of ASCII letters, digits, "-", "_", ":",
+// and ".".
+func validAttrName(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		switch c := name[i]; {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case c == '-', c == '_', c == ':', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,194 @@
+package wikilink
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+type detailedResolverFunc func(*Node) (Resolution, error)
+
+func (f detailedResolverFunc) ResolveWikilink(n *Node) ([]byte, error) {
+	res, err := f(n)
+	return res.Destination, err
+}
+
+func (f detailedResolverFunc) ResolveWikilinkDetails(n *Node) (Resolution, error) {
+	return f(n)
+}
+
+// privateResolver resolves all wikilinks to "secret.html"
+// as private targets.
+var privateResolver = detailedResolverFunc(func(*Node) (Resolution, error) {
+	return Resolution{Destination: []byte("secret.html"), Private: true}, nil
+})
+
+// assertNotLinked asserts that src renders with r
+// without the destinations of private targets,
+// as resolved by a privateResolver that r wraps.
+func assertNotLinked(t *testing.T, r Resolver, src string) {
+	t.Helper()
+
+	md := goldmark.New(goldmark.WithExtensions(&Extender{Resolver: r}))
+	var buf bytes.Buffer
+	require.NoError(t, md.Convert([]byte(src), &buf))
+	assert.NotContains(t, buf.String(), "href")
+	assert.NotContains(t, buf.String(), "secret")
+}
+
+func TestRenderer_Resolution(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc       string
+		give       *Node
+		res        Resolution
+		wantHTML   string
+		wantStatus LinkStatus
+	}{
+		{
+			desc:       "destination",
+			give:       &Node{Target: []byte("Foo")},
+			res:        Resolution{Destination: []byte("foo.html")},
+			wantHTML:   `<a href="foo.html">`,
+			wantStatus: LinkOK,
+		},
+		{
+			desc: "title",
+			give: &Node{Target: []byte("Foo")},
+			res: Resolution{
+				Destination: []byte("foo.html"),
+				Title:       `Foo & "Bar"`,
+			},
+			wantHTML:   `<a href="foo.html" title="Foo &amp; &quot;Bar&quot;">`,
+			wantStatus: LinkOK,
+		},
+		{
+			desc: "attributes",
+			give: &Node{Target: []byte("Foo")},
+			res: Resolution{
+				Destination: []byte("foo.html"),
+				Attrs: map[string]string{
+					"data-id":    "42",
+					"aria-label": "<Foo>",
+					"href":       "evil.html",
+					"title":      "ignored",
+					`x" onclick`: "alert(1)",
+				},
+			},
+			wantHTML:   `<a href="foo.html" aria-label="&lt;Foo&gt;" data-id="42">`,
+			wantStatus: LinkOK,
+		},
+		{
+			desc: "missing",
+			give: &Node{Target: []byte("Foo")},
+			res: Resolution{
+				Destination: []byte("new?title=Foo"),
+				Missing:     true,
+			},
+			wantHTML:   `<a href="new?title=Foo" class="wikilink-missing">`,
+			wantStatus: LinkMissing,
+		},
+		{
+			desc: "classes",
+			give: &Node{Target: []byte("report.pdf")},
+			res: Resolution{
+				Destination: []byte("report.pdf"),
+				Missing:     true,
+				Attrs:       map[string]string{"class": "big"},
+			},
+			wantHTML:   `<a href="report.pdf" download class="wikilink-file-pdf wikilink-missing big">`,
+			wantStatus: LinkMissing,
+		},
+		{
+			desc:       "no destination",
+			give:       &Node{Target: []byte("Foo")},
+			res:        Resolution{Title: "ignored"},
+			wantHTML:   ``,
+			wantStatus: LinkMissing,
+		},
+		{
+			desc:       "private",
+			give:       &Node{Target: []byte("Foo")},
+			res:        Resolution{Destination: []byte("secret/Foo.html"), Private: true},
+			wantHTML:   ``,
+			wantStatus: LinkOK,
+		},
+		{
+			desc:       "private embed",
+			give:       &Node{Target: []byte("Foo.png"), Embed: true},
+			res:        Resolution{Destination: []byte("secret/Foo.png"), Private: true},
+			wantHTML:   ``,
+			wantStatus: LinkOK,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buff bytes.Buffer
+			w := bufio.NewWriter(&buff)
+
+			report := new(LinkReport)
+			r := Renderer{
+				Resolver: detailedResolverFunc(func(*Node) (Resolution, error) {
+					return tt.res, nil
+				}),
+				DownloadExtensions: DefaultDownloadExtensions,
+				Report:             report,
+			}
+			_, err := r.Render(w, nil /* source */, tt.give, true /* entering */)
+			require.NoError(t, err, "should not fail")
+			require.NoError(t, w.Flush(), "flush")
+
+			assert.Equal(t, tt.wantHTML, buff.String(), "output mismatch")
+			if entries := report.Entries(); assert.Len(t, entries, 1) {
+				assert.Equal(t, tt.wantStatus, entries[0].Status, "status mismatch")
+			}
+		})
+	}
+}
+
+func TestRenderer_MarkPrivate(t *testing.T) {
+	t.Parallel()
+
+	var buff bytes.Buffer
+	w := bufio.NewWriter(&buff)
+
+	r := Renderer{
+		Resolver: detailedResolverFunc(func(*Node) (Resolution, error) {
+			return Resolution{Destination: []byte("secret.html"), Private: true}, nil
+		}),
+		MarkPrivate: true,
+	}
+	n := &Node{Target: []byte("secret")}
+	_, err := r.Render(w, nil /* source */, n, true /* entering */)
+	require.NoError(t, err, "entering")
+	_, err = r.Render(w, nil /* source */, n, false /* entering */)
+	require.NoError(t, err, "exiting")
+	require.NoError(t, w.Flush(), "flush")
+
+	assert.Equal(t, `<span class="wikilink-private"></span>`, buff.String())
+}
+
+func TestRenderer_ResolutionError(t *testing.T) {
+	t.Parallel()
+
+	sadness := errors.New("great sadness")
+	r := Renderer{
+		Resolver: detailedResolverFunc(func(*Node) (Resolution, error) {
+			return Resolution{}, sadness
+		}),
+	}
+
+	var buff bytes.Buffer
+	_, err := r.Render(bufio.NewWriter(&buff), nil /* source */, &Node{Target: []byte("Foo")}, true /* entering */)
+	assert.ErrorIs(t, err, sadness)
+}
@@ -0,0 +1,80 @@
+package wikilink
+
+import "bytes"
+
+// ParseTarget parses the text of a single wikilink into a Node
+// with the same rules that Parser uses by default.
+// Use this with Resolve in tools that handle wikilinks
+// without rendering Markdown, like link rewriters or search indexers.
+//
+// The surrounding brackets are optional, and the label, if any,
+// is discarded.
+//
+//	ParseTarget("[[Foo#Bar|baz]]") // => Node{Target: "Foo", Fragment: "Bar"}
+//	ParseTarget("![[cat.png]]")    // => Node{Target: "cat.png", Embed: true}
+//	ParseTarget("Foo#Bar")         // => Node{Target: "Foo", Fragment: "Bar"}
+//
+// ParseTarget returns nil if Parser would not accept s as a wikilink
+// because its target or its label is empty, like "[[|foo]]" or "[[foo|]]".
+// "[[#]]" has an empty target and fragment,
+// but Parser accepts it, and so does ParseTarget.
+func ParseTarget(s string) *Node {
+	b := []byte(s)
+
+	var embed bool
+	switch {
+	case bytes.HasPrefix(b, _open):
+		b = b[len(_open):]
+	case bytes.HasPrefix(b, _embedOpen):
+		embed = true
+		b = b[len(_embedOpen):]
+	}
+	b = bytes.TrimSuffix(b, _close)
+
+	if idx := indexUnescaped(b, _pipe); idx >= 0 {
+		if idx == len(b)-1 {
+			return nil // empty label
+		}
+		b = b[:idx]
+	}
+	if len(b) == 0 {
+		return nil
+	}
+
+	n := &Node{Target: b, Embed: embed}
+	if idx := lastIndexUnescaped(n.Target, '#'); idx >= 0 {
+		n.Fragment = n.Target[idx+1:]
+		n.Target = n.Target[:idx]
+	}
+	n.Target = unescape(n.Target, '#')
+	n.Fragment = unescape(n.Fragment, '#')
+	return n
+}
+
+// Resolve resolves a wikilink target and fragment to its destination
+// with the provided Resolver, or DefaultResolver if r is nil.
+//
+// As in Renderer, targets that start with one of DefaultURLSchemes
+// are returned as-is without consulting the resolver.
+//
+//	Resolve("Foo", "Bar", nil) // => "Foo.html#Bar"
+//
+// Resolve returns an empty string if the resolver did not find a
+// destination for the target, or if r is a DetailedResolver that reports
+// the target as Missing or Private.
+// The destination is not URL-escaped.
+func Resolve(target, fragment string, r Resolver) (string, error) {
+	n := &Node{Target: []byte(target), Fragment: []byte(fragment)}
+	if r == nil {
+		r = DefaultResolver
+	}
+	if hasURLScheme(n.Target, DefaultURLSchemes) {
+		r = urlResolver{}
+	}
+
+	res, err := ResolveDetails(r, n)
+	if err != nil || res.Missing || res.Private {
+		return "", err
+	}
+	return string(res.Destination), nil
+}
@@ -0,0 +1,184 @@
+package wikilink_test
+
+import (
+	"errors"
+	"testing"
+
+	wikilink "github.com/kentxxq/goldmark-wikilink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+func TestParseTarget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give string
+		want *wikilink.Node // nil if Parser rejects it
+	}{
+		{give: "Foo", want: &wikilink.Node{Target: []byte("Foo")}},
+		{give: "[[Foo]]", want: &wikilink.Node{Target: []byte("Foo")}},
+		{
+			give: "[[Foo#Bar|baz]]",
+			want: &wikilink.Node{Target: []byte("Foo"), Fragment: []byte("Bar")},
+		},
+		{
+			give: "![[cat.png|A cat]]",
+			want: &wikilink.Node{Target: []byte("cat.png"), Embed: true},
+		},
+		{
+			give: "[[#Bar]]",
+			want: &wikilink.Node{Target: []byte{}, Fragment: []byte("Bar")},
+		},
+		{
+			give: `[[a\|b#c\]d]]`,
+			want: &wikilink.Node{Target: []byte("a|b"), Fragment: []byte("c]d")},
+		},
+		{
+			give: `[[C\# notes#Generics]]`,
+			want: &wikilink.Node{Target: []byte("C# notes"), Fragment: []byte("Generics")},
+		},
+		{give: "[[#]]", want: &wikilink.Node{Target: []byte{}}},
+		{give: "[[ ]]", want: &wikilink.Node{Target: []byte(" ")}},
+		{give: ""},
+		{give: "[[]]"},
+		{give: "[[|label]]"},
+		{give: "[[Foo|]]"},
+		{give: "![[]]"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			got := wikilink.ParseTarget(tt.give)
+			if tt.want == nil {
+				assert.Nil(t, got)
+				return
+			}
+
+			require.NotNil(t, got)
+			assert.Equal(t, string(tt.want.Target), string(got.Target), "target")
+			assert.Equal(t, string(tt.want.Fragment), string(got.Fragment), "fragment")
+			assert.Equal(t, tt.want.Embed, got.Embed, "embed")
+		})
+	}
+}
+
+func TestParseTarget_matchesParser(t *testing.T) {
+	t.Parallel()
+
+	md := goldmark.New(goldmark.WithExtensions(&wikilink.Extender{}))
+	for _, give := range []string{
+		"[[Foo]]", "![[Foo]]", "[[]]", "![[]]", "[[|foo]]", "[[foo|]]",
+		"[[#]]", "[[#foo]]", "[[#|foo]]", "[[ ]]", `[[\#]]`, "[[foo#]]",
+	} {
+		doc := md.Parser().Parse(text.NewReader([]byte(give)))
+		var parsed bool
+		_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+			if _, ok := n.(*wikilink.Node); ok {
+				parsed = true
+			}
+			return ast.WalkContinue, nil
+		})
+		assert.Equal(t, parsed, wikilink.ParseTarget(give) != nil, give)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		target   string
+		fragment string
+		resolver wikilink.Resolver
+		want     string
+	}{
+		{
+			desc:     "default resolver",
+			target:   "Foo",
+			fragment: "Bar",
+			want:     "Foo.html#Bar",
+		},
+		{
+			desc:     "custom resolver",
+			target:   "Foo",
+			resolver: wikilink.PrettyResolver,
+			want:     "Foo/",
+		},
+		{
+			desc:     "url",
+			target:   "https://example.com",
+			fragment: "top",
+			resolver: wikilink.PrettyResolver,
+			want:     "https://example.com#top",
+		},
+		{
+			desc:     "not found",
+			target:   "Foo",
+			resolver: resolverFunc(func(*wikilink.Node) ([]byte, error) { return nil, nil }),
+			want:     "",
+		},
+		{
+			desc:   "details",
+			target: "Foo",
+			resolver: detailedResolverFunc(func(*wikilink.Node) (wikilink.Resolution, error) {
+				return wikilink.Resolution{Destination: []byte("foo.html"), Title: "Foo"}, nil
+			}),
+			want: "foo.html",
+		},
+		{
+			desc:   "missing",
+			target: "Foo",
+			resolver: detailedResolverFunc(func(*wikilink.Node) (wikilink.Resolution, error) {
+				return wikilink.Resolution{Destination: []byte("new?title=Foo"), Missing: true}, nil
+			}),
+			want: "",
+		},
+		{
+			desc:   "private",
+			target: "Foo",
+			resolver: detailedResolverFunc(func(*wikilink.Node) (wikilink.Resolution, error) {
+				return wikilink.Resolution{Destination: []byte("foo.html"), Private: true}, nil
+			}),
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := wikilink.Resolve(tt.target, tt.fragment, tt.resolver)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+
+		sadness := errors.New("great sadness")
+		_, err := wikilink.Resolve("Foo", "", resolverFunc(func(*wikilink.Node) ([]byte, error) {
+			return nil, sadness
+		}))
+		assert.ErrorIs(t, err, sadness)
+	})
+}
+
+type detailedResolverFunc func(*wikilink.Node) (wikilink.Resolution, error)
+
+func (f detailedResolverFunc) ResolveWikilink(n *wikilink.Node) ([]byte, error) {
+	res, err := f(n)
+	return res.Destination, err
+}
+
+func (f detailedResolverFunc) ResolveWikilinkDetails(n *wikilink.Node) (wikilink.Resolution, error) {
+	return f(n)
+}
@@ -1,9 +1,37 @@
 package wikilink
 
-import "path/filepath"
+import (
+	"context"
+	"path/filepath"
+)
 
-// DefaultResolver is a minimal wikilink resolver that resolves wikilinks
-// relative to the source page.
+// DefaultResolver is the resolver returned by NewDefaultResolver.
+//
+// It's kept for compatibility with existing code;
+// prefer NewDefaultResolver in new code.
+var DefaultResolver Resolver = NewDefaultResolver()
+
+// PrettyResolver is the resolver returned by NewPrettyResolver.
+//
+// It's kept for compatibility with existing code;
+// prefer NewPrettyResolver in new code.
+var PrettyResolver Resolver = NewPrettyResolver()
+
+// RelResolver is the resolver returned by NewRelResolver.
+//
+// It's kept for compatibility with existing code;
+// prefer NewRelResolver in new code.
+var RelResolver Resolver = NewRelResolver()
+
+// RootResolver builds the resolver returned by NewRootResolver.
+//
+// It's kept for compatibility with existing code;
+// prefer NewRootResolver in new code.
+var RootResolver = NewRootResolver
+
+// NewDefaultResolver returns a minimal wikilink resolver that resolves
+// wikilinks relative to the source page.
+// This is the resolver used by Renderer if one isn't specified.
 //
 // It adds ".html" to the end of the target
 // if the target does not have an extension.
@@ -15,26 +43,45 @@ import "path/filepath"
 //	[[foo/Bar]]  // => "foo/Bar.html"
 //	[[foo.pdf]]  // => "foo.pdf"
 //	[[foo.png]]  // => "foo.png"
-var DefaultResolver Resolver = defaultResolver{}
-
-// pretty url
-// https://gohugo.io/content-management/urls/#appearance
-//  [[Foo]]      // => "Foo/"
-var PrettyResolver Resolver = prettyResolver{}
-
-// when i use pretty url with [[relative path]]
-//  /root/Foo.md                 url: /root/Foo/
-//  /root/a.md include [[Foo]] . url: /root/a/    wikilink: /root/a/Foo/ not found!
-//  so...
-//  [[Foo]]      // => "../Foo/"    worked!
-var RelResolver Resolver = relResolver{}
-
-// when i use pretty url with [[absolute path]]
-//  /Foo.md                      url: /posts/Foo/
-//  /a.md include [[root/Foo]] . url: /posts/a/    wikilink: /posts/a/posts/Foo/ not found!
-//  so...
-//  [[Foo]]      // => "/root/Foo/" worked!
-var RootResolver = func(b string) Resolver {
+func NewDefaultResolver() Resolver {
+	return defaultResolver{}
+}
+
+// NewPrettyResolver returns a resolver for sites with pretty URLs,
+// where every page is served from its own directory,
+// like Hugo's (https://gohugo.io/content-management/urls/#appearance).
+//
+// It adds a "/" to the end of the target
+// if the target does not have an extension.
+//
+//	[[Foo]]     // => "Foo/"
+//	[[foo/Bar]] // => "foo/Bar/"
+func NewPrettyResolver() Resolver {
+	return prettyResolver{}
+}
+
+// NewRelResolver returns a resolver for sites with pretty URLs
+// that resolves wikilinks relative to the directory of the source page.
+//
+// With pretty URLs, a page like "/root/a.md" is served from "/root/a/",
+// so a link to "Foo/" from it would point to "/root/a/Foo/".
+// NewRelResolver adds a "../" to the destination to reach the sibling page.
+//
+//	[[Foo]] // => "../Foo/"
+func NewRelResolver() Resolver {
+	return relResolver{}
+}
+
+// NewRootResolver returns a resolver for sites with pretty URLs
+// that resolves wikilinks to absolute paths under the base path b.
+//
+// Use this when pages are served from a subdirectory of the site,
+// like "/posts/", so that links work regardless of
+// the URL of the page containing them.
+//
+//	NewRootResolver("/posts/")
+//	[[Foo]] // => "/posts/Foo/"
+func NewRootResolver(b string) Resolver {
 	return &rootResolver{
 		base: b,
 	}
@@ -47,7 +94,7 @@ type Resolver interface {
 	// being placed into a link.
 	//
 	// If ResolveWikilink returns a non-nil error, rendering will be
-	// halted.
+	// halted with a *RenderError that wraps it.
 	//
 	// If ResolveWikilink returns a nil destination and error, the
 	// Renderer will omit the link and render its contents as a regular
@@ -55,65 +102,65 @@ type Resolver interface {
 	ResolveWikilink(*Node) (destination []byte, err error)
 }
 
-var _html = []byte(".html")
+// ResolverWithContext is an optional interface for resolvers that make
+// network or database requests and want to respect cancellation and
+// deadlines during long builds.
+//
+// The Renderer calls ResolveWikilinkContext instead of ResolveWikilink
+// for resolvers that implement it, passing the context.Context set with
+// SetResolveContext, or context.Background if there isn't one.
+type ResolverWithContext interface {
+	Resolver
+
+	// ResolveWikilinkContext is like ResolveWikilink,
+	// but it should give up when ctx is done.
+	ResolveWikilinkContext(ctx context.Context, n *Node) (destination []byte, err error)
+}
+
+// ResolveNode resolves n with r,
+// using ResolveWikilinkContext with n.Context() if r is a ResolverWithContext.
+//
+// Resolvers that wrap other resolvers should use ResolveDetails instead,
+// which passes the context along in the same way.
+func ResolveNode(r Resolver, n *Node) ([]byte, error) {
+	if rc, ok := r.(ResolverWithContext); ok {
+		return rc.ResolveWikilinkContext(n.Context(), n)
+	}
+	return r.ResolveWikilink(n)
+}
 
 type defaultResolver struct{}
 
 func (defaultResolver) ResolveWikilink(n *Node) ([]byte, error) {
-	dest := make([]byte, len(n.Target)+len(_html)+len(_hash)+len(n.Fragment))
-	var i int
-	if len(n.Target) > 0 {
-		i += copy(dest, n.Target)
-		if filepath.Ext(string(n.Target)) == "" {
-			i += copy(dest[i:], _html)
-		}
-	}
-	if len(n.Fragment) > 0 {
-		i += copy(dest[i:], _hash)
-		i += copy(dest[i:], n.Fragment)
-	}
-	return dest[:i], nil
+	return DestBuilder{
+		Path:     n.Target,
+		Suffix:   pageSuffix(n.Target, ".html"),
+		Query:    n.Query,
+		Fragment: n.Fragment,
+	}.Build(), nil
 }
 
-var pretty_html = []byte("/")
-
 type prettyResolver struct{}
 
 func (prettyResolver) ResolveWikilink(n *Node) ([]byte, error) {
-	dest := make([]byte, len(n.Target)+len(pretty_html)+len(_hash)+len(n.Fragment))
-	var i int
-	if len(n.Target) > 0 {
-		i += copy(dest, n.Target)
-		if filepath.Ext(string(n.Target)) == "" {
-			i += copy(dest[i:], pretty_html)
-		}
-	}
-	if len(n.Fragment) > 0 {
-		i += copy(dest[i:], _hash)
-		i += copy(dest[i:], n.Fragment)
-	}
-	return dest[:i], nil
+	return DestBuilder{
+		Path:     n.Target,
+		Suffix:   pageSuffix(n.Target, "/"),
+		Query:    n.Query,
+		Fragment: n.Fragment,
+	}.Build(), nil
 }
 
-var rel_head = []byte("../")
-
 type relResolver struct{}
 
 func (relResolver) ResolveWikilink(n *Node) ([]byte, error) {
-	dest := make([]byte, len(rel_head)+len(n.Target)+len(pretty_html)+len(_hash)+len(n.Fragment))
-	var i int
-	if len(n.Target) > 0 {
-		i += copy(dest, rel_head)
-		i += copy(dest[i:], n.Target)
-		if filepath.Ext(string(n.Target)) == "" {
-			i += copy(dest[i:], pretty_html)
-		}
-	}
-	if len(n.Fragment) > 0 {
-		i += copy(dest[i:], _hash)
-		i += copy(dest[i:], n.Fragment)
-	}
-	return dest[:i], nil
+	return DestBuilder{
+		Prefix:   "../",
+		Path:     n.Target,
+		Suffix:   pageSuffix(n.Target, "/"),
+		Query:    n.Query,
+		Fragment: n.Fragment,
+	}.Build(), nil
 }
 
 type rootResolver struct {
@@ -121,18 +168,20 @@ type rootResolver struct {
 }
 
 func (r rootResolver) ResolveWikilink(n *Node) ([]byte, error) {
-	dest := make([]byte, len(r.base)+len(n.Target)+len(pretty_html)+len(_hash)+len(n.Fragment))
-	var i int
-	if len(n.Target) > 0 {
-		i += copy(dest, []byte(r.base))
-		i += copy(dest[i:], n.Target)
-		if filepath.Ext(string(n.Target)) == "" {
-			i += copy(dest[i:], pretty_html)
-		}
-	}
-	if len(n.Fragment) > 0 {
-		i += copy(dest[i:], _hash)
-		i += copy(dest[i:], n.Fragment)
+	return DestBuilder{
+		Prefix:   r.base,
+		Path:     n.Target,
+		Suffix:   pageSuffix(n.Target, "/"),
+		Query:    n.Query,
+		Fragment: n.Fragment,
+	}.Build(), nil
+}
+
+// pageSuffix returns suffix if target is a page, i.e. it doesn't have
+// an extension, and "" otherwise.
+func pageSuffix(target []byte, suffix string) string {
+	if filepath.Ext(string(target)) == "" {
+		return suffix
 	}
-	return dest[:i], nil
+	return ""
 }
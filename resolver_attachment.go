@@ -0,0 +1,84 @@
+package wikilink
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// AttachmentResolver resolves embeds of attachments, like images,
+// that are stored in Obsidian's attachment folder.
+//
+// When the target of an embed has no directory, like ![[photo.png]],
+// AttachmentResolver looks for it in Folder.
+// If the file exists there, the embed is resolved with Next
+// as if its target had been written with that folder.
+// All other wikilinks are resolved with Next as-is.
+//
+//	r := &wikilink.AttachmentResolver{
+//		FS:     os.DirFS("vault"),
+//		Folder: "attachments",
+//		Next:   wikilink.NewRootResolver("/"),
+//	}
+//	![[photo.png]] // => "/attachments/photo.png" if vault/attachments/photo.png exists
+//
+// The targets passed to Next are relative to the root of FS,
+// so use it with a resolver that builds absolute URLs,
+// like NewRootResolver.
+type AttachmentResolver struct {
+	// FS holds the files of the vault.
+	// It's used to check whether attachments exist.
+	FS fs.FS
+
+	// Folder is the attachment folder, relative to the root of FS.
+	//
+	// Folders starting with "./" are relative to the directory of the
+	// document containing the wikilink, as set by SetContextSource,
+	// to match Obsidian's "Same folder as current file" ("./")
+	// and "In subfolder under current folder" ("./attachments")
+	// settings.
+	Folder string
+
+	// Next resolves the wikilinks.
+	//
+	// Defaults to DefaultResolver.
+	Next Resolver
+}
+
+var _ DetailedResolver = (*AttachmentResolver)(nil)
+
+// ResolveWikilink resolves the provided wikilink with Next,
+// pointing embeds of attachments into the attachment folder.
+func (r *AttachmentResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	res, err := r.ResolveWikilinkDetails(n)
+	return res.Destination, err
+}
+
+// ResolveWikilinkDetails is like ResolveWikilink,
+// but it reports the Resolution of Next.
+func (r *AttachmentResolver) ResolveWikilinkDetails(n *Node) (Resolution, error) {
+	next := r.Next
+	if next == nil {
+		next = DefaultResolver
+	}
+
+	if !n.Embed || len(n.Target) == 0 || strings.IndexByte(string(n.Target), '/') >= 0 {
+		return ResolveDetails(next, n)
+	}
+
+	folder := r.Folder
+	if folder == "." || strings.HasPrefix(folder, "./") {
+		folder = path.Join(path.Dir(n.Source()), folder)
+	}
+	name := path.Join(folder, string(n.Target))
+	if !fs.ValidPath(name) {
+		return ResolveDetails(next, n)
+	}
+
+	if _, err := fs.Stat(r.FS, name); err != nil {
+		n.Tracef("no attachment %q", name)
+		return ResolveDetails(next, n)
+	}
+	n.Tracef("attachment %q", name)
+	return ResolveDetails(next, n.withTarget([]byte(name)))
+}
@@ -0,0 +1,105 @@
+package wikilink
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentResolver(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"attachments/photo.png":          {},
+		"notes/trip.md":                  {},
+		"notes/map.png":                  {},
+		"notes/attachments/ticket.pdf":   {},
+		"notes/attachments/photo.png":    {},
+		"notes/nested/attachments/x.png": {},
+	}
+
+	tests := []struct {
+		desc   string
+		folder string
+		give   *Node
+		want   string
+	}{
+		{
+			desc:   "folder",
+			folder: "attachments",
+			give:   &Node{Target: []byte("photo.png"), Embed: true},
+			want:   "/attachments/photo.png",
+		},
+		{
+			desc:   "not in folder",
+			folder: "attachments",
+			give:   &Node{Target: []byte("other.png"), Embed: true},
+			want:   "/other.png",
+		},
+		{
+			desc:   "has directory",
+			folder: "attachments",
+			give:   &Node{Target: []byte("notes/map.png"), Embed: true},
+			want:   "/notes/map.png",
+		},
+		{
+			desc:   "not an embed",
+			folder: "attachments",
+			give:   &Node{Target: []byte("photo.png")},
+			want:   "/photo.png",
+		},
+		{
+			desc:   "same folder",
+			folder: "./",
+			give:   &Node{Target: []byte("map.png"), Embed: true, source: "notes/trip.md"},
+			want:   "/notes/map.png",
+		},
+		{
+			desc:   "subfolder",
+			folder: "./attachments",
+			give:   &Node{Target: []byte("ticket.pdf"), Embed: true, source: "notes/trip.md"},
+			want:   "/notes/attachments/ticket.pdf",
+		},
+		{
+			desc:   "subfolder without source",
+			folder: "./attachments",
+			give:   &Node{Target: []byte("photo.png"), Embed: true},
+			want:   "/attachments/photo.png",
+		},
+		{
+			desc:   "escapes root",
+			folder: "../attachments",
+			give:   &Node{Target: []byte("photo.png"), Embed: true},
+			want:   "/photo.png",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			r := &AttachmentResolver{
+				FS:     files,
+				Folder: tt.folder,
+				Next:   NewRootResolver("/"),
+			}
+			got, err := r.ResolveWikilink(tt.give)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestAttachmentResolver_Private(t *testing.T) {
+	t.Parallel()
+
+	r := &AttachmentResolver{
+		FS:     fstest.MapFS{"attachments/doc.pdf": {}},
+		Folder: "attachments",
+		Next:   privateResolver,
+	}
+	assertNotLinked(t, r, "[[Foo]] ![[doc.pdf]]")
+}
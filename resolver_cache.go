@@ -0,0 +1,137 @@
+package wikilink
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// NewCachedResolver returns a Resolver that remembers the destinations
+// returned by next for up to size distinct wikilinks, forgetting the
+// least recently used ones first. Use this with expensive resolvers in
+// long-running builds where an unbounded cache would grow without limit.
+//
+//	wikilink.NewCachedResolver(4096, expensiveResolver)
+//
+// Wikilinks are identified by their target, fragment, and query,
+// and whether they are embeds. Do not use this with resolvers whose
+// results depend on anything else, like the source document or Site.
+//
+// If next is a DetailedResolver, the whole Resolution is remembered,
+// including its Title, Attrs, and whether it's Missing or Private.
+// The context of each wikilink is passed along to next.
+//
+// Errors returned by next are not remembered.
+// Links resolved from the cache are counted by the Renderer's Metrics.
+// If size is not positive, next is returned as-is.
+//
+// The returned Resolver is safe for concurrent use
+// if next is safe for concurrent use.
+func NewCachedResolver(size int, next Resolver) Resolver {
+	if size <= 0 {
+		return next
+	}
+	return &cachedResolver{
+		size:    size,
+		next:    next,
+		entries: make(map[cacheKey]*list.Element, size),
+		order:   list.New(),
+	}
+}
+
+type cacheKey struct {
+	target, fragment, query string
+	embed                   bool
+}
+
+type cacheEntry struct {
+	key cacheKey
+	res Resolution
+}
+
+type cachedResolver struct {
+	size int
+	next Resolver
+
+	mu      sync.Mutex
+	entries map[cacheKey]*list.Element // value is *cacheEntry
+	order   *list.List                 // most recently used first
+}
+
+var (
+	_ DetailedResolver    = (*cachedResolver)(nil)
+	_ ResolverWithContext = (*cachedResolver)(nil)
+)
+
+func (r *cachedResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	res, err := r.ResolveWikilinkDetails(n)
+	return res.Destination, err
+}
+
+func (r *cachedResolver) ResolveWikilinkContext(ctx context.Context, n *Node) ([]byte, error) {
+	res, err := r.lookup(n, func() (Resolution, error) {
+		if _, ok := r.next.(DetailedResolver); ok {
+			return ResolveDetails(r.next, n)
+		}
+		if rc, ok := r.next.(ResolverWithContext); ok {
+			dest, err := rc.ResolveWikilinkContext(ctx, n)
+			return Resolution{Destination: dest}, err
+		}
+		dest, err := r.next.ResolveWikilink(n)
+		return Resolution{Destination: dest}, err
+	})
+	return res.Destination, err
+}
+
+func (r *cachedResolver) ResolveWikilinkDetails(n *Node) (Resolution, error) {
+	return r.lookup(n, func() (Resolution, error) {
+		return ResolveDetails(r.next, n)
+	})
+}
+
+// lookup returns the Resolution remembered for n,
+// or remembers and returns the one reported by resolve.
+func (r *cachedResolver) lookup(n *Node, resolve func() (Resolution, error)) (Resolution, error) {
+	key := cacheKey{
+		target:   string(n.Target),
+		fragment: string(n.Fragment),
+		query:    string(n.Query),
+		embed:    n.Embed,
+	}
+
+	r.mu.Lock()
+	if el, ok := r.entries[key]; ok {
+		r.order.MoveToFront(el)
+		res := el.Value.(*cacheEntry).res
+		r.mu.Unlock()
+		n.Tracef("cache hit")
+		if n.metrics != nil {
+			n.metrics.cacheHits.Add(1)
+		}
+		return res, nil
+	}
+	r.mu.Unlock()
+	n.Tracef("cache miss")
+
+	// Don't hold the lock while resolving
+	// so that slow resolutions don't block others.
+	res, err := resolve()
+	if err != nil {
+		return Resolution{}, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if el, ok := r.entries[key]; ok {
+		// Resolved concurrently by another caller.
+		r.order.MoveToFront(el)
+		return res, nil
+	}
+	r.entries[key] = r.order.PushFront(&cacheEntry{key: key, res: res})
+	if r.order.Len() > r.size {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.entries, oldest.Value.(*cacheEntry).key)
+	}
+	return res, nil
+}
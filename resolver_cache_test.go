@@ -0,0 +1,161 @@
+package wikilink
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedResolver(t *testing.T) {
+	t.Parallel()
+
+	calls := make(map[string]int)
+	r := NewCachedResolver(2, resolverFunc(func(n *Node) ([]byte, error) {
+		calls[string(n.Target)+"#"+string(n.Fragment)]++
+		return DefaultResolver.ResolveWikilink(n)
+	}))
+
+	resolve := func(target, fragment string) string {
+		got, err := r.ResolveWikilink(&Node{Target: []byte(target), Fragment: []byte(fragment)})
+		require.NoError(t, err, "resolve %q#%q", target, fragment)
+		return string(got)
+	}
+
+	assert.Equal(t, "foo.html", resolve("foo", ""))
+	assert.Equal(t, "foo.html#bar", resolve("foo", "bar"))
+	assert.Equal(t, "foo.html", resolve("foo", ""))
+	assert.Equal(t, map[string]int{"foo#": 1, "foo#bar": 1}, calls, "hits")
+
+	// "foo#bar" is the least recently used entry so it's evicted.
+	assert.Equal(t, "baz.html", resolve("baz", ""))
+	assert.Equal(t, "foo.html", resolve("foo", ""))
+	assert.Equal(t, "foo.html#bar", resolve("foo", "bar"))
+	assert.Equal(t, map[string]int{"foo#": 1, "foo#bar": 2, "baz#": 1}, calls, "eviction")
+}
+
+func TestCachedResolver_errors(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	sadness := errors.New("great sadness")
+	r := NewCachedResolver(10, resolverFunc(func(*Node) ([]byte, error) {
+		calls++
+		return nil, sadness
+	}))
+
+	for i := 0; i < 2; i++ {
+		_, err := r.ResolveWikilink(&Node{Target: []byte("foo")})
+		assert.ErrorIs(t, err, sadness)
+	}
+	assert.Equal(t, 2, calls, "errors must not be cached")
+}
+
+func TestCachedResolver_unpublished(t *testing.T) {
+	t.Parallel()
+
+	r := NewCachedResolver(10, &UnpublishedResolver{
+		Pages: map[string]PageMeta{"draft": {Draft: true}},
+	})
+
+	for i := 0; i < 2; i++ {
+		got, err := ResolveNode(r, &Node{Target: []byte("draft")})
+		require.NoError(t, err)
+		assert.Nil(t, got, "draft")
+
+		got, err = ResolveNode(r, &Node{Target: []byte("post")})
+		require.NoError(t, err)
+		assert.Equal(t, "post.html", string(got), "post")
+	}
+}
+
+func TestCachedResolver_details(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	r := NewCachedResolver(10, detailedResolverFunc(func(n *Node) (Resolution, error) {
+		calls++
+		return Resolution{
+			Destination: []byte("secret.html"),
+			Title:       "Secret",
+			Private:     true,
+		}, nil
+	}))
+	require.Implements(t, (*DetailedResolver)(nil), r)
+
+	for i := 0; i < 2; i++ {
+		got, err := ResolveDetails(r, &Node{Target: []byte("secret")})
+		require.NoError(t, err)
+		assert.Equal(t, Resolution{
+			Destination: []byte("secret.html"),
+			Title:       "Secret",
+			Private:     true,
+		}, got)
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestCachedResolver_context(t *testing.T) {
+	t.Parallel()
+
+	r := NewCachedResolver(10, resolverWithContextFunc(
+		func(ctx context.Context, n *Node) ([]byte, error) {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return DefaultResolver.ResolveWikilink(n)
+		}))
+	require.Implements(t, (*ResolverWithContext)(nil), r)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rc := r.(ResolverWithContext)
+	_, err := rc.ResolveWikilinkContext(ctx, &Node{Target: []byte("foo")})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	got, err := rc.ResolveWikilinkContext(context.Background(), &Node{Target: []byte("foo")})
+	require.NoError(t, err)
+	assert.Equal(t, "foo.html", string(got))
+}
+
+type resolverWithContextFunc func(context.Context, *Node) ([]byte, error)
+
+func (f resolverWithContextFunc) ResolveWikilink(n *Node) ([]byte, error) {
+	return f(context.Background(), n)
+}
+
+func (f resolverWithContextFunc) ResolveWikilinkContext(ctx context.Context, n *Node) ([]byte, error) {
+	return f(ctx, n)
+}
+
+func TestCachedResolver_disabled(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, DefaultResolver, NewCachedResolver(0, DefaultResolver))
+}
+
+func TestCachedResolver_concurrent(t *testing.T) {
+	t.Parallel()
+
+	r := NewCachedResolver(4, DefaultResolver)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				target := []byte{'a' + byte((i+j)%6)}
+				got, err := r.ResolveWikilink(&Node{Target: target})
+				assert.NoError(t, err)
+				assert.Equal(t, string(target)+".html", string(got))
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,104 @@
+package wikilink
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// DocusaurusDoc holds the front matter of a Docusaurus document
+// that affects its URL.
+type DocusaurusDoc struct {
+	// ID overrides the last component of the document's URL.
+	ID string
+
+	// Slug overrides the document's URL.
+	// Slugs starting with "/" are relative to the docs route,
+	// and others are relative to the document's directory.
+	Slug string
+}
+
+// NewDocusaurusResolver returns a resolver that resolves wikilinks to the URLs
+// of documents served by the Docusaurus docs plugin at routeBasePath,
+// usually "/docs".
+//
+// Targets are paths to documents relative to the docs directory.
+// Number prefixes used to order documents are dropped from each path
+// component, and index and README documents are served from their
+// directory.
+//
+//	r := NewDocusaurusResolver("/docs", nil)
+//	[[01-intro]]                 // => "/docs/intro"
+//	[[02-guides/03-setup.md]]    // => "/docs/guides/setup"
+//	[[02-guides/index#Overview]] // => "/docs/guides#overview"
+//
+// docs maps document paths, as used in wikilink targets and without their
+// extension, to the id and slug front matter of those documents.
+//
+//	r := NewDocusaurusResolver("/docs", map[string]wikilink.DocusaurusDoc{
+//		"02-guides/03-setup": {Slug: "/install"},
+//	})
+//	[[02-guides/03-setup]] // => "/docs/install"
+func NewDocusaurusResolver(routeBasePath string, docs map[string]DocusaurusDoc) Resolver {
+	return &docusaurusResolver{
+		base: strings.TrimSuffix(routeBasePath, "/"),
+		docs: docs,
+	}
+}
+
+type docusaurusResolver struct {
+	base string
+	docs map[string]DocusaurusDoc
+}
+
+func (r *docusaurusResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	b := DestBuilder{Path: n.Target, Query: n.Query}
+	if len(n.Target) > 0 {
+		target := string(n.Target)
+		switch ext := path.Ext(target); ext {
+		case "", ".md", ".mdx":
+			b.Prefix = r.base
+			b.Path = []byte(r.route(strings.TrimSuffix(target, ext)))
+		}
+	}
+	if len(n.Fragment) > 0 {
+		b.Fragment = []byte(githubAnchor(string(n.Fragment)))
+	}
+	return b.Build(), nil
+}
+
+// route returns the URL of the given document relative to the docs route.
+// The result always starts with a "/".
+func (r *docusaurusResolver) route(doc string) string {
+	doc = strings.TrimPrefix(path.Clean("/"+doc), "/")
+	fm := r.docs[doc]
+
+	parts := splitPath(doc)
+	if len(parts) == 0 {
+		return "/"
+	}
+	for i, part := range parts {
+		parts[i] = _docusaurusNumberPrefix.ReplaceAllString(part, "")
+	}
+
+	dir := "/" + strings.Join(parts[:len(parts)-1], "/")
+	switch {
+	case strings.HasPrefix(fm.Slug, "/"):
+		return path.Clean(fm.Slug)
+	case len(fm.Slug) > 0:
+		return path.Join(dir, fm.Slug)
+	case len(fm.ID) > 0:
+		return path.Join(dir, fm.ID)
+	}
+
+	switch last := parts[len(parts)-1]; strings.ToLower(last) {
+	case "index", "readme":
+		return dir
+	default:
+		return path.Join(dir, last)
+	}
+}
+
+// _docusaurusNumberPrefix matches the number prefixes used to order
+// documents in Docusaurus, like "01-" or "2. ".
+var _docusaurusNumberPrefix = regexp.MustCompile(`^\d+\s*[-_.]+\s*`)
@@ -0,0 +1,54 @@
+package wikilink
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocusaurusResolver(t *testing.T) {
+	t.Parallel()
+
+	docs := map[string]DocusaurusDoc{
+		"02-guides/03-setup":  {Slug: "/install"},
+		"02-guides/04-deploy": {Slug: "shipping"},
+		"03-api/01-client":    {ID: "go-client"},
+	}
+
+	tests := []struct {
+		base     string
+		target   string
+		fragment string
+		want     string
+	}{
+		{base: "/docs", target: "intro", want: "/docs/intro"},
+		{base: "/docs/", target: "01-intro.md", want: "/docs/intro"},
+		{base: "/docs", target: "02-guides/01_first steps.mdx", want: "/docs/guides/first steps"},
+		{base: "/docs", target: "02-guides/index", fragment: "Overview", want: "/docs/guides#overview"},
+		{base: "/docs", target: "README", want: "/docs/"},
+		{base: "/", target: "2. Guides/README.md", want: "/Guides"},
+		{base: "/docs", target: "02-guides/03-setup", want: "/docs/install"},
+		{base: "/docs", target: "02-guides/04-deploy.md", want: "/docs/guides/shipping"},
+		{base: "/docs", target: "03-api/01-client", want: "/docs/api/go-client"},
+		{base: "/docs", target: "/img/logo.png", want: "/img/logo.png"},
+		{base: "/docs", target: "/", want: "/docs/"},
+		{base: "/docs", fragment: "Foo Bar", want: "#foo-bar"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		name := fmt.Sprintf("%v %v#%v", tt.base, tt.target, tt.fragment)
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewDocusaurusResolver(tt.base, docs).ResolveWikilink(&Node{
+				Target:   []byte(tt.target),
+				Fragment: []byte(tt.fragment),
+			})
+			require.NoError(t, err, "resolve failed")
+			assert.Equal(t, tt.want, string(got), "result mismatch")
+		})
+	}
+}
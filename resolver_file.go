@@ -0,0 +1,151 @@
+package wikilink
+
+import (
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FileURLResolver resolves wikilinks to file:// URLs
+// of files inside a directory on the local file system,
+// so that HTML previews generated from a vault have working links
+// when they're opened in a browser without a web server.
+//
+//	r := &wikilink.FileURLResolver{Dir: "/home/me/preview"}
+//	[[Foo bar#Baz]] // => "file:///home/me/preview/Foo%20bar.html#Baz"
+//	![[cat.png]]    // => "file:///home/me/preview/cat.png"
+//
+// Next resolves the path of each wikilink relative to Dir.
+// Paths ending with "/", like those of NewPrettyResolver,
+// get "index.html" added because browsers don't look for index files
+// in file:// URLs.
+// Destinations that are already URLs are returned as-is.
+//
+// On Windows, URLs are built following its conventions:
+//
+//	Dir: `C:\Users\me\preview`  // => "file:///C:/Users/me/preview/..."
+//	Dir: `\\server\share\notes` // => "file://server/share/notes/..."
+type FileURLResolver struct {
+	// Dir is the directory containing the files,
+	// such as the output directory of the preview.
+	// Relative paths are relative to the current working directory.
+	Dir string
+
+	// Next resolves wikilinks to paths relative to Dir.
+	//
+	// Defaults to DefaultResolver.
+	Next Resolver
+}
+
+var _ DetailedResolver = (*FileURLResolver)(nil)
+
+// ResolveWikilink resolves the provided wikilink with Next
+// and turns the result into a file:// URL.
+func (r *FileURLResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	res, err := r.ResolveWikilinkDetails(n)
+	return res.Destination, err
+}
+
+// ResolveWikilinkDetails is like ResolveWikilink,
+// but it reports the Resolution of Next with the file:// URL
+// as its Destination.
+func (r *FileURLResolver) ResolveWikilinkDetails(n *Node) (Resolution, error) {
+	next := r.Next
+	if next == nil {
+		next = DefaultResolver
+	}
+
+	res, err := ResolveDetails(next, n)
+	if err != nil {
+		return res, err
+	}
+	if res.Destination, err = r.toFileURL(res.Destination); err != nil {
+		return Resolution{}, err
+	}
+	return res, nil
+}
+
+// toFileURL turns a destination resolved by Next into a file:// URL.
+func (r *FileURLResolver) toFileURL(dest []byte) ([]byte, error) {
+	if len(dest) == 0 {
+		return dest, nil
+	}
+
+	rel, fragment := string(dest), ""
+	if idx := strings.IndexByte(rel, '#'); idx >= 0 {
+		rel, fragment = rel[:idx], rel[idx+1:]
+	}
+	if idx := strings.IndexByte(rel, '?'); idx >= 0 {
+		rel = rel[:idx] // files don't have queries
+	}
+	if len(rel) == 0 || hasURLScheme([]byte(rel), DefaultURLSchemes) || strings.HasPrefix(rel, "//") {
+		return dest, nil
+	}
+	// Resolvers leave most characters as-is but percent-encode some,
+	// like "#", so decode only valid sequences.
+	rel = unescapeValid(rel)
+	if strings.HasSuffix(rel, "/") {
+		rel += "index.html"
+	}
+
+	dir, err := filepath.Abs(r.Dir)
+	if err != nil {
+		return nil, err
+	}
+	name := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(rel, "/")))
+
+	u := fileURL(filepath.ToSlash(name))
+	if len(fragment) > 0 {
+		u.Fragment = unescapeValid(fragment)
+	}
+	return []byte(u.String()), nil
+}
+
+// fileURL returns the file:// URL for an absolute path
+// with "/" as the separator.
+//
+//	/home/me/a b.html     // => file:///home/me/a%20b.html
+//	C:/Users/me/a.html    // => file:///C:/Users/me/a.html
+//	//server/share/a.html // => file://server/share/a.html
+func fileURL(name string) *url.URL {
+	u := &url.URL{Scheme: "file"}
+	switch {
+	case strings.HasPrefix(name, "//"):
+		// UNC path: the first component is the host.
+		host, rest, _ := strings.Cut(name[2:], "/")
+		u.Host, u.Path = host, "/"+rest
+	case strings.HasPrefix(name, "/"):
+		u.Path = name
+	default:
+		// Windows drive letter, like "C:/".
+		u.Path = "/" + name
+	}
+	return u
+}
+
+// unescapeValid decodes the valid percent-encoded sequences in s,
+// leaving other "%" characters as-is.
+//
+//	C%23 & 100%.html // => C# & 100%.html
+func unescapeValid(s string) string {
+	if strings.IndexByte(s, '%') < 0 {
+		return s
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]) {
+			b, _ := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			sb.WriteByte(byte(b))
+			i += 2
+			continue
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
@@ -0,0 +1,108 @@
+package wikilink
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileURLResolver(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	base := fileURL(filepath.ToSlash(dir)).String()
+
+	tests := []struct {
+		desc     string
+		resolver *FileURLResolver
+		node     *Node
+		want     string
+	}{
+		{
+			desc:     "note",
+			resolver: &FileURLResolver{Dir: dir},
+			node:     &Node{Target: []byte("Foo bar"), Fragment: []byte("Baz qux")},
+			want:     base + "/Foo%20bar.html#Baz%20qux",
+		},
+		{
+			desc:     "asset",
+			resolver: &FileURLResolver{Dir: dir},
+			node:     &Node{Target: []byte("img/cat.png"), Embed: true},
+			want:     base + "/img/cat.png",
+		},
+		{
+			desc:     "special characters",
+			resolver: &FileURLResolver{Dir: dir},
+			node:     &Node{Target: []byte("C# & 100%")},
+			want:     base + "/C%23%20&%20100%25.html",
+		},
+		{
+			desc:     "pretty",
+			resolver: &FileURLResolver{Dir: dir, Next: NewRootResolver("/")},
+			node:     &Node{Target: []byte("notes/Foo")},
+			want:     base + "/notes/Foo/index.html",
+		},
+		{
+			desc:     "fragment only",
+			resolver: &FileURLResolver{Dir: dir},
+			node:     &Node{Fragment: []byte("Foo")},
+			want:     "#Foo",
+		},
+		{
+			desc: "URL",
+			resolver: &FileURLResolver{Dir: dir, Next: resolverFunc(func(*Node) ([]byte, error) {
+				return []byte("https://example.com/a#b"), nil
+			})},
+			node: &Node{Target: []byte("a")},
+			want: "https://example.com/a#b",
+		},
+		{
+			desc:     "unresolved",
+			resolver: &FileURLResolver{Dir: dir, Next: resolverFunc(noopResolver)},
+			node:     &Node{Target: []byte("a")},
+			want:     "",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.resolver.ResolveWikilink(tt.node)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestFileURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give string
+		want string
+	}{
+		{give: "/home/me/a b.html", want: "file:///home/me/a%20b.html"},
+		{give: "C:/Users/me/a.html", want: "file:///C:/Users/me/a.html"},
+		{give: "//server/share/a.html", want: "file://server/share/a.html"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, fileURL(tt.give).String())
+		})
+	}
+}
+
+func TestFileURLResolver_Private(t *testing.T) {
+	t.Parallel()
+
+	r := &FileURLResolver{Dir: "preview", Next: privateResolver}
+	assertNotLinked(t, r, "[[Foo]]")
+}
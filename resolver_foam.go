@@ -0,0 +1,42 @@
+package wikilink
+
+import (
+	"path"
+	"strings"
+)
+
+// NewFoamResolver returns a resolver that resolves wikilinks the way Foam
+// does for notes authored in VS Code.
+//
+// Notes are identified by their file names without the ".md" extension.
+// Targets and fragments are matched case-insensitively by slugging them:
+// lowercased, with runs of spaces and punctuation replaced by a "-".
+// Targets with other extensions, like images, are left as-is.
+//
+//	[[My Note]]          // => "my-note"
+//	[[my-note.md#Ideas]] // => "my-note#ideas"
+//	[[Projects/Go Tips]] // => "projects/go-tips"
+//	[[diagram.png]]      // => "diagram.png"
+//
+// This is equivalent to a zero SlugResolver.
+// Use a SlugResolver with Slugify set to customize how notes are slugged.
+func NewFoamResolver() Resolver {
+	return foamResolver{}
+}
+
+type foamResolver struct{}
+
+func (foamResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	b := DestBuilder{Path: n.Target, Query: n.Query}
+	if len(n.Target) > 0 {
+		target := string(n.Target)
+		switch ext := path.Ext(target); ext {
+		case "", ".md":
+			b.Path = []byte(slugifyPath(strings.TrimSuffix(target, ext)))
+		}
+	}
+	if len(n.Fragment) > 0 {
+		b.Fragment = []byte(slugify(string(n.Fragment)))
+	}
+	return b.Build(), nil
+}
@@ -0,0 +1,41 @@
+package wikilink
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFoamResolver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		target   string
+		fragment string
+		want     string
+	}{
+		{target: "My Note", want: "my-note"},
+		{target: "my-note", want: "my-note"},
+		{target: "MY NOTE.md", fragment: "Big Ideas", want: "my-note#big-ideas"},
+		{target: "Projects/Go Tips", want: "projects/go-tips"},
+		{target: "diagram.png", want: "diagram.png"},
+		{fragment: "Some Heading", want: "#some-heading"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		name := fmt.Sprintf("%v#%v", tt.target, tt.fragment)
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewFoamResolver().ResolveWikilink(&Node{
+				Target:   []byte(tt.target),
+				Fragment: []byte(tt.fragment),
+			})
+			require.NoError(t, err, "resolve failed")
+			assert.Equal(t, tt.want, string(got), "result mismatch")
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package wikilink
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+// HeadingResolver resolves wikilinks to headings by their text alone,
+// for vaults organized as a few long pages.
+//
+// When the target of a wikilink without a fragment matches no page
+// in FS but matches exactly one heading in one of its pages,
+// the wikilink is resolved with Next as a link to that heading.
+// All other wikilinks are resolved with Next as-is.
+//
+//	// guide.md contains "## Installation"
+//	r := &wikilink.HeadingResolver{FS: os.DirFS("vault")}
+//	[[Installation]] // => "guide.html#Installation"
+//	[[guide]]        // => "guide.html"
+//
+// Like Obsidian, pages and headings are matched case-insensitively,
+// and pages match by their path or by their name alone.
+// Headings that appear more than once in the vault are ambiguous
+// and aren't matched.
+//
+// FS is scanned the first time it's needed.
+// Create a new HeadingResolver to pick up changes to it.
+type HeadingResolver struct {
+	// FS holds the Markdown files of the vault.
+	FS fs.FS
+
+	// Next resolves the wikilinks.
+	//
+	// Defaults to DefaultResolver.
+	Next Resolver
+
+	once     sync.Once
+	err      error
+	pages    map[string]struct{} // lowercase paths and names, without ".md"
+	headings map[string][]headingRef
+}
+
+// headingRef is a heading in a page of the vault.
+type headingRef struct {
+	Page string // path without ".md"
+	Text string
+}
+
+var _ DetailedResolver = (*HeadingResolver)(nil)
+
+// ResolveWikilink resolves the provided wikilink with Next,
+// pointing it at a heading if its target only matches a heading.
+func (r *HeadingResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	res, err := r.ResolveWikilinkDetails(n)
+	return res.Destination, err
+}
+
+// ResolveWikilinkDetails is like ResolveWikilink,
+// but it reports the Resolution of Next.
+func (r *HeadingResolver) ResolveWikilinkDetails(n *Node) (Resolution, error) {
+	next := r.Next
+	if next == nil {
+		next = DefaultResolver
+	}
+
+	target := string(n.Target)
+	if n.Embed || len(target) == 0 || len(n.Fragment) > 0 || len(path.Ext(target)) > 0 {
+		return ResolveDetails(next, n)
+	}
+
+	r.once.Do(r.scan)
+	if r.err != nil {
+		return Resolution{}, r.err
+	}
+
+	key := strings.ToLower(target)
+	if _, ok := r.pages[key]; ok {
+		return ResolveDetails(next, n)
+	}
+
+	refs := r.headings[key]
+	switch len(refs) {
+	case 0:
+		n.Tracef("no page or heading %q", target)
+		return ResolveDetails(next, n)
+	case 1:
+		n.Tracef("matched heading %q in %q", refs[0].Text, refs[0].Page)
+		h := n.withTarget([]byte(refs[0].Page))
+		h.Fragment = []byte(refs[0].Text)
+		return ResolveDetails(next, h)
+	default:
+		n.Tracef("heading %q is in %d places", target, len(refs))
+		return ResolveDetails(next, n)
+	}
+}
+
+// scan records the pages of the vault and their headings.
+func (r *HeadingResolver) scan() {
+	r.pages = make(map[string]struct{})
+	r.headings = make(map[string][]headingRef)
+	r.err = fs.WalkDir(r.FS, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(path.Ext(p), ".md") {
+			return nil
+		}
+
+		page := p[:len(p)-len(".md")]
+		r.pages[strings.ToLower(page)] = struct{}{}
+		r.pages[strings.ToLower(path.Base(page))] = struct{}{}
+
+		src, err := fs.ReadFile(r.FS, p)
+		if err != nil {
+			return err
+		}
+		for _, h := range markdownHeadings(src) {
+			key := strings.ToLower(h)
+			r.headings[key] = append(r.headings[key], headingRef{Page: page, Text: h})
+		}
+		return nil
+	})
+	if r.err != nil {
+		r.err = fmt.Errorf("scan headings: %w", r.err)
+	}
+}
+
+// markdownHeadings returns the text of the ATX headings in src,
+// like "## Installation", skipping those inside fenced code blocks.
+func markdownHeadings(src []byte) []string {
+	var headings []string
+	scanHeadings(src, func(_ int, text string) bool {
+		headings = append(headings, text)
+		return true
+	})
+	return headings
+}
+
+// firstTitleHeading returns the text of the first level-1 ATX heading
+// in src, like "# Installation", or "" if there isn't one.
+func firstTitleHeading(src []byte) (title string) {
+	scanHeadings(src, func(level int, text string) bool {
+		if level == 1 {
+			title = text
+			return false
+		}
+		return true
+	})
+	return title
+}
+
+// scanHeadings calls fn with the level and text of each ATX heading
+// in src, skipping those inside fenced code blocks,
+// until fn returns false.
+func scanHeadings(src []byte, fn func(level int, text string) bool) {
+	var fence []byte // opening fence of the current code block, if any
+	s := bufio.NewScanner(bytes.NewReader(src))
+	for s.Scan() {
+		line := bytes.TrimLeft(s.Bytes(), " ")
+		if len(s.Bytes())-len(line) > 3 {
+			continue // indented code
+		}
+
+		if f := codeFence(line); len(f) > 0 {
+			switch {
+			case fence == nil:
+				fence = append([]byte(nil), f...)
+			case f[0] == fence[0] && len(f) >= len(fence) && len(bytes.TrimSpace(line[len(f):])) == 0:
+				fence = nil
+			}
+			continue
+		}
+		if fence != nil {
+			continue
+		}
+
+		level := 0
+		for level < len(line) && line[level] == '#' {
+			level++
+		}
+		if level == 0 || level > 6 || (level < len(line) && line[level] != ' ' && line[level] != '\t') {
+			continue
+		}
+		text := bytes.TrimSpace(line[level:])
+		if closed := bytes.TrimRight(text, "#"); len(closed) == 0 || closed[len(closed)-1] == ' ' || closed[len(closed)-1] == '\t' {
+			text = bytes.TrimSpace(closed) // "## Foo ##"
+		}
+		if len(text) > 0 && !fn(level, string(text)) {
+			return
+		}
+	}
+}
+
+// codeFence returns the fence at the start of line,
+// like "```" or "~~~~", or nil if it doesn't start with one.
+func codeFence(line []byte) []byte {
+	if len(line) == 0 || (line[0] != '`' && line[0] != '~') {
+		return nil
+	}
+	i := 0
+	for i < len(line) && line[i] == line[0] {
+		i++
+	}
+	if i < 3 {
+		return nil
+	}
+	return line[:i]
+}
@@ -0,0 +1,102 @@
+package wikilink
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeadingResolver(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"guide.md":     {Data: []byte("# Guide\n\n## Installation\n\n## Usage ##\n\n```\n# Not a heading\n```\n")},
+		"notes/faq.md": {Data: []byte("# FAQ\n\n### Usage\n\n## C#\n")},
+		"Setup.md":     {Data: []byte("Set up.\n")},
+		"other.md":     {Data: []byte("## Setup\n")},
+	}
+
+	tests := []struct {
+		desc     string
+		target   string
+		fragment string
+		embed    bool
+		want     string
+	}{
+		{desc: "heading", target: "Installation", want: "guide.html#Installation"},
+		{desc: "case-insensitive", target: "installation", want: "guide.html#Installation"},
+		{desc: "in a folder", target: "C#", want: "notes/faq.html#C#"},
+		{desc: "closed heading is ambiguous", target: "Usage", want: "Usage.html"},
+		{desc: "page", target: "guide", want: "guide.html"},
+		{desc: "page by name", target: "FAQ", want: "FAQ.html"},
+		{desc: "page wins", target: "setup", want: "setup.html"},
+		{desc: "code block", target: "Not a heading", want: "Not a heading.html"},
+		{desc: "unknown", target: "Nope", want: "Nope.html"},
+		{desc: "fragment", target: "Installation", fragment: "Foo", want: "Installation.html#Foo"},
+		{desc: "embed", target: "Installation", embed: true, want: "Installation.html"},
+		{desc: "extension", target: "Installation.pdf", want: "Installation.pdf"},
+	}
+
+	r := &HeadingResolver{FS: files}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := r.ResolveWikilink(&Node{
+				Target:   []byte(tt.target),
+				Fragment: []byte(tt.fragment),
+				Embed:    tt.embed,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestHeadingResolver_Error(t *testing.T) {
+	t.Parallel()
+
+	r := &HeadingResolver{FS: errFS{fs.ErrPermission}}
+	_, err := r.ResolveWikilink(&Node{Target: []byte("Foo")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "scan headings")
+	assert.ErrorIs(t, err, fs.ErrPermission)
+}
+
+// errFS is an fs.FS that fails to open any file.
+type errFS struct{ err error }
+
+func (f errFS) Open(string) (fs.File, error) {
+	return nil, f.err
+}
+
+func TestMarkdownHeadings(t *testing.T) {
+	t.Parallel()
+
+	src := "# One\n" +
+		"  ## Two ##  \n" +
+		"    # Indented code\n" +
+		"#NotAHeading\n" +
+		"####### Seven\n" +
+		"~~~~md\n" +
+		"# Fenced\n" +
+		"~~~ not closed\n" +
+		"~~~~\n" +
+		"###### Six\n" +
+		"## C# #\n"
+	assert.Equal(t, []string{"One", "Two", "Six", "C#"}, markdownHeadings([]byte(src)))
+}
+
+func TestHeadingResolver_Private(t *testing.T) {
+	t.Parallel()
+
+	r := &HeadingResolver{
+		FS:   fstest.MapFS{"guide.md": {Data: []byte("## Installation\n")}},
+		Next: privateResolver,
+	}
+	assertNotLinked(t, r, "[[Installation]] [[guide]]")
+}
@@ -0,0 +1,194 @@
+package wikilink
+
+import (
+	"log/slog"
+	"path"
+	"strings"
+)
+
+// IndexResolver resolves wikilinks by looking up their targets in an
+// Index, the way Obsidian does, so that [[Foo]] links to notes/Foo.md
+// wherever it is in the vault.
+// The file found in the index is resolved with Next.
+//
+//	idx := new(wikilink.Index)
+//	err := idx.AddFS(os.DirFS("vault"))
+//	r := &wikilink.IndexResolver{Index: idx}
+//	[[foo]]       // => "notes/Foo.html"
+//	[[Old name]]  // => "notes/Foo.html" if notes/Foo.md has the alias "Old name"
+//	![[cat.png]]  // => "attachments/cat.png"
+//
+// Targets that match more than one file fail with an AmbiguousTargetError
+// unless one of them matches by its full path.
+// Targets that match no file are resolved with Next as-is,
+// or fail with a TargetNotFoundError if Strict is set,
+// or an InvalidTargetError if they point outside the vault, like [[../Foo]].
+type IndexResolver struct {
+	// Index of the files in the vault.
+	Index *Index
+
+	// Next resolves the wikilinks, with the IDs of the files in the index
+	// as their targets.
+	//
+	// Defaults to DefaultResolver.
+	Next Resolver
+
+	// Strict fails wikilinks to targets that aren't in the index
+	// with a TargetNotFoundError instead of resolving them as-is,
+	// or with an InvalidTargetError if they point outside the vault.
+	Strict bool
+
+	// Suggestions is the number of files with names similar to the
+	// target to suggest in the TargetNotFoundError of Strict,
+	// so that build failures say how to fix the wikilink.
+	//
+	//	"Instalation": target not found: did you mean guide/Installation?
+	//
+	// Defaults to none.
+	Suggestions int
+
+	// Fuzzy, if non-zero, resolves targets that aren't in the index
+	// to the file with the most similar name,
+	// so that minor typos, like [[Instalation]], still resolve.
+	// It's the minimum similarity, from 0 to 1,
+	// of a name to the target for the name to match:
+	// 1 minus their edit distance divided by the length of the longer one.
+	//
+	//	Fuzzy: 0.8 // [[Instalation]] => "Installation" (0.92)
+	//
+	// Targets with two equally similar files don't match.
+	// Every fuzzy match is reported to Logger
+	// so that authors can fix the typos.
+	Fuzzy float64
+
+	// Logger, if set, receives a warning for every fuzzy match.
+	Logger *slog.Logger
+
+	// Titles sets the title attribute of links to the title of the note
+	// they resolve to, as reported by Index.Title,
+	// unless Next reports a title itself.
+	//
+	//	[[Foo]] // => <a href="notes/Foo.html" title="Foo: an introduction">
+	Titles bool
+}
+
+var _ DetailedResolver = (*IndexResolver)(nil)
+
+// ResolveWikilink resolves the provided wikilink to the file in the index
+// that its target refers to.
+func (r *IndexResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	res, err := r.ResolveWikilinkDetails(n)
+	return res.Destination, err
+}
+
+// ResolveWikilinkDetails is like ResolveWikilink,
+// but it also reports the title of the note if Titles is set.
+func (r *IndexResolver) ResolveWikilinkDetails(n *Node) (Resolution, error) {
+	next := r.Next
+	if next == nil {
+		next = DefaultResolver
+	}
+	if len(n.Target) == 0 || r.Index == nil {
+		return ResolveDetails(next, n)
+	}
+	if err := r.Index.Load(); err != nil {
+		return Resolution{}, err
+	}
+
+	target := string(n.Target)
+	ids := lookupTarget(r.Index, target)
+	if len(ids) == 0 && r.Fuzzy > 0 {
+		if id, ok := r.fuzzy(n); ok {
+			ids = []string{id}
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		n.Tracef("%q is not in the index", target)
+		if r.Strict {
+			if outsideVault(target) {
+				return Resolution{}, &InvalidTargetError{Node: n, Reason: "outside the vault"}
+			}
+			return Resolution{}, &TargetNotFoundError{Node: n, Suggestions: r.suggest(target)}
+		}
+		return ResolveDetails(next, n)
+	case 1:
+		n.Tracef("indexed as %q", ids[0])
+		res, err := ResolveDetails(next, n.withTarget([]byte(ids[0])))
+		if err == nil && r.Titles && len(res.Title) == 0 {
+			res.Title = r.Index.Title(ids[0])
+		}
+		return res, err
+	default:
+		return Resolution{}, &AmbiguousTargetError{Node: n, Candidates: ids}
+	}
+}
+
+// lookupTarget looks up the target of a wikilink in idx.
+// If it matches more than one file, a file that it matches
+// by its full path wins over those it matches by name.
+func lookupTarget(idx *Index, target string) []string {
+	ids := idx.Lookup(target)
+	if len(ids) > 1 {
+		exact := strings.TrimSuffix(target, ".md")
+		for _, id := range ids {
+			if strings.EqualFold(id, exact) {
+				return []string{id}
+			}
+		}
+	}
+	return ids
+}
+
+// fuzzy returns the ID of the file with the name most similar to the
+// target of n, if it's similar enough and there's only one such file.
+func (r *IndexResolver) fuzzy(n *Node) (string, bool) {
+	matches := r.Index.closest(string(n.Target), 2)
+	if len(matches) == 0 || matches[0].Similarity < r.Fuzzy {
+		return "", false
+	}
+	if len(matches) > 1 && matches[1].Similarity == matches[0].Similarity {
+		n.Tracef("fuzzy match for %q is ambiguous", n.Target)
+		return "", false
+	}
+
+	m := matches[0]
+	n.Tracef("fuzzy matched %q (%.2f)", m.Name, m.Similarity)
+	if r.Logger != nil {
+		attrs := make([]slog.Attr, 0, 4)
+		if len(n.source) > 0 {
+			attrs = append(attrs, slog.String("source", n.source))
+		}
+		attrs = append(attrs,
+			slog.String("target", string(n.Target)),
+			slog.String("match", m.ID),
+			slog.Float64("similarity", m.Similarity),
+		)
+		r.Logger.LogAttrs(n.Context(), slog.LevelWarn, "wikilink fuzzy match", attrs...)
+	}
+	return m.ID, true
+}
+
+// suggest returns the IDs of up to Suggestions files
+// with names similar to target, most similar first.
+func (r *IndexResolver) suggest(target string) []string {
+	if r.Suggestions <= 0 {
+		return nil
+	}
+
+	var ids []string
+	for _, m := range r.Index.closest(target, r.Suggestions) {
+		if m.Similarity > 0 {
+			ids = append(ids, m.ID)
+		}
+	}
+	return ids
+}
+
+// outsideVault reports whether target is a path
+// that leaves the root of the vault, like "../Foo".
+func outsideVault(target string) bool {
+	p := path.Clean(target)
+	return p == ".." || strings.HasPrefix(p, "../")
+}
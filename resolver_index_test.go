@@ -0,0 +1,268 @@
+package wikilink
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestIndexResolver(t *testing.T) {
+	t.Parallel()
+
+	idx := new(Index)
+	idx.Add("notes/Foo")
+	idx.Add("archive/Foo")
+	idx.Add("guide/Installation")
+	idx.Add("guide/Instructions")
+	idx.Add("attachments/cat.png")
+
+	tests := []struct {
+		desc     string
+		resolver *IndexResolver
+		node     *Node
+		want     string
+		wantErr  error
+	}{
+		{
+			desc:     "name",
+			resolver: &IndexResolver{Index: idx},
+			node:     &Node{Target: []byte("installation"), Fragment: []byte("Linux")},
+			want:     "guide/Installation.html#Linux",
+		},
+		{
+			desc:     "asset",
+			resolver: &IndexResolver{Index: idx, Next: NewRootResolver("/")},
+			node:     &Node{Target: []byte("cat.png"), Embed: true},
+			want:     "/attachments/cat.png",
+		},
+		{
+			desc:     "full path",
+			resolver: &IndexResolver{Index: idx},
+			node:     &Node{Target: []byte("notes/Foo")},
+			want:     "notes/Foo.html",
+		},
+		{
+			desc:     "ambiguous",
+			resolver: &IndexResolver{Index: idx},
+			node:     &Node{Target: []byte("Foo")},
+			wantErr:  ErrAmbiguousTarget,
+		},
+		{
+			desc:     "missing",
+			resolver: &IndexResolver{Index: idx},
+			node:     &Node{Target: []byte("Nope")},
+			want:     "Nope.html",
+		},
+		{
+			desc:     "missing/strict",
+			resolver: &IndexResolver{Index: idx, Strict: true},
+			node:     &Node{Target: []byte("Nope")},
+			wantErr:  ErrTargetNotFound,
+		},
+		{
+			desc:     "outside/strict",
+			resolver: &IndexResolver{Index: idx, Strict: true},
+			node:     &Node{Target: []byte("notes/../../Foo")},
+			wantErr:  ErrInvalidTarget,
+		},
+		{
+			desc:     "outside",
+			resolver: &IndexResolver{Index: idx},
+			node:     &Node{Target: []byte("../Foo")},
+			want:     "../Foo.html",
+		},
+		{
+			desc:     "fragment only",
+			resolver: &IndexResolver{Index: idx, Strict: true},
+			node:     &Node{Fragment: []byte("Foo")},
+			want:     "#Foo",
+		},
+		{
+			desc:     "fuzzy",
+			resolver: &IndexResolver{Index: idx, Fuzzy: 0.8},
+			node:     &Node{Target: []byte("Instalation")},
+			want:     "guide/Installation.html",
+		},
+		{
+			desc:     "fuzzy/below threshold",
+			resolver: &IndexResolver{Index: idx, Fuzzy: 0.8, Strict: true},
+			node:     &Node{Target: []byte("Install")},
+			wantErr:  ErrTargetNotFound,
+		},
+		{
+			desc:     "fuzzy/ambiguous",
+			resolver: &IndexResolver{Index: idx, Fuzzy: 0.5, Strict: true},
+			node:     &Node{Target: []byte("Fox")},
+			wantErr:  ErrTargetNotFound,
+		},
+		{
+			desc:     "no index",
+			resolver: &IndexResolver{},
+			node:     &Node{Target: []byte("Foo")},
+			want:     "Foo.html",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.resolver.ResolveWikilink(tt.node)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestIndexResolver_Titles(t *testing.T) {
+	t.Parallel()
+
+	idx := new(Index)
+	require.NoError(t, idx.AddFS(fstest.MapFS{
+		"notes/Foo.md": {Data: []byte("# Foo & friends\n")},
+		"Bar.md":       {},
+	}))
+
+	tests := []struct {
+		desc     string
+		resolver *IndexResolver
+		give     string
+		want     string
+	}{
+		{
+			desc:     "title",
+			resolver: &IndexResolver{Index: idx, Titles: true},
+			give:     "[[Foo]]",
+			want:     `<p><a href="notes/Foo.html" title="Foo &amp; friends">Foo</a></p>`,
+		},
+		{
+			desc:     "no title",
+			resolver: &IndexResolver{Index: idx, Titles: true},
+			give:     "[[Bar]]",
+			want:     `<p><a href="Bar.html">Bar</a></p>`,
+		},
+		{
+			desc:     "disabled",
+			resolver: &IndexResolver{Index: idx},
+			give:     "[[Foo]]",
+			want:     `<p><a href="notes/Foo.html">Foo</a></p>`,
+		},
+		{
+			desc: "next title wins",
+			resolver: &IndexResolver{
+				Index:  idx,
+				Titles: true,
+				Next: detailedResolverFunc(func(n *Node) (Resolution, error) {
+					return Resolution{Destination: []byte("/foo/"), Title: "From next"}, nil
+				}),
+			},
+			give: "[[Foo]]",
+			want: `<p><a href="/foo/" title="From next">Foo</a></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			md := goldmark.New(goldmark.WithExtensions(&Extender{Resolver: tt.resolver}))
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want, strings.TrimSpace(buf.String()))
+		})
+	}
+}
+
+func TestIndexResolver_Suggestions(t *testing.T) {
+	t.Parallel()
+
+	idx := new(Index)
+	idx.Add("guide/Installation")
+	idx.Add("guide/Instructions")
+	idx.Add("About")
+
+	tests := []struct {
+		desc        string
+		suggestions int
+		target      string
+		want        []string
+	}{
+		{desc: "disabled", target: "Instalation"},
+		{
+			desc:        "one",
+			suggestions: 1,
+			target:      "Instalation",
+			want:        []string{"guide/Installation"},
+		},
+		{
+			desc:        "many",
+			suggestions: 5,
+			target:      "Instalation",
+			want:        []string{"guide/Installation", "guide/Instructions", "About"},
+		},
+		{
+			desc:        "nothing similar",
+			suggestions: 5,
+			target:      "xyz",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			r := &IndexResolver{Index: idx, Strict: true, Suggestions: tt.suggestions}
+			_, err := r.ResolveWikilink(&Node{Target: []byte(tt.target)})
+
+			var notFound *TargetNotFoundError
+			require.ErrorAs(t, err, &notFound)
+			assert.Equal(t, tt.want, notFound.Suggestions)
+		})
+	}
+
+	t.Run("message", func(t *testing.T) {
+		t.Parallel()
+
+		r := &IndexResolver{Index: idx, Strict: true, Suggestions: 1}
+		_, err := r.ResolveWikilink(&Node{Target: []byte("Instalation")})
+		assert.EqualError(t, err, `"Instalation": target not found: did you mean guide/Installation?`)
+	})
+}
+
+func TestIndexResolver_FuzzyLogger(t *testing.T) {
+	t.Parallel()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	idx := new(Index)
+	idx.Add("Installation")
+	r := &IndexResolver{Index: idx, Fuzzy: 0.8, Logger: logger}
+
+	got, err := r.ResolveWikilink(&Node{Target: []byte("Instalation"), source: "a.md"})
+	require.NoError(t, err)
+	assert.Equal(t, "Installation.html", string(got))
+	assert.Equal(t,
+		`level=WARN msg="wikilink fuzzy match" source=a.md target=Instalation match=Installation similarity=0.9166666666666666`+"\n",
+		logs.String())
+}
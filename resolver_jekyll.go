@@ -0,0 +1,167 @@
+package wikilink
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// NewJekyllResolver returns a resolver that resolves wikilinks to Jekyll posts
+// using the given permalink template.
+//
+// Targets that name a post, with or without the "_posts/" directory
+// and ".md" extension, are resolved with the template.
+// Directories above "_posts" are the categories of the post.
+// All other targets are resolved with next.
+//
+//	r := NewJekyllResolver("/:categories/:year/:month/:day/:title/", DefaultResolver)
+//	[[2024-01-15-hello-world]]                // => "/2024/01/15/hello-world/"
+//	[[blog/_posts/2024-01-15-hello-world.md]] // => "/blog/2024/01/15/hello-world/"
+//	[[about]]                                 // => "about.html"
+//
+// See JekyllResolver for the placeholders the template supports.
+func NewJekyllResolver(permalink string, next Resolver) *JekyllResolver {
+	return &JekyllResolver{
+		Permalink: permalink,
+		Next:      next,
+	}
+}
+
+// JekyllResolver resolves wikilinks to Jekyll posts
+// using a permalink template.
+// See NewJekyllResolver.
+type JekyllResolver struct {
+	// Permalink is the permalink template of posts.
+	// It supports the following placeholders:
+	// :categories, :year, :short_year, :month, :i_month, :day, :i_day,
+	// :y_day, :title, :slug, and :output_ext.
+	// It may also be one of Jekyll's built-in styles:
+	// "date", "pretty", "ordinal", or "none".
+	Permalink string
+
+	// Slugify turns the titles of posts into the :slug placeholder.
+	// Use this to match the slugs of your Jekyll site.
+	//
+	// Defaults to the algorithm of a zero SlugResolver.
+	Slugify func(string) string
+
+	// Next resolves wikilinks that aren't to Jekyll posts.
+	//
+	// Defaults to DefaultResolver.
+	Next Resolver
+}
+
+var _jekyllStyles = map[string]string{
+	"date":    "/:categories/:year/:month/:day/:title:output_ext",
+	"pretty":  "/:categories/:year/:month/:day/:title/",
+	"ordinal": "/:categories/:year/:y_day/:title:output_ext",
+	"none":    "/:categories/:title:output_ext",
+}
+
+// _jekyllPost matches the names of Jekyll post files.
+var _jekyllPost = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})-(.+)$`)
+
+// _jekyllPlaceholder matches placeholders in Jekyll permalink templates.
+var _jekyllPlaceholder = regexp.MustCompile(`:[a-z_]+`)
+
+var _ DetailedResolver = (*JekyllResolver)(nil)
+
+// ResolveWikilink resolves the provided wikilink to the permalink
+// of a Jekyll post, or with Next if it isn't one.
+func (r *JekyllResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	res, err := r.ResolveWikilinkDetails(n)
+	return res.Destination, err
+}
+
+// ResolveWikilinkDetails is like ResolveWikilink,
+// but it reports the Resolution of Next
+// for the wikilinks it resolves with Next.
+func (r *JekyllResolver) ResolveWikilinkDetails(n *Node) (Resolution, error) {
+	target := strings.TrimSuffix(string(n.Target), ".md")
+	dir, name := path.Split(target)
+	m := _jekyllPost.FindStringSubmatch(name)
+	if m == nil {
+		n.Tracef("not a Jekyll post: %q", n.Target)
+		next := r.Next
+		if next == nil {
+			next = DefaultResolver
+		}
+		return ResolveDetails(next, n)
+	}
+	n.Tracef("matched Jekyll post %q", name)
+
+	var categories []string
+	for _, c := range splitPath(dir) {
+		if c != "_posts" {
+			categories = append(categories, c)
+		}
+	}
+
+	permalink := r.Permalink
+	if style, ok := _jekyllStyles[permalink]; ok {
+		permalink = style
+	}
+	slug := r.Slugify
+	if slug == nil {
+		slug = slugify
+	}
+
+	year, month, day, title := m[1], m[2], m[3], m[4]
+	dest := _jekyllPlaceholder.ReplaceAllStringFunc(permalink, func(p string) string {
+		switch p {
+		case ":categories":
+			return strings.Join(categories, "/")
+		case ":year":
+			return year
+		case ":short_year":
+			return year[2:]
+		case ":month":
+			return month
+		case ":i_month":
+			return strings.TrimPrefix(month, "0")
+		case ":day":
+			return day
+		case ":i_day":
+			return strings.TrimPrefix(day, "0")
+		case ":y_day":
+			return jekyllYearDay(year, month, day)
+		case ":title":
+			return title
+		case ":slug":
+			return slug(title)
+		case ":output_ext":
+			return ".html"
+		default:
+			return p
+		}
+	})
+	dest = _duplicateSlashes.ReplaceAllString(dest, "/")
+
+	return Resolution{
+		Destination: DestBuilder{
+			Path:     []byte(dest),
+			Query:    n.Query,
+			Fragment: n.Fragment,
+		}.Build(),
+	}, nil
+}
+
+// _duplicateSlashes matches runs of "/" left behind by empty placeholders.
+var _duplicateSlashes = regexp.MustCompile(`/{2,}`)
+
+// jekyllYearDay returns the 3-digit day of the year for the given date.
+func jekyllYearDay(year, month, day string) string {
+	y, _ := strconv.Atoi(year)
+	m, _ := strconv.Atoi(month)
+	d, _ := strconv.Atoi(day)
+
+	days := []int{31, 28, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+	if y%4 == 0 && (y%100 != 0 || y%400 == 0) {
+		days[1] = 29
+	}
+	for i := 0; i < m-1 && i < len(days); i++ {
+		d += days[i]
+	}
+	return strconv.Itoa(1000 + d)[1:]
+}
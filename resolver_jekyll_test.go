@@ -0,0 +1,96 @@
+package wikilink
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJekyllResolver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		permalink string
+		target    string
+		fragment  string
+		want      string
+	}{
+		{
+			permalink: "/:categories/:year/:month/:day/:title/",
+			target:    "2024-01-15-hello-world",
+			want:      "/2024/01/15/hello-world/",
+		},
+		{
+			permalink: "/:categories/:year/:month/:day/:title/",
+			target:    "blog/_posts/2024-01-15-hello-world.md",
+			fragment:  "intro",
+			want:      "/blog/2024/01/15/hello-world/#intro",
+		},
+		{
+			permalink: "date",
+			target:    "_posts/2024-01-15-hello-world",
+			want:      "/2024/01/15/hello-world.html",
+		},
+		{
+			permalink: "none",
+			target:    "a/b/_posts/2024-01-15-hello-world",
+			want:      "/a/b/hello-world.html",
+		},
+		{
+			permalink: "ordinal",
+			target:    "2024-03-01-leap",
+			want:      "/2024/061/leap.html",
+		},
+		{
+			permalink: "/:short_year/:i_month/:i_day/:slug",
+			target:    "2024-01-05-Hello World",
+			want:      "/24/1/5/hello-world",
+		},
+		{
+			permalink: "pretty",
+			target:    "about",
+			want:      "about.html",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		name := fmt.Sprintf("%v %v#%v", tt.permalink, tt.target, tt.fragment)
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewJekyllResolver(tt.permalink, DefaultResolver).ResolveWikilink(&Node{
+				Target:   []byte(tt.target),
+				Fragment: []byte(tt.fragment),
+			})
+			require.NoError(t, err, "resolve failed")
+			assert.Equal(t, tt.want, string(got), "result mismatch")
+		})
+	}
+}
+
+func TestJekyllResolver_Private(t *testing.T) {
+	t.Parallel()
+
+	r := NewJekyllResolver("pretty", privateResolver)
+	assertNotLinked(t, r, "[[about]]")
+}
+
+func TestJekyllResolver_Slugify(t *testing.T) {
+	t.Parallel()
+
+	r := &JekyllResolver{
+		Permalink: "/:year/:slug/",
+		Slugify:   strings.ToUpper,
+	}
+	got, err := r.ResolveWikilink(&Node{Target: []byte("2024-01-15-hello world")})
+	require.NoError(t, err)
+	assert.Equal(t, "/2024/HELLO WORLD/", string(got))
+
+	got, err = r.ResolveWikilink(&Node{Target: []byte("about")})
+	require.NoError(t, err)
+	assert.Equal(t, "about.html", string(got), "Next defaults to DefaultResolver")
+}
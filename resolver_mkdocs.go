@@ -0,0 +1,109 @@
+package wikilink
+
+import (
+	"path"
+	"strings"
+)
+
+// NewMkDocsResolver returns a resolver that resolves wikilinks to the URLs
+// generated by MkDocs with use_directory_urls enabled, where every page is
+// served from its own directory.
+//
+// Targets are paths to documents relative to the docs directory,
+// with or without their ".md" extension.
+// Each page is served from a directory named after it,
+// and index pages are served from their parent directory.
+//
+//	page.md      => page/
+//	dir/page.md  => dir/page/
+//	dir/index.md => dir/
+//
+// Destinations are relative to the URL of the page containing the link
+// as recorded by SetContextSource, so links between pages work
+// regardless of where the site is deployed.
+//
+//	// Inside "dir/a.md":
+//	[[dir/b]]       // => "../b/"
+//	[[index]]       // => "../../"
+//	[[img/cat.png]] // => "../../img/cat.png"
+//
+// Destinations are relative to the site root
+// if the source document is unknown.
+func NewMkDocsResolver() Resolver {
+	return mkdocsResolver{}
+}
+
+type mkdocsResolver struct{}
+
+func (mkdocsResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	b := DestBuilder{Query: n.Query, Fragment: n.Fragment}
+	if len(n.Target) > 0 {
+		var from string
+		if src := n.Source(); len(src) > 0 {
+			from = mkdocsURL(src)
+		}
+		b.Path = []byte(relativeURL(from, mkdocsURL(string(n.Target))))
+	}
+	return b.Build(), nil
+}
+
+// mkdocsURL returns the URL of the given document relative to the site
+// root with use_directory_urls enabled.
+// Pages get a trailing "/". Other files are returned as-is.
+func mkdocsURL(doc string) string {
+	doc = strings.TrimPrefix(path.Clean("/"+doc), "/")
+	switch ext := path.Ext(doc); ext {
+	case "", ".md":
+		doc = strings.TrimSuffix(doc, ext)
+	default:
+		return doc
+	}
+
+	switch base := path.Base(doc); base {
+	case "index", "README":
+		doc = strings.TrimSuffix(doc, base)
+	default:
+		doc += "/"
+	}
+	return doc
+}
+
+// relativeURL returns a URL that refers to target relative to the
+// directory dir. Both must be relative to the same root.
+//
+//	relativeURL("a/", "a/b/") // => "b/"
+//	relativeURL("a/b/", "c/") // => "../../c/"
+//	relativeURL("a/", "a/")   // => "./"
+func relativeURL(dir, target string) string {
+	dirParts := splitPath(dir)
+	targetParts := splitPath(target)
+
+	var common int
+	for common < len(dirParts) && common < len(targetParts) &&
+		dirParts[common] == targetParts[common] {
+		common++
+	}
+
+	// A trailing file name in the target can't be a common directory.
+	if !strings.HasSuffix(target, "/") && common == len(targetParts) && common > 0 {
+		common--
+	}
+
+	var sb strings.Builder
+	for i := common; i < len(dirParts); i++ {
+		sb.WriteString("../")
+	}
+	sb.WriteString(strings.Join(targetParts[common:], "/"))
+	if strings.HasSuffix(target, "/") && len(targetParts) > common {
+		sb.WriteByte('/')
+	}
+	if sb.Len() == 0 {
+		return "./"
+	}
+	return sb.String()
+}
+
+// splitPath splits a "/"-separated path into its non-empty components.
+func splitPath(p string) []string {
+	return strings.FieldsFunc(p, func(r rune) bool { return r == '/' })
+}
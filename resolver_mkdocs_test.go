@@ -0,0 +1,51 @@
+package wikilink
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMkDocsResolver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		source   string
+		target   string
+		fragment string
+		want     string
+	}{
+		{target: "page", want: "page/"},
+		{target: "page.md", want: "page/"},
+		{target: "dir/index.md", want: "dir/"},
+		{target: "index", want: "./"},
+		{source: "index.md", target: "dir/page", want: "dir/page/"},
+		{source: "a.md", target: "b.md", want: "../b/"},
+		{source: "a.md", target: "a.md", fragment: "Foo", want: "./#Foo"},
+		{source: "dir/a.md", target: "dir/b", want: "../b/"},
+		{source: "dir/a.md", target: "index", want: "../../"},
+		{source: "dir/a.md", target: "img/cat.png", want: "../../img/cat.png"},
+		{source: "dir/index.md", target: "dir/b", want: "b/"},
+		{source: "dir/index.md", target: "dir/cat.png", want: "cat.png"},
+		{source: "dir/README.md", target: "other/README.md", want: "../other/"},
+		{source: "dir/a.md", fragment: "Foo", want: "#Foo"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		name := fmt.Sprintf("%v->%v#%v", tt.source, tt.target, tt.fragment)
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewMkDocsResolver().ResolveWikilink(&Node{
+				Target:   []byte(tt.target),
+				Fragment: []byte(tt.fragment),
+				source:   tt.source,
+			})
+			require.NoError(t, err, "resolve failed")
+			assert.Equal(t, tt.want, string(got), "result mismatch")
+		})
+	}
+}
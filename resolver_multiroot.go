@@ -0,0 +1,142 @@
+package wikilink
+
+import "strings"
+
+// VaultRoot is one of the vaults resolved by a MultiRootResolver.
+type VaultRoot struct {
+	// Index of the files in the vault.
+	Index *Index
+
+	// Prefix is added to the IDs of the files in the vault
+	// before they're resolved, so that each vault gets its own
+	// section of the site, like "work/" for /work/notes/Foo/.
+	//
+	// Wikilinks whose targets start with Prefix,
+	// like [[work/Foo]], look in this vault first.
+	//
+	// Defaults to none: the files are resolved as if they were
+	// at the root of the site.
+	Prefix string
+}
+
+// MultiRootResolver resolves wikilinks across several vaults
+// merged into one site, like notes/, work-vault/, and archive/,
+// looking up their targets in the Index of each vault in turn.
+// The first vault with a file for the target wins,
+// and the file is resolved with Next, with the Prefix of its vault.
+//
+//	r := &wikilink.MultiRootResolver{
+//		Roots: []wikilink.VaultRoot{
+//			{Index: notes},
+//			{Index: work, Prefix: "work/"},
+//			{Index: archive, Prefix: "archive/"},
+//		},
+//		Next: wikilink.NewRootResolver("/"),
+//	}
+//	[[Foo]]         // => "/Foo/" if notes has a Foo, else "/work/Foo/", ...
+//	[[archive/Foo]] // => "/archive/Foo/" if archive has a Foo
+//
+// Targets are looked up like IndexResolver does.
+// Targets that match more than one file in the winning vault fail
+// with an AmbiguousTargetError.
+// Targets that match no file in any vault are resolved with Next as-is,
+// or fail with a TargetNotFoundError if Strict is set,
+// or an InvalidTargetError if they point outside the vaults.
+type MultiRootResolver struct {
+	// Roots are the vaults in order of precedence.
+	Roots []VaultRoot
+
+	// Next resolves the wikilinks, with the prefixed IDs of the files
+	// as their targets.
+	//
+	// Defaults to DefaultResolver.
+	Next Resolver
+
+	// Strict fails wikilinks to targets that aren't in any vault
+	// with a TargetNotFoundError instead of resolving them as-is,
+	// or with an InvalidTargetError if they point outside the vaults.
+	Strict bool
+}
+
+var _ DetailedResolver = (*MultiRootResolver)(nil)
+
+// ResolveWikilink resolves the provided wikilink to the file
+// in the first vault that has one for its target.
+func (r *MultiRootResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	res, err := r.ResolveWikilinkDetails(n)
+	return res.Destination, err
+}
+
+// ResolveWikilinkDetails is like ResolveWikilink,
+// but it reports the Resolution of Next.
+func (r *MultiRootResolver) ResolveWikilinkDetails(n *Node) (Resolution, error) {
+	next := r.Next
+	if next == nil {
+		next = DefaultResolver
+	}
+	if len(n.Target) == 0 {
+		return ResolveDetails(next, n)
+	}
+
+	target := string(n.Target)
+	for _, root := range r.rootsFor(target) {
+		if err := root.Index.Load(); err != nil {
+			return Resolution{}, err
+		}
+		var ids []string
+		if hasPrefixFold(target, root.Prefix) {
+			// [[work/Foo]] is Foo in the work vault.
+			ids = lookupTarget(root.Index, target[len(root.Prefix):])
+		}
+		if len(ids) == 0 {
+			ids = lookupTarget(root.Index, target)
+		}
+
+		switch len(ids) {
+		case 0:
+			continue
+		case 1:
+			id := root.Prefix + ids[0]
+			n.Tracef("found in vault %q as %q", root.Prefix, id)
+			return ResolveDetails(next, n.withTarget([]byte(id)))
+		default:
+			for i, id := range ids {
+				ids[i] = root.Prefix + id
+			}
+			return Resolution{}, &AmbiguousTargetError{Node: n, Candidates: ids}
+		}
+	}
+
+	n.Tracef("%q is not in any vault", target)
+	if r.Strict {
+		if outsideVault(target) {
+			return Resolution{}, &InvalidTargetError{Node: n, Reason: "outside the vault"}
+		}
+		return Resolution{}, &TargetNotFoundError{Node: n}
+	}
+	return ResolveDetails(next, n)
+}
+
+// rootsFor returns the roots to look up target in, in order:
+// those whose Prefix the target starts with first,
+// then the rest in order of precedence.
+func (r *MultiRootResolver) rootsFor(target string) []VaultRoot {
+	roots := make([]VaultRoot, 0, len(r.Roots))
+	for _, root := range r.Roots {
+		if root.Index != nil && hasPrefixFold(target, root.Prefix) {
+			roots = append(roots, root)
+		}
+	}
+	for _, root := range r.Roots {
+		if root.Index != nil && !hasPrefixFold(target, root.Prefix) {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
+// hasPrefixFold reports whether s starts with the non-empty prefix,
+// ignoring case.
+func hasPrefixFold(s, prefix string) bool {
+	return len(prefix) > 0 && len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
@@ -0,0 +1,98 @@
+package wikilink
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiRootResolver(t *testing.T) {
+	t.Parallel()
+
+	notes := new(Index)
+	notes.Add("Foo")
+	notes.Add("topics/Go")
+	notes.Add("a/Dup")
+	notes.Add("b/Dup")
+
+	work := new(Index)
+	work.Add("Foo")
+	work.Add("projects/Bar")
+	work.Add("Go")
+
+	archive := new(Index)
+	archive.Add("Bar")
+	archive.Add("Old")
+
+	roots := []VaultRoot{
+		{Index: notes},
+		{Index: work, Prefix: "work/"},
+		{Index: archive, Prefix: "archive/"},
+		{Prefix: "empty/"},
+	}
+	next := NewRootResolver("/")
+
+	tests := []struct {
+		desc    string
+		strict  bool
+		target  string
+		want    string
+		wantErr error
+	}{
+		{desc: "first root", target: "Foo", want: "/Foo/"},
+		{desc: "precedence", target: "Bar", want: "/work/projects/Bar/"},
+		{desc: "later root", target: "Old", want: "/archive/Old/"},
+		{desc: "prefixed", target: "work/Foo", want: "/work/Foo/"},
+		{desc: "prefixed/case", target: "Archive/bar", want: "/archive/Bar/"},
+		{desc: "prefixed/full path", target: "work/projects/Bar", want: "/work/projects/Bar/"},
+		{desc: "prefixed/missing", target: "archive/Foo", want: "/archive/Foo/"},
+		{desc: "path in first root", target: "topics/Go", want: "/topics/Go/"},
+		{desc: "ambiguous", target: "Dup", wantErr: ErrAmbiguousTarget},
+		{desc: "missing", target: "Nope", want: "/Nope/"},
+		{desc: "missing/strict", strict: true, target: "Nope", wantErr: ErrTargetNotFound},
+		{desc: "outside/strict", strict: true, target: "../Foo", wantErr: ErrInvalidTarget},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			r := &MultiRootResolver{Roots: roots, Next: next, Strict: tt.strict}
+			got, err := r.ResolveWikilink(&Node{Target: []byte(tt.target)})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+
+	t.Run("ambiguous candidates", func(t *testing.T) {
+		t.Parallel()
+
+		both := new(Index)
+		both.Add("x/Baz")
+		both.Add("y/Baz")
+		r := &MultiRootResolver{Roots: []VaultRoot{{Index: both, Prefix: "w/"}}}
+		_, err := r.ResolveWikilink(&Node{Target: []byte("Baz")})
+
+		var ambiguous *AmbiguousTargetError
+		require.ErrorAs(t, err, &ambiguous)
+		assert.Equal(t, []string{"w/x/Baz", "w/y/Baz"}, ambiguous.Candidates)
+	})
+}
+
+func TestMultiRootResolver_Private(t *testing.T) {
+	t.Parallel()
+
+	idx := new(Index)
+	idx.Add("notes/Foo")
+	r := &MultiRootResolver{
+		Roots: []VaultRoot{{Index: idx, Prefix: "work/"}},
+		Next:  privateResolver,
+	}
+	assertNotLinked(t, r, "[[Foo]] [[Bar]]")
+}
@@ -0,0 +1,102 @@
+package wikilink
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// NewNotionResolver returns a resolver for content exported from Notion.
+//
+// Notion names exported files and directories after their page titles
+// followed by a unique ID, like "Page Title 3fa9c0d1e2f34a5b6c7d8e9f0a1b2c3d.md".
+// NewNotionResolver registers the provided exported file paths
+// under their titles without these IDs,
+// so that [[Page Title]] and [[Parent/Page Title]] find them.
+//
+// Once found, the target is replaced with the exported path
+// (without the ".md" extension) and resolved with next.
+// Targets that don't match any exported file are passed to next as-is.
+//
+//	r := NewNotionResolver([]string{"Page Title 3fa9c0d1e2f34a5b6c7d8e9f0a1b2c3d.md"}, DefaultResolver)
+//	[[Page Title]]  // => "Page Title 3fa9c0d1e2f34a5b6c7d8e9f0a1b2c3d.html"
+//
+// If a title matches more than one exported file,
+// the resolver fails with an *AmbiguousTargetError.
+func NewNotionResolver(files []string, next Resolver) Resolver {
+	r := &notionResolver{
+		next:   next,
+		titles: make(map[string][]string),
+	}
+	for _, f := range files {
+		f = strings.TrimSuffix(path.Clean(f), ".md")
+		title := notionStripIDs(f)
+		r.add(title, f)
+		if base := path.Base(title); base != title {
+			r.add(base, f)
+		}
+	}
+	for _, paths := range r.titles {
+		sort.Strings(paths)
+	}
+	return r
+}
+
+type notionResolver struct {
+	next Resolver
+
+	// titles maps page titles and paths with the Notion IDs stripped
+	// to the exported paths that have them.
+	titles map[string][]string
+}
+
+func (r *notionResolver) add(title, file string) {
+	for _, f := range r.titles[title] {
+		if f == file {
+			return
+		}
+	}
+	r.titles[title] = append(r.titles[title], file)
+}
+
+var _ DetailedResolver = (*notionResolver)(nil)
+
+func (r *notionResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	res, err := r.ResolveWikilinkDetails(n)
+	return res.Destination, err
+}
+
+func (r *notionResolver) ResolveWikilinkDetails(n *Node) (Resolution, error) {
+	if len(n.Target) == 0 {
+		return ResolveDetails(r.next, n)
+	}
+
+	files := r.titles[strings.TrimSuffix(string(n.Target), ".md")]
+	switch len(files) {
+	case 0:
+		n.Tracef("no Notion export for %q", n.Target)
+		return ResolveDetails(r.next, n)
+	case 1:
+		n.Tracef("matched Notion export %q", files[0])
+		return ResolveDetails(r.next, n.withTarget([]byte(files[0])))
+	default:
+		return Resolution{}, &AmbiguousTargetError{
+			Node:       n,
+			Candidates: append([]string(nil), files...),
+		}
+	}
+}
+
+// _notionID matches the unique ID that Notion appends
+// to the names of exported files and directories.
+var _notionID = regexp.MustCompile(` [0-9a-f]{32}$`)
+
+// notionStripIDs removes Notion IDs from all components of the given path.
+func notionStripIDs(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = _notionID.ReplaceAllString(part, "")
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,98 @@
+package wikilink
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotionResolver(t *testing.T) {
+	t.Parallel()
+
+	r := NewNotionResolver([]string{
+		"Page Title 3fa9c0d1e2f34a5b6c7d8e9f0a1b2c3d.md",
+		"Parent 0123456789abcdef0123456789abcdef/Child 00112233445566778899aabbccddeeff.md",
+		"a/Dup 11111111111111111111111111111111.md",
+		"b/Dup 22222222222222222222222222222222.md",
+	}, DefaultResolver)
+
+	tests := []struct {
+		desc     string
+		target   string
+		fragment string
+		want     string
+	}{
+		{
+			desc:   "title",
+			target: "Page Title",
+			want:   "Page Title 3fa9c0d1e2f34a5b6c7d8e9f0a1b2c3d.html",
+		},
+		{
+			desc:   "title with extension",
+			target: "Page Title.md",
+			want:   "Page Title 3fa9c0d1e2f34a5b6c7d8e9f0a1b2c3d.html",
+		},
+		{
+			desc:     "nested",
+			target:   "Parent/Child",
+			fragment: "Foo",
+			want:     "Parent 0123456789abcdef0123456789abcdef/Child 00112233445566778899aabbccddeeff.html#Foo",
+		},
+		{
+			desc:   "nested by title",
+			target: "Child",
+			want:   "Parent 0123456789abcdef0123456789abcdef/Child 00112233445566778899aabbccddeeff.html",
+		},
+		{
+			desc:   "disambiguated by path",
+			target: "b/Dup",
+			want:   "b/Dup 22222222222222222222222222222222.html",
+		},
+		{
+			desc:   "unknown",
+			target: "Unknown",
+			want:   "Unknown.html",
+		},
+		{
+			desc:     "fragment only",
+			fragment: "Foo",
+			want:     "#Foo",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			n := &Node{Target: []byte(tt.target), Fragment: []byte(tt.fragment)}
+			got, err := r.ResolveWikilink(n)
+			require.NoError(t, err, "resolve failed")
+			assert.Equal(t, tt.want, string(got), "result mismatch")
+			assert.Equal(t, tt.target, string(n.Target), "node must not be modified")
+		})
+	}
+
+	t.Run("ambiguous", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := r.ResolveWikilink(&Node{Target: []byte("Dup")})
+		require.ErrorIs(t, err, ErrAmbiguousTarget)
+
+		var ambiguous *AmbiguousTargetError
+		require.True(t, errors.As(err, &ambiguous))
+		assert.Equal(t, []string{
+			"a/Dup 11111111111111111111111111111111",
+			"b/Dup 22222222222222222222222222222222",
+		}, ambiguous.Candidates)
+	})
+}
+
+func TestNotionResolver_Private(t *testing.T) {
+	t.Parallel()
+
+	r := NewNotionResolver([]string{"Page Title 3fa9c0d1e2f34a5b6c7d8e9f0a1b2c3d.md"}, privateResolver)
+	assertNotLinked(t, r, "[[Page Title]] [[Foo]]")
+}
@@ -0,0 +1,84 @@
+package wikilink
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ObsidianURIResolver resolves wikilinks to obsidian:// URIs
+// that open their notes in the Obsidian app.
+// Use it to render HTML, like digests or emails,
+// whose links reopen notes in a local vault.
+//
+//	r := &wikilink.ObsidianURIResolver{Vault: "My Vault"}
+//	[[Foo bar]]       // => "obsidian://open?vault=My%20Vault&file=Foo%20bar"
+//	[[notes/Foo#Baz]] // => "obsidian://open?vault=My%20Vault&file=notes%2FFoo%23Baz"
+//
+// Wikilinks to headings in the same note, like [[#Baz]],
+// open the note containing them, as set by SetContextSource,
+// or link to the heading on the page if it's unknown.
+//
+// Embeds are resolved with Next because images and other embedded files
+// can't be loaded from obsidian:// URIs.
+type ObsidianURIResolver struct {
+	// Vault is the name of the vault containing the notes.
+	//
+	// If empty, Obsidian opens the notes in the last vault it had open.
+	Vault string
+
+	// Next resolves embeds.
+	//
+	// Defaults to DefaultResolver.
+	Next Resolver
+}
+
+var _ DetailedResolver = (*ObsidianURIResolver)(nil)
+
+// ResolveWikilink resolves the provided wikilink to an obsidian:// URI,
+// or with Next if it's an embed.
+func (r *ObsidianURIResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	res, err := r.ResolveWikilinkDetails(n)
+	return res.Destination, err
+}
+
+// ResolveWikilinkDetails is like ResolveWikilink,
+// but it reports the Resolution of Next
+// for the wikilinks it resolves with Next.
+func (r *ObsidianURIResolver) ResolveWikilinkDetails(n *Node) (Resolution, error) {
+	if n.Embed {
+		next := r.Next
+		if next == nil {
+			next = DefaultResolver
+		}
+		return ResolveDetails(next, n)
+	}
+
+	file := strings.TrimSuffix(string(n.Target), ".md")
+	if len(file) == 0 {
+		file = strings.TrimSuffix(n.Source(), ".md")
+	}
+	if len(file) == 0 {
+		return Resolution{Destination: DestBuilder{Fragment: n.Fragment}.Build()}, nil
+	}
+	if len(n.Fragment) > 0 {
+		file += "#" + string(n.Fragment)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("obsidian://open?")
+	if len(r.Vault) > 0 {
+		sb.WriteString("vault=")
+		sb.WriteString(obsidianURIEscape(r.Vault))
+		sb.WriteByte('&')
+	}
+	sb.WriteString("file=")
+	sb.WriteString(obsidianURIEscape(file))
+	return Resolution{Destination: []byte(sb.String())}, nil
+}
+
+// obsidianURIEscape escapes a parameter of an obsidian:// URI
+// like JavaScript's encodeURIComponent, which Obsidian expects:
+// with "%20" rather than "+" for spaces.
+func obsidianURIEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,86 @@
+package wikilink
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObsidianURIResolver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		resolver *ObsidianURIResolver
+		node     *Node
+		want     string
+	}{
+		{
+			desc:     "simple",
+			resolver: &ObsidianURIResolver{Vault: "My Vault"},
+			node:     &Node{Target: []byte("Foo bar")},
+			want:     "obsidian://open?vault=My%20Vault&file=Foo%20bar",
+		},
+		{
+			desc:     "path and fragment",
+			resolver: &ObsidianURIResolver{Vault: "notes"},
+			node:     &Node{Target: []byte("daily/2024-01-01.md"), Fragment: []byte("Tasks")},
+			want:     "obsidian://open?vault=notes&file=daily%2F2024-01-01%23Tasks",
+		},
+		{
+			desc:     "special characters",
+			resolver: &ObsidianURIResolver{Vault: "a&b"},
+			node:     &Node{Target: []byte("C++ & Go?")},
+			want:     "obsidian://open?vault=a%26b&file=C%2B%2B%20%26%20Go%3F",
+		},
+		{
+			desc:     "no vault",
+			resolver: &ObsidianURIResolver{},
+			node:     &Node{Target: []byte("Foo")},
+			want:     "obsidian://open?file=Foo",
+		},
+		{
+			desc:     "same note",
+			resolver: &ObsidianURIResolver{Vault: "v"},
+			node:     &Node{Fragment: []byte("Intro"), source: "notes/Foo.md"},
+			want:     "obsidian://open?vault=v&file=notes%2FFoo%23Intro",
+		},
+		{
+			desc:     "same note without source",
+			resolver: &ObsidianURIResolver{Vault: "v"},
+			node:     &Node{Fragment: []byte("Intro")},
+			want:     "#Intro",
+		},
+		{
+			desc:     "embed",
+			resolver: &ObsidianURIResolver{Vault: "v"},
+			node:     &Node{Target: []byte("cat.png"), Embed: true},
+			want:     "cat.png",
+		},
+		{
+			desc:     "embed with next",
+			resolver: &ObsidianURIResolver{Vault: "v", Next: NewRootResolver("/static/")},
+			node:     &Node{Target: []byte("cat.png"), Embed: true},
+			want:     "/static/cat.png",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.resolver.ResolveWikilink(tt.node)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestObsidianURIResolver_Private(t *testing.T) {
+	t.Parallel()
+
+	r := &ObsidianURIResolver{Next: privateResolver}
+	assertNotLinked(t, r, "![[doc.pdf]]")
+}
@@ -0,0 +1,54 @@
+package wikilink
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// NewObsidianPublishResolver returns a resolver that generates URLs
+// like those of a vault published with Obsidian Publish.
+//
+// Targets are placed under the given base URL
+// without their ".md" extension, if any,
+// and URL-encoded with "+" in place of spaces.
+//
+//	r := NewObsidianPublishResolver("https://publish.obsidian.md/myvault")
+//	[[Foo bar]]          // => "https://publish.obsidian.md/myvault/Foo+bar"
+//	[[notes/Foo.md#Baz]] // => "https://publish.obsidian.md/myvault/notes/Foo#Baz"
+//	[[foo.png]]          // => "https://publish.obsidian.md/myvault/foo.png"
+func NewObsidianPublishResolver(base string) Resolver {
+	return &publishResolver{
+		base: strings.TrimSuffix(base, "/"),
+	}
+}
+
+type publishResolver struct {
+	base string
+}
+
+func (r *publishResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	b := DestBuilder{Prefix: r.base, Query: n.Query}
+	if len(n.Target) > 0 {
+		target := strings.TrimSuffix(string(n.Target), ".md")
+
+		var sb strings.Builder
+		for _, part := range strings.Split(path.Clean("/"+target), "/")[1:] {
+			sb.WriteByte('/')
+			sb.WriteString(publishEscape(part))
+		}
+		b.Path = []byte(sb.String())
+	}
+	if len(n.Fragment) > 0 {
+		b.Fragment = []byte(url.PathEscape(string(n.Fragment)))
+	}
+	return b.Build(), nil
+}
+
+// publishEscape escapes a single path segment the way Obsidian Publish
+// does: percent-encoded, with "+" in place of spaces.
+func publishEscape(s string) string {
+	s = url.PathEscape(s)
+	s = strings.ReplaceAll(s, "+", "%2B")
+	return strings.ReplaceAll(s, "%20", "+")
+}
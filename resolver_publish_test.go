@@ -0,0 +1,67 @@
+package wikilink
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObsidianPublishResolver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		base     string
+		target   string
+		fragment string
+		want     string
+	}{
+		{
+			base:   "https://publish.obsidian.md/vault",
+			target: "Foo",
+			want:   "https://publish.obsidian.md/vault/Foo",
+		},
+		{
+			base:   "https://publish.obsidian.md/vault/",
+			target: "Foo bar",
+			want:   "https://publish.obsidian.md/vault/Foo+bar",
+		},
+		{
+			base:     "https://notes.example.com",
+			target:   "notes/Foo.md",
+			fragment: "Some heading",
+			want:     "https://notes.example.com/notes/Foo#Some%20heading",
+		},
+		{
+			base:   "https://notes.example.com",
+			target: "C++ & Go",
+			want:   "https://notes.example.com/C%2B%2B+&+Go",
+		},
+		{
+			base:   "https://notes.example.com",
+			target: "img/foo bar.png",
+			want:   "https://notes.example.com/img/foo+bar.png",
+		},
+		{
+			base:     "https://notes.example.com",
+			fragment: "Foo",
+			want:     "#Foo",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		name := fmt.Sprintf("%v %v#%v", tt.base, tt.target, tt.fragment)
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewObsidianPublishResolver(tt.base).ResolveWikilink(&Node{
+				Target:   []byte(tt.target),
+				Fragment: []byte(tt.fragment),
+			})
+			require.NoError(t, err, "resolve failed")
+			assert.Equal(t, tt.want, string(got), "result mismatch")
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package wikilink
+
+import "strings"
+
+// DefaultTaxonomies maps the singular and plural names of the taxonomies
+// that Hugo defines by default to the plural names used in their URLs.
+var DefaultTaxonomies = map[string]string{
+	"tag":        "tags",
+	"tags":       "tags",
+	"category":   "categories",
+	"categories": "categories",
+}
+
+// TaxonomyResolver resolves wikilinks to taxonomy terms,
+// like tags and categories, to the URLs of their pages
+// in a static site generator.
+// All other wikilinks are resolved with Next.
+//
+// Targets are recognized as taxonomy terms by their first "/"-separated
+// component, which is matched case-insensitively against Taxonomies.
+// The term is slugged with Slugify.
+//
+//	r := &wikilink.TaxonomyResolver{}
+//	[[tags/Go Lang]] // => "/tags/go-lang/"
+//	[[tag/golang]]   // => "/tags/golang/"
+//	[[categories/]]  // => "/categories/"
+//	[[about]]        // => "about.html"
+type TaxonomyResolver struct {
+	// Taxonomies maps the prefixes of taxonomy targets, in lowercase,
+	// to the names of the taxonomies in URLs.
+	// List both the singular and plural forms of a taxonomy
+	// to recognize both.
+	//
+	//	Taxonomies: map[string]string{"series": "series", "author": "authors"}
+	//
+	// Defaults to DefaultTaxonomies.
+	Taxonomies map[string]string
+
+	// Base is the URL under which taxonomy pages are placed.
+	//
+	// Defaults to "/".
+	Base string
+
+	// Slugify turns terms into the path segments of their pages.
+	// Use this to match the slug algorithm of your site generator.
+	//
+	// Defaults to the algorithm of a zero SlugResolver.
+	Slugify func(string) string
+
+	// Next resolves wikilinks that aren't to taxonomy terms.
+	//
+	// Defaults to DefaultResolver.
+	Next Resolver
+}
+
+var _ DetailedResolver = (*TaxonomyResolver)(nil)
+
+// ResolveWikilink resolves the provided wikilink to the page of
+// a taxonomy term, or with Next if it isn't one.
+func (r *TaxonomyResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	res, err := r.ResolveWikilinkDetails(n)
+	return res.Destination, err
+}
+
+// ResolveWikilinkDetails is like ResolveWikilink,
+// but it reports the Resolution of Next
+// for the wikilinks it resolves with Next.
+func (r *TaxonomyResolver) ResolveWikilinkDetails(n *Node) (Resolution, error) {
+	prefix, term, ok := strings.Cut(string(n.Target), "/")
+	taxonomy, isTaxonomy := r.taxonomies()[strings.ToLower(prefix)]
+	if !ok || !isTaxonomy {
+		next := r.Next
+		if next == nil {
+			next = DefaultResolver
+		}
+		return ResolveDetails(next, n)
+	}
+
+	base := r.Base
+	if len(base) == 0 {
+		base = "/"
+	}
+
+	dest := strings.TrimSuffix(base, "/") + "/" + taxonomy + "/"
+	if term = strings.Trim(term, "/"); len(term) > 0 {
+		slug := r.Slugify
+		if slug == nil {
+			slug = slugify
+		}
+		dest += slug(term) + "/"
+	}
+	n.Tracef("taxonomy %q", taxonomy)
+
+	return Resolution{
+		Destination: DestBuilder{
+			Path:     []byte(dest),
+			Query:    n.Query,
+			Fragment: n.Fragment,
+		}.Build(),
+	}, nil
+}
+
+func (r *TaxonomyResolver) taxonomies() map[string]string {
+	if r.Taxonomies != nil {
+		return r.Taxonomies
+	}
+	return DefaultTaxonomies
+}
@@ -0,0 +1,103 @@
+package wikilink
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaxonomyResolver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		resolver *TaxonomyResolver
+		target   string
+		fragment string
+		want     string
+	}{
+		{
+			desc:     "tag",
+			resolver: &TaxonomyResolver{},
+			target:   "tags/Go Lang",
+			want:     "/tags/go-lang/",
+		},
+		{
+			desc:     "singular",
+			resolver: &TaxonomyResolver{},
+			target:   "Tag/golang",
+			want:     "/tags/golang/",
+		},
+		{
+			desc:     "category",
+			resolver: &TaxonomyResolver{},
+			target:   "category/Web Dev",
+			fragment: "Top",
+			want:     "/categories/web-dev/#Top",
+		},
+		{
+			desc:     "list",
+			resolver: &TaxonomyResolver{},
+			target:   "categories/",
+			want:     "/categories/",
+		},
+		{
+			desc:     "base",
+			resolver: &TaxonomyResolver{Base: "/blog/"},
+			target:   "tags/go",
+			want:     "/blog/tags/go/",
+		},
+		{
+			desc: "custom",
+			resolver: &TaxonomyResolver{
+				Taxonomies: map[string]string{"author": "authors"},
+				Slugify:    strings.ToLower,
+			},
+			target: "author/Jane Doe",
+			want:   "/authors/jane doe/",
+		},
+		{
+			desc: "custom replaces defaults",
+			resolver: &TaxonomyResolver{
+				Taxonomies: map[string]string{"author": "authors"},
+			},
+			target: "tags/go",
+			want:   "tags/go.html",
+		},
+		{
+			desc:     "not a taxonomy",
+			resolver: &TaxonomyResolver{Next: NewPrettyResolver()},
+			target:   "notes/tags",
+			want:     "notes/tags/",
+		},
+		{
+			desc:     "taxonomy name only",
+			resolver: &TaxonomyResolver{},
+			target:   "tags",
+			want:     "tags.html",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.resolver.ResolveWikilink(&Node{
+				Target:   []byte(tt.target),
+				Fragment: []byte(tt.fragment),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestTaxonomyResolver_Private(t *testing.T) {
+	t.Parallel()
+
+	r := &TaxonomyResolver{Next: privateResolver}
+	assertNotLinked(t, r, "[[about]]")
+}
@@ -1,6 +1,7 @@
 package wikilink
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -19,6 +20,7 @@ func TestDefaultResolver(t *testing.T) {
 
 	tests := []struct {
 		target   string
+		query    string
 		fragment string
 		want     string
 	}{
@@ -56,16 +58,33 @@ func TestDefaultResolver(t *testing.T) {
 			fragment: "foo",
 			want:     "#foo",
 		},
+		{
+			target:   "C# notes",
+			fragment: "bar",
+			want:     "C%23 notes.html#bar",
+		},
+		{
+			target: "search",
+			query:  "tag=golang.pdf",
+			want:   "search.html?tag=golang.pdf",
+		},
+		{
+			target:   "foo.pdf",
+			query:    "page=1",
+			fragment: "bar",
+			want:     "foo.pdf?page=1#bar",
+		},
 	}
 
 	for _, tt := range tests {
 		tt := tt
-		name := fmt.Sprintf("%v#%v", tt.target, tt.fragment)
+		name := fmt.Sprintf("%v?%v#%v", tt.target, tt.query, tt.fragment)
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
 			got, err := DefaultResolver.ResolveWikilink(&Node{
 				Target:   []byte(tt.target),
+				Query:    []byte(tt.query),
 				Fragment: []byte(tt.fragment),
 			})
 			require.NoError(t, err, "resolve failed")
@@ -73,3 +92,88 @@ func TestDefaultResolver(t *testing.T) {
 		})
 	}
 }
+
+func TestResolverConstructors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		legacy Resolver
+		give   Resolver
+	}{
+		{"Default", DefaultResolver, NewDefaultResolver()},
+		{"Pretty", PrettyResolver, NewPrettyResolver()},
+		{"Rel", RelResolver, NewRelResolver()},
+		{"Root", RootResolver("/root/"), NewRootResolver("/root/")},
+	}
+
+	n := &Node{Target: []byte("Foo Bar"), Fragment: []byte("Baz")}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			want, err := tt.legacy.ResolveWikilink(n)
+			require.NoError(t, err)
+
+			got, err := tt.give.ResolveWikilink(n)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+type ctxResolverFunc func(context.Context, *Node) ([]byte, error)
+
+func (f ctxResolverFunc) ResolveWikilink(n *Node) ([]byte, error) {
+	return f(context.Background(), n)
+}
+
+func (f ctxResolverFunc) ResolveWikilinkContext(ctx context.Context, n *Node) ([]byte, error) {
+	return f(ctx, n)
+}
+
+func TestResolveNode(t *testing.T) {
+	t.Parallel()
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+
+	r := ctxResolverFunc(func(ctx context.Context, n *Node) ([]byte, error) {
+		v, _ := ctx.Value(key{}).(string)
+		return []byte(v), nil
+	})
+
+	t.Run("context", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := ResolveNode(r, &Node{Target: []byte("Foo"), ctx: ctx})
+		require.NoError(t, err)
+		assert.Equal(t, "value", string(got))
+	})
+
+	t.Run("no context", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := ResolveNode(r, &Node{Target: []byte("Foo")})
+		require.NoError(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("plain resolver", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := ResolveNode(DefaultResolver, &Node{Target: []byte("Foo"), ctx: ctx})
+		require.NoError(t, err)
+		assert.Equal(t, "Foo.html", string(got))
+	})
+
+	t.Run("wrapped", func(t *testing.T) {
+		t.Parallel()
+
+		wrapped := NewTranslationResolver(map[string]string{"Bar": "Foo"}, r)
+		got, err := ResolveNode(wrapped, &Node{Target: []byte("Bar"), ctx: ctx})
+		require.NoError(t, err)
+		assert.Equal(t, "value", string(got))
+	})
+}
@@ -0,0 +1,43 @@
+package wikilink
+
+// NewTranslationResolver returns a resolver that translates wikilink targets
+// with the given lookup table before resolving them with next.
+// Targets that aren't in the table are resolved as-is.
+//
+// Use this to let authors link to pages by their titles in their own
+// language.
+//
+//	r := NewTranslationResolver(map[string]string{"关于": "about"}, PrettyResolver)
+//	[[关于]]      // => "about/"
+//	[[关于#团队]] // => "about/#团队"
+//
+// For multilingual sites, build one translation resolver per language and
+// pick one for each document with SetContextResolver.
+func NewTranslationResolver(translations map[string]string, next Resolver) Resolver {
+	return &translationResolver{
+		translations: translations,
+		next:         next,
+	}
+}
+
+type translationResolver struct {
+	translations map[string]string
+	next         Resolver
+}
+
+var _ DetailedResolver = (*translationResolver)(nil)
+
+func (r *translationResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	res, err := r.ResolveWikilinkDetails(n)
+	return res.Destination, err
+}
+
+func (r *translationResolver) ResolveWikilinkDetails(n *Node) (Resolution, error) {
+	if target, ok := r.translations[string(n.Target)]; ok {
+		n.Tracef("translated %q to %q", n.Target, target)
+		n = n.withTarget([]byte(target))
+	} else {
+		n.Tracef("no translation for %q", n.Target)
+	}
+	return ResolveDetails(r.next, n)
+}
@@ -0,0 +1,48 @@
+package wikilink
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslationResolver(t *testing.T) {
+	t.Parallel()
+
+	r := NewTranslationResolver(map[string]string{
+		"关于":    "about",
+		"博客/入门": "blog/getting-started",
+	}, PrettyResolver)
+
+	tests := []struct {
+		target   string
+		fragment string
+		want     string
+	}{
+		{target: "关于", want: "about/"},
+		{target: "关于", fragment: "团队", want: "about/#团队"},
+		{target: "博客/入门", want: "blog/getting-started/"},
+		{target: "contact", want: "contact/"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.target+"#"+tt.fragment, func(t *testing.T) {
+			t.Parallel()
+
+			n := &Node{Target: []byte(tt.target), Fragment: []byte(tt.fragment)}
+			got, err := r.ResolveWikilink(n)
+			require.NoError(t, err, "resolve failed")
+			assert.Equal(t, tt.want, string(got), "result mismatch")
+			assert.Equal(t, tt.target, string(n.Target), "node must not be modified")
+		})
+	}
+}
+
+func TestTranslationResolver_Private(t *testing.T) {
+	t.Parallel()
+
+	r := NewTranslationResolver(map[string]string{"关于": "about"}, privateResolver)
+	assertNotLinked(t, r, "[[关于]] [[Foo]]")
+}
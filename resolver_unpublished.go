@@ -0,0 +1,79 @@
+package wikilink
+
+import (
+	"strings"
+	"time"
+)
+
+// UnpublishedResolver resolves wikilinks with Next,
+// except for those to pages that aren't published,
+// which are left unresolved, like Hugo does with drafts,
+// scheduled pages, and expired pages.
+// This keeps published pages from linking to pages that
+// don't exist on the live site.
+//
+//	pages, err := wikilink.LoadPageMeta(os.DirFS("content"))
+//	r := &wikilink.UnpublishedResolver{Pages: pages, Next: wikilink.NewPrettyResolver()}
+//	[[posts/hello]] // => "" if content/posts/hello.md has "draft: true"
+//
+// See PageMeta.Published for which pages are published.
+//
+// Targets are matched against the keys of Pages exactly,
+// with or without a ".md" extension.
+//
+// For preview builds that should link to everything,
+// use Next directly or set Preview.
+type UnpublishedResolver struct {
+	// Pages maps targets to the publication state of their pages.
+	// Use LoadPageMeta to read this from the front matter of a
+	// directory of Markdown files.
+	Pages map[string]PageMeta
+
+	// Next resolves wikilinks to published pages.
+	//
+	// Defaults to DefaultResolver.
+	Next Resolver
+
+	// Preview specifies whether unpublished pages are linked to anyway.
+	Preview bool
+
+	// Now returns the time at which pages must be published
+	// to be linked to, usually the time of the build.
+	//
+	// Defaults to time.Now.
+	Now func() time.Time
+}
+
+var _ DetailedResolver = (*UnpublishedResolver)(nil)
+
+// ResolveWikilink resolves the provided wikilink with Next
+// if its target is published.
+func (r *UnpublishedResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	res, err := r.ResolveWikilinkDetails(n)
+	return res.Destination, err
+}
+
+// ResolveWikilinkDetails is like ResolveWikilink,
+// but it reports the Resolution of Next.
+func (r *UnpublishedResolver) ResolveWikilinkDetails(n *Node) (Resolution, error) {
+	if !r.Preview && len(n.Target) > 0 {
+		target := strings.TrimSuffix(string(n.Target), ".md")
+		if meta, ok := r.Pages[target]; ok && !meta.Published(r.now()) {
+			n.Tracef("unpublished %q", target)
+			return Resolution{}, nil
+		}
+	}
+
+	next := r.Next
+	if next == nil {
+		next = DefaultResolver
+	}
+	return ResolveDetails(next, n)
+}
+
+func (r *UnpublishedResolver) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
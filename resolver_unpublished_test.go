@@ -0,0 +1,109 @@
+package wikilink
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnpublishedResolver(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	pages := map[string]PageMeta{
+		"posts/hello":     {Draft: true},
+		"posts/bye":       {},
+		"posts/scheduled": {Date: now.AddDate(0, 0, 1)},
+		"posts/expired":   {ExpiryDate: now.AddDate(0, 0, -1)},
+	}
+	clock := func() time.Time { return now }
+
+	tests := []struct {
+		desc     string
+		resolver *UnpublishedResolver
+		target   string
+		fragment string
+		want     string
+	}{
+		{
+			desc:     "draft",
+			resolver: &UnpublishedResolver{Pages: pages},
+			target:   "posts/hello",
+			want:     "",
+		},
+		{
+			desc:     "draft with extension",
+			resolver: &UnpublishedResolver{Pages: pages},
+			target:   "posts/hello.md",
+			want:     "",
+		},
+		{
+			desc:     "published",
+			resolver: &UnpublishedResolver{Pages: pages},
+			target:   "posts/bye",
+			want:     "posts/bye.html",
+		},
+		{
+			desc:     "scheduled",
+			resolver: &UnpublishedResolver{Pages: pages, Now: clock},
+			target:   "posts/scheduled",
+			want:     "",
+		},
+		{
+			desc:     "scheduled and live",
+			resolver: &UnpublishedResolver{Pages: pages, Now: func() time.Time { return now.AddDate(0, 0, 2) }},
+			target:   "posts/scheduled",
+			want:     "posts/scheduled.html",
+		},
+		{
+			desc:     "expired",
+			resolver: &UnpublishedResolver{Pages: pages, Now: clock},
+			target:   "posts/expired",
+			want:     "",
+		},
+		{
+			desc:     "unknown",
+			resolver: &UnpublishedResolver{Pages: pages, Next: NewPrettyResolver()},
+			target:   "about",
+			want:     "about/",
+		},
+		{
+			desc:     "fragment",
+			resolver: &UnpublishedResolver{Pages: pages},
+			fragment: "Intro",
+			want:     "#Intro",
+		},
+		{
+			desc:     "preview",
+			resolver: &UnpublishedResolver{Pages: pages, Preview: true},
+			target:   "posts/hello",
+			want:     "posts/hello.html",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.resolver.ResolveWikilink(&Node{
+				Target:   []byte(tt.target),
+				Fragment: []byte(tt.fragment),
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestUnpublishedResolver_Private(t *testing.T) {
+	t.Parallel()
+
+	r := &UnpublishedResolver{
+		Pages: map[string]PageMeta{"Foo": {}},
+		Next:  privateResolver,
+	}
+	assertNotLinked(t, r, "[[Foo]] [[Bar]]")
+}
@@ -0,0 +1,127 @@
+package wikilink
+
+import (
+	"net/url"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// NewGitHubWikiResolver returns a resolver that resolves wikilinks the way
+// GitHub wikis (Gollum) do.
+//
+// GitHub wikis have a flat namespace: pages are identified by their names
+// alone, regardless of the directory they are in.
+// Spaces in page names become dashes, the case is preserved,
+// and pages don't have an extension.
+// Fragments are turned into GitHub-style heading anchors.
+// Targets with extensions other than ".md", like images, are left as-is.
+//
+//	[[Home]]                   // => "Home"
+//	[[Getting Started]]        // => "Getting-Started"
+//	[[guides/Getting Started]] // => "Getting-Started"
+//	[[Install#From Source]]    // => "Install#from-source"
+func NewGitHubWikiResolver() Resolver {
+	return githubWikiResolver{}
+}
+
+type githubWikiResolver struct{}
+
+func (githubWikiResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	b := DestBuilder{Path: n.Target, Query: n.Query}
+	if len(n.Target) > 0 {
+		target := string(n.Target)
+		switch ext := path.Ext(target); ext {
+		case "", ".md":
+			page := path.Base(strings.TrimSuffix(target, ext))
+			b.Path = []byte(strings.ReplaceAll(page, " ", "-"))
+		}
+	}
+	if len(n.Fragment) > 0 {
+		b.Fragment = []byte(githubAnchor(string(n.Fragment)))
+	}
+	return b.Build(), nil
+}
+
+// NewGitLabWikiResolver returns a resolver that resolves wikilinks to pages of a
+// GitLab wiki hosted at the given base URL, like "/group/project/-/wikis".
+//
+// Spaces in page names become dashes and the case is preserved.
+// Directories in targets become subpages.
+// Fragments are turned into heading anchors like GitLab's.
+// Targets with extensions other than ".md", like images, are left as-is
+// under the base URL.
+//
+//	r := NewGitLabWikiResolver("/group/project/-/wikis")
+//	[[Getting Started]]    // => "/group/project/-/wikis/Getting-Started"
+//	[[guides/Setup#Linux]] // => "/group/project/-/wikis/guides/Setup#linux"
+func NewGitLabWikiResolver(base string) Resolver {
+	return &hostedWikiResolver{
+		base: strings.TrimSuffix(base, "/") + "/",
+		page: func(name string) string {
+			return strings.ReplaceAll(name, " ", "-")
+		},
+	}
+}
+
+// NewGiteaWikiResolver returns a resolver that resolves wikilinks to pages of a
+// Gitea or Forgejo wiki hosted at the given base URL,
+// like "/owner/repo/wiki".
+//
+// Gitea wikis have a flat namespace. Spaces in page names become dashes,
+// the case is preserved, and other special characters, including "/",
+// are percent-encoded.
+// Fragments are turned into heading anchors like Gitea's.
+// Targets with extensions other than ".md", like images, are left as-is
+// under the base URL.
+//
+//	r := NewGiteaWikiResolver("/owner/repo/wiki")
+//	[[Getting Started]] // => "/owner/repo/wiki/Getting-Started"
+//	[[CI/CD]]           // => "/owner/repo/wiki/CI%2FCD"
+func NewGiteaWikiResolver(base string) Resolver {
+	return &hostedWikiResolver{
+		base: strings.TrimSuffix(base, "/") + "/",
+		page: func(name string) string {
+			return url.PathEscape(strings.ReplaceAll(name, " ", "-"))
+		},
+	}
+}
+
+// hostedWikiResolver resolves wikilinks to pages of a wiki hosted under a
+// base URL, converting page names to URL components with page.
+type hostedWikiResolver struct {
+	base string // with a trailing "/"
+	page func(name string) string
+}
+
+func (r *hostedWikiResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	b := DestBuilder{Prefix: r.base, Path: n.Target, Query: n.Query}
+	if len(n.Target) > 0 {
+		target := string(n.Target)
+		switch ext := path.Ext(target); ext {
+		case "", ".md":
+			b.Path = []byte(r.page(strings.TrimSuffix(target, ext)))
+		}
+	}
+	if len(n.Fragment) > 0 {
+		b.Fragment = []byte(githubAnchor(string(n.Fragment)))
+	}
+	return b.Build(), nil
+}
+
+// githubAnchor turns a heading into an anchor the way GitHub does:
+// lowercased, with punctuation other than "-" and "_" removed,
+// and spaces replaced by "-".
+func githubAnchor(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r == ' ':
+			sb.WriteByte('-')
+		case r == '-', r == '_', unicode.IsLetter(r), unicode.IsDigit(r):
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
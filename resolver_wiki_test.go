@@ -0,0 +1,106 @@
+package wikilink
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubWikiResolver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		target   string
+		fragment string
+		want     string
+	}{
+		{target: "Home", want: "Home"},
+		{target: "Getting Started", want: "Getting-Started"},
+		{target: "guides/Getting Started.md", want: "Getting-Started"},
+		{target: "Install", fragment: "From Source (Linux)", want: "Install#from-source-linux"},
+		{target: "images/logo.png", want: "images/logo.png"},
+		{fragment: "API_v2 Notes", want: "#api_v2-notes"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		name := fmt.Sprintf("%v#%v", tt.target, tt.fragment)
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := NewGitHubWikiResolver().ResolveWikilink(&Node{
+				Target:   []byte(tt.target),
+				Fragment: []byte(tt.fragment),
+			})
+			require.NoError(t, err, "resolve failed")
+			assert.Equal(t, tt.want, string(got), "result mismatch")
+		})
+	}
+}
+
+func TestHostedWikiResolvers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc     string
+		resolver Resolver
+		target   string
+		fragment string
+		want     string
+	}{
+		{
+			desc:     "gitlab",
+			resolver: NewGitLabWikiResolver("/group/project/-/wikis/"),
+			target:   "Getting Started",
+			want:     "/group/project/-/wikis/Getting-Started",
+		},
+		{
+			desc:     "gitlab/subpage",
+			resolver: NewGitLabWikiResolver("/group/project/-/wikis"),
+			target:   "guides/Setup.md",
+			fragment: "On Linux",
+			want:     "/group/project/-/wikis/guides/Setup#on-linux",
+		},
+		{
+			desc:     "gitlab/upload",
+			resolver: NewGitLabWikiResolver("/group/project/-/wikis"),
+			target:   "uploads/logo.png",
+			want:     "/group/project/-/wikis/uploads/logo.png",
+		},
+		{
+			desc:     "gitea",
+			resolver: NewGiteaWikiResolver("/owner/repo/wiki"),
+			target:   "Getting Started",
+			want:     "/owner/repo/wiki/Getting-Started",
+		},
+		{
+			desc:     "gitea/slash",
+			resolver: NewGiteaWikiResolver("/owner/repo/wiki"),
+			target:   "CI/CD",
+			fragment: "Runners",
+			want:     "/owner/repo/wiki/CI%2FCD#runners",
+		},
+		{
+			desc:     "gitea/fragment only",
+			resolver: NewGiteaWikiResolver("/owner/repo/wiki"),
+			fragment: "Runners",
+			want:     "#runners",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.resolver.ResolveWikilink(&Node{
+				Target:   []byte(tt.target),
+				Fragment: []byte(tt.fragment),
+			})
+			require.NoError(t, err, "resolve failed")
+			assert.Equal(t, tt.want, string(got), "result mismatch")
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package wikilink
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+)
+
+// SearchIndex builds a JSON document of the notes in a vault
+// and the links between them,
+// for client-side search and graph libraries, like Elasticlunr or D3.
+//
+//	search := &wikilink.SearchIndex{Index: idx, Report: report}
+//	search.WriteJSON(f) // public/search.json
+//	// {
+//	//   "pages": [
+//	//     {
+//	//       "id": "notes/Foo",
+//	//       "url": "notes/Foo.html",
+//	//       "title": "Foo",
+//	//       "tags": ["go"],
+//	//       "links": ["notes/Bar"],
+//	//       "backlinks": []
+//	//     },
+//	//     ...
+//	//   ]
+//	// }
+//
+// Links are read from Report the way LinkReport.Backlinks does,
+// so render every note with an IndexResolver for Index before
+// building the search index,
+// and set their sources to their paths in the vault.
+type SearchIndex struct {
+	// Index of the files in the vault.
+	Index *Index
+
+	// Report of the wikilinks in all notes.
+	// Pages have no links without it.
+	Report *LinkReport
+
+	// Resolver resolves the IDs of notes, like "notes/Foo",
+	// to their URLs, like IndexResolver.Next.
+	// Notes that it doesn't resolve, like drafts, are left out,
+	// along with the links to them.
+	//
+	// Defaults to DefaultResolver.
+	Resolver Resolver
+}
+
+// SearchPage is a note in a SearchIndex.
+type SearchPage struct {
+	// ID of the note in the Index, like "notes/Foo".
+	ID string `json:"id"`
+
+	// URL of the note.
+	URL string `json:"url"`
+
+	// Title of the note, or else its name. See Index.Title.
+	Title string `json:"title"`
+
+	// Tags in the front matter of the note. See Index.Tags.
+	Tags []string `json:"tags"`
+
+	// Links are the IDs of the notes that the note links to,
+	// and Backlinks those of the notes that link to it,
+	// in lexical order.
+	Links     []string `json:"links"`
+	Backlinks []string `json:"backlinks"`
+}
+
+// Pages returns the notes in the index, sorted by ID.
+// Files other than notes, like images, are left out.
+func (s *SearchIndex) Pages() ([]SearchPage, error) {
+	if err := s.Index.Load(); err != nil {
+		return nil, err
+	}
+
+	var pages []SearchPage
+	for _, id := range s.Index.IDs() {
+		if !s.Index.isNote(id) {
+			continue
+		}
+		url, err := Resolve(id, "", s.Resolver)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", id, err)
+		}
+		if len(url) == 0 {
+			continue
+		}
+
+		title := s.Index.Title(id)
+		if len(title) == 0 {
+			title = path.Base(id)
+		}
+		tags := s.Index.Tags(id)
+		if tags == nil {
+			tags = []string{} // [] instead of null
+		}
+		pages = append(pages, SearchPage{
+			ID:        id,
+			URL:       url,
+			Title:     title,
+			Tags:      tags,
+			Links:     []string{},
+			Backlinks: []string{},
+		})
+	}
+	byID := make(map[string]*SearchPage, len(pages))
+	for i := range pages {
+		byID[pages[i].ID] = &pages[i]
+	}
+
+	if s.Report != nil {
+		for _, e := range s.Report.Entries() {
+			if e.Status != LinkOK || len(e.Target) == 0 {
+				continue
+			}
+			from, ok := byID[sourceID(e.Source)]
+			if !ok {
+				continue
+			}
+			ids := lookupTarget(s.Index, e.Target)
+			if len(ids) != 1 || ids[0] == from.ID {
+				continue
+			}
+			to, ok := byID[ids[0]]
+			if !ok {
+				continue
+			}
+			from.Links = append(from.Links, to.ID)
+			to.Backlinks = append(to.Backlinks, from.ID)
+		}
+	}
+
+	for i := range pages {
+		pages[i].Links = sortedUnique(pages[i].Links)
+		pages[i].Backlinks = sortedUnique(pages[i].Backlinks)
+	}
+	return pages, nil
+}
+
+// WriteJSON writes the search index to w as a JSON object
+// with the Pages in its "pages" field.
+func (s *SearchIndex) WriteJSON(w io.Writer) error {
+	pages, err := s.Pages()
+	if err != nil {
+		return err
+	}
+	if pages == nil {
+		pages = []SearchPage{} // [] instead of null
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Pages []SearchPage `json:"pages"`
+	}{Pages: pages})
+}
+
+// sortedUnique sorts ss and removes duplicates from it in-place.
+func sortedUnique(ss []string) []string {
+	sort.Strings(ss)
+	out := ss[:0]
+	for _, s := range ss {
+		if len(out) == 0 || s != out[len(out)-1] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
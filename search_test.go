@@ -0,0 +1,78 @@
+package wikilink_test
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	wikilink "github.com/kentxxq/goldmark-wikilink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestSearchIndex(t *testing.T) {
+	t.Parallel()
+
+	vault := fstest.MapFS{
+		"notes/Foo.md": {Data: []byte("---\ntags: [go, notes]\n---\n# Foo\n\nSee [[Bar]], [[bar|again]], [[#Foo]], and [[Draft]].\n")},
+		"Bar.md":       {Data: []byte("Back to [[notes/Foo]]. ![[cat.png]] [[Missing]]\n")},
+		"Draft.md":     {Data: []byte("---\ndraft: true\n---\nA draft about [[Foo]].\n")},
+		"cat.png":      {},
+	}
+	idx := &wikilink.Index{FS: vault}
+	report := new(wikilink.LinkReport)
+	renderVault(t, goldmark.New(goldmark.WithExtensions(&wikilink.Extender{
+		Resolver: &wikilink.IndexResolver{Index: idx},
+		Report:   report,
+	})), vault)
+
+	pages, err := wikilink.LoadPageMeta(vault)
+	require.NoError(t, err)
+	search := &wikilink.SearchIndex{
+		Index:    idx,
+		Report:   report,
+		Resolver: &wikilink.UnpublishedResolver{Pages: pages},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, search.WriteJSON(&buf))
+	assert.JSONEq(t, `{
+		"pages": [
+			{
+				"id": "Bar",
+				"url": "Bar.html",
+				"title": "Bar",
+				"tags": [],
+				"links": ["notes/Foo"],
+				"backlinks": ["notes/Foo"]
+			},
+			{
+				"id": "notes/Foo",
+				"url": "notes/Foo.html",
+				"title": "Foo",
+				"tags": ["go", "notes"],
+				"links": ["Bar"],
+				"backlinks": ["Bar"]
+			}
+		]
+	}`, buf.String())
+
+	t.Run("no report", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := (&wikilink.SearchIndex{Index: idx}).Pages()
+		require.NoError(t, err)
+		require.Len(t, got, 3)
+		assert.Equal(t, "Draft", got[1].ID)
+		assert.Empty(t, got[1].Backlinks)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, (&wikilink.SearchIndex{Index: new(wikilink.Index)}).WriteJSON(&buf))
+		assert.JSONEq(t, `{"pages": []}`, buf.String())
+	})
+}
@@ -0,0 +1,119 @@
+package wikilink
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+)
+
+// Sitemap writes a sitemap.xml of the notes in a vault,
+// so that search engines find every note of a site
+// that has no other list of its pages.
+//
+//	sitemap := &wikilink.Sitemap{Index: idx, BaseURL: "https://example.com/"}
+//	sitemap.WriteXML(f) // public/sitemap.xml
+//	// <?xml version="1.0" encoding="UTF-8"?>
+//	// <urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+//	//   <url>
+//	//     <loc>https://example.com/notes/Foo.html</loc>
+//	//     <lastmod>2024-01-15</lastmod>
+//	//   </url>
+//	// </urlset>
+//
+// The last modification dates of notes
+// are the modification times of their files.
+type Sitemap struct {
+	// Index of the files in the vault.
+	Index *Index
+
+	// Resolver resolves the IDs of notes, like "notes/Foo",
+	// to their URLs, like IndexResolver.Next.
+	// Notes that it doesn't resolve, like drafts, are left out.
+	//
+	// Defaults to DefaultResolver.
+	Resolver Resolver
+
+	// BaseURL is the absolute URL of the site,
+	// like "https://example.com/docs/",
+	// that the URLs of notes are relative to.
+	// Sitemaps must list absolute URLs,
+	// so set this unless Resolver builds them.
+	BaseURL string
+}
+
+// _sitemapNamespace is the XML namespace of sitemaps.
+const _sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// WriteXML writes the sitemap to w, with the notes sorted by ID.
+// Files other than notes, like images, are left out.
+func (s *Sitemap) WriteXML(w io.Writer) error {
+	if err := s.Index.Load(); err != nil {
+		return err
+	}
+
+	var base *url.URL
+	if len(s.BaseURL) > 0 {
+		var err error
+		if base, err = url.Parse(s.BaseURL); err != nil {
+			return fmt.Errorf("base URL: %w", err)
+		}
+	}
+
+	set := sitemapURLSet{Xmlns: _sitemapNamespace}
+	for _, id := range s.Index.IDs() {
+		if !s.Index.isNote(id) {
+			continue
+		}
+		dest, err := Resolve(id, "", s.Resolver)
+		if err != nil {
+			return fmt.Errorf("%v: %w", id, err)
+		}
+		if len(dest) == 0 {
+			continue
+		}
+		loc, err := url.Parse(dest)
+		if err != nil {
+			return fmt.Errorf("%v: %w", id, err)
+		}
+		if base != nil {
+			loc = base.ResolveReference(loc)
+		}
+
+		u := sitemapURL{Loc: loc.String()}
+		info, err := s.Index.Stat(id)
+		switch {
+		case err == nil:
+			if t := info.ModTime(); !t.IsZero() {
+				u.LastMod = t.UTC().Format("2006-01-02")
+			}
+		case !errors.Is(err, fs.ErrNotExist): // registered with Add
+			return err
+		}
+		set.URLs = append(set.URLs, u)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(set); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
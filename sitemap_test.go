@@ -0,0 +1,94 @@
+package wikilink_test
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	wikilink "github.com/kentxxq/goldmark-wikilink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSitemap(t *testing.T) {
+	t.Parallel()
+
+	vault := fstest.MapFS{
+		"notes/Foo Bar.md": {ModTime: time.Date(2024, 1, 15, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60))},
+		"index.md":         {ModTime: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		"Draft.md":         {Data: []byte("---\ndraft: true\n---\n")},
+		"cat.png":          {},
+	}
+	pages, err := wikilink.LoadPageMeta(vault)
+	require.NoError(t, err)
+
+	idx := &wikilink.Index{FS: vault}
+	idx.Add("Virtual")
+
+	tests := []struct {
+		desc string
+		give wikilink.Sitemap
+		want string
+	}{
+		{
+			desc: "base URL",
+			give: wikilink.Sitemap{
+				Index:    idx,
+				Resolver: &wikilink.UnpublishedResolver{Pages: pages},
+				BaseURL:  "https://example.com/docs/",
+			},
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/docs/Virtual.html</loc>
+  </url>
+  <url>
+    <loc>https://example.com/docs/index.html</loc>
+    <lastmod>2024-03-01</lastmod>
+  </url>
+  <url>
+    <loc>https://example.com/docs/notes/Foo%20Bar.html</loc>
+    <lastmod>2024-01-16</lastmod>
+  </url>
+</urlset>
+`,
+		},
+		{
+			desc: "absolute resolver",
+			give: wikilink.Sitemap{
+				Index:    idx,
+				Resolver: wikilink.NewRootResolver("https://example.com/"),
+			},
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/Draft/</loc>
+  </url>
+  <url>
+    <loc>https://example.com/Virtual/</loc>
+  </url>
+  <url>
+    <loc>https://example.com/index/</loc>
+    <lastmod>2024-03-01</lastmod>
+  </url>
+  <url>
+    <loc>https://example.com/notes/Foo%20Bar/</loc>
+    <lastmod>2024-01-16</lastmod>
+  </url>
+</urlset>
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			require.NoError(t, tt.give.WriteXML(&buf))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
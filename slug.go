@@ -0,0 +1,190 @@
+package wikilink
+
+import (
+	"path"
+	"strings"
+	"unicode"
+)
+
+// SlugResolver resolves wikilinks to URL-friendly slugs of their targets.
+//
+// Each "/"-separated component of the target and the fragment are
+// lowercased, and runs of spaces and punctuation are replaced with a "-".
+// Targets with extensions other than ".md", like images, are left as-is.
+//
+//	r := &SlugResolver{Suffix: "/"}
+//	[[Foo Bar]]               // => "foo-bar/"
+//	[[Notes/Go's API#Errors]] // => "notes/go-s-api/#errors"
+//	[[diagram.png]]           // => "diagram.png"
+//
+// The zero value is a valid SlugResolver that only keeps ASCII letters and
+// digits and does not add a suffix.
+type SlugResolver struct {
+	// Suffix is added to the end of slugged targets, e.g. "/" or ".html".
+	Suffix string
+
+	// Unicode specifies whether non-ASCII letters and digits,
+	// such as Chinese or Japanese characters, are kept in slugs.
+	// By default, they're dropped, which makes titles written entirely
+	// in these scripts collapse to empty slugs.
+	//
+	//	[[数据库 设计]]  // => "/" by default, "数据库-设计/" with Unicode
+	//
+	// Renderer percent-encodes them in the final URL.
+	Unicode bool
+
+	// FoldDiacritics specifies whether accented Latin letters are
+	// replaced with their closest ASCII equivalents before slugging,
+	// like most static site generators do.
+	//
+	//	[[Café Notes]] // => "caf-notes" by default, "cafe-notes" with FoldDiacritics
+	//	[[Straße]]     // => "stra-e" by default, "strasse" with FoldDiacritics
+	//
+	// This takes precedence over Unicode for the letters it folds.
+	FoldDiacritics bool
+
+	// Slugify, if set, replaces the built-in slug algorithm so that
+	// links exactly match the URLs generated by a static site generator.
+	// It's called with each "/"-separated component of the target
+	// and with the fragment.
+	// Unicode and FoldDiacritics are ignored if this is set.
+	//
+	//	r := &SlugResolver{
+	//		Suffix:  "/",
+	//		Slugify: func(s string) string {
+	//			return strings.ReplaceAll(strings.ToLower(s), " ", "_")
+	//		},
+	//	}
+	//	[[Foo Bar#Some Heading]] // => "foo_bar/#some_heading"
+	Slugify func(string) string
+}
+
+var _ Resolver = (*SlugResolver)(nil)
+
+// ResolveWikilink resolves the provided wikilink to a slug.
+func (r *SlugResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	slugify := r.Slugify
+	if slugify == nil {
+		slugify = slugger{unicode: r.Unicode, fold: r.FoldDiacritics}.slugify
+	}
+
+	b := DestBuilder{Path: n.Target, Query: n.Query}
+	if len(n.Target) > 0 {
+		target := string(n.Target)
+		switch ext := path.Ext(target); ext {
+		case "", ".md":
+			// Add the suffix even if the slug is empty
+			// so that the link still points to a page.
+			b.Path = []byte(mapPath(strings.TrimSuffix(target, ext), slugify) + r.Suffix)
+		}
+	}
+	if len(n.Fragment) > 0 {
+		b.Fragment = []byte(slugify(string(n.Fragment)))
+	}
+	return b.Build(), nil
+}
+
+// slugger turns strings into URL-friendly slugs.
+type slugger struct {
+	// unicode keeps non-ASCII letters and digits.
+	unicode bool
+
+	// fold replaces accented Latin letters with ASCII.
+	fold bool
+}
+
+// slugify turns s into a URL-friendly slug:
+// letters and digits are lowercased and kept,
+// and runs of all other characters become a single "-".
+//
+//	slugify("Foo Bar!") // => "foo-bar"
+//	slugify("Go's API") // => "go-s-api"
+func (s slugger) slugify(str string) string {
+	var sb strings.Builder
+	sb.Grow(len(str))
+
+	dash := false // whether a dash is pending
+	for _, r := range str {
+		if s.fold {
+			if ascii, ok := _foldedLetters[unicode.ToLower(r)]; ok {
+				if dash && sb.Len() > 0 {
+					sb.WriteByte('-')
+				}
+				dash = false
+				sb.WriteString(ascii)
+				continue
+			}
+		}
+
+		if s.keep(r) {
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			dash = false
+			sb.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		dash = true
+	}
+	return sb.String()
+}
+
+// keep reports whether r should be kept in a slug.
+func (s slugger) keep(r rune) bool {
+	if r < unicode.MaxASCII {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	return s.unicode && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r))
+}
+
+// slugifyPath slugifies each "/"-separated component of p.
+func (s slugger) slugifyPath(p string) string {
+	return mapPath(p, s.slugify)
+}
+
+// mapPath applies f to each "/"-separated component of p.
+func mapPath(p string, f func(string) string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		parts[i] = f(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// slugify slugifies str with the default slugger.
+func slugify(str string) string {
+	return slugger{}.slugify(str)
+}
+
+// slugifyPath slugifies p with the default slugger.
+func slugifyPath(p string) string {
+	return slugger{}.slugifyPath(p)
+}
+
+// _foldedLetters maps lowercase accented Latin letters
+// to their closest ASCII equivalents.
+var _foldedLetters = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a",
+	'ā': "a", 'ă': "a", 'ą': "a", 'æ': "ae",
+	'ç': "c", 'ć': "c", 'č': "c", 'ĉ': "c", 'ċ': "c",
+	'ď': "d", 'đ': "d", 'ð': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ē': "e", 'ĕ': "e", 'ė': "e", 'ę': "e", 'ě': "e",
+	'ĝ': "g", 'ğ': "g", 'ġ': "g", 'ģ': "g",
+	'ĥ': "h", 'ħ': "h",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ĩ': "i", 'ī': "i", 'ĭ': "i", 'į': "i", 'ı': "i",
+	'ĵ': "j", 'ķ': "k",
+	'ĺ': "l", 'ļ': "l", 'ľ': "l", 'ŀ': "l", 'ł': "l",
+	'ñ': "n", 'ń': "n", 'ņ': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o",
+	'ō': "o", 'ŏ': "o", 'ő': "o", 'œ': "oe",
+	'ŕ': "r", 'ŗ': "r", 'ř': "r",
+	'ś': "s", 'ŝ': "s", 'ş': "s", 'š': "s", 'ș': "s", 'ß': "ss",
+	'ţ': "t", 'ť': "t", 'ŧ': "t", 'ț': "t", 'þ': "th",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u",
+	'ũ': "u", 'ū': "u", 'ŭ': "u", 'ů': "u", 'ű': "u", 'ų': "u",
+	'ŵ': "w",
+	'ý': "y", 'ÿ': "y", 'ŷ': "y",
+	'ź': "z", 'ż': "z", 'ž': "z",
+}
@@ -0,0 +1,120 @@
+package wikilink
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugify(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give        string
+		want        string
+		wantUnicode string
+	}{
+		{give: "foo", want: "foo", wantUnicode: "foo"},
+		{give: "Foo Bar", want: "foo-bar", wantUnicode: "foo-bar"},
+		{give: "  Foo -- Bar!  ", want: "foo-bar", wantUnicode: "foo-bar"},
+		{give: "Go's API v2", want: "go-s-api-v2", wantUnicode: "go-s-api-v2"},
+		{give: "my_note", want: "my-note", wantUnicode: "my-note"},
+		{give: "数据库", want: "", wantUnicode: "数据库"},
+		{give: "数据库 设计", want: "", wantUnicode: "数据库-设计"},
+		{give: "Go 言語の基本", want: "go", wantUnicode: "go-言語の基本"},
+		{give: "Ünïcode Ωmega", want: "n-code-mega", wantUnicode: "ünïcode-ωmega"},
+		{give: "", want: "", wantUnicode: ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, slugify(tt.give), "default")
+			assert.Equal(t, tt.wantUnicode, slugger{unicode: true}.slugify(tt.give), "unicode")
+		})
+	}
+}
+
+func TestSlugify_FoldDiacritics(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give        string
+		want        string
+		wantUnicode string
+	}{
+		{give: "Café Notes", want: "cafe-notes", wantUnicode: "cafe-notes"},
+		{give: "Straße", want: "strasse", wantUnicode: "strasse"},
+		{give: "Ærøskøbing", want: "aeroskobing", wantUnicode: "aeroskobing"},
+		{give: "Łódź, Kraków", want: "lodz-krakow", wantUnicode: "lodz-krakow"},
+		{give: "Crème brûlée!", want: "creme-brulee", wantUnicode: "creme-brulee"},
+		{give: "Ωmega 数据", want: "mega", wantUnicode: "ωmega-数据"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, slugger{fold: true}.slugify(tt.give), "default")
+			assert.Equal(t, tt.wantUnicode, slugger{fold: true, unicode: true}.slugify(tt.give), "unicode")
+		})
+	}
+}
+
+func TestSlugifyPath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "notes/foo-bar", slugifyPath("Notes/Foo Bar"))
+}
+
+func TestSlugResolver(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		resolver *SlugResolver
+		target   string
+		fragment string
+		want     string
+	}{
+		{resolver: &SlugResolver{}, target: "Foo Bar", want: "foo-bar"},
+		{resolver: &SlugResolver{Suffix: "/"}, target: "Notes/Go's API.md", fragment: "Errors", want: "notes/go-s-api/#errors"},
+		{resolver: &SlugResolver{Suffix: "/"}, target: "img/Diagram.png", want: "img/Diagram.png"},
+		{resolver: &SlugResolver{Suffix: "/"}, target: "数据库", want: "/"},
+		{resolver: &SlugResolver{Suffix: "/", Unicode: true}, target: "数据库", fragment: "索引 设计", want: "数据库/#索引-设计"},
+		{resolver: &SlugResolver{Suffix: "/", FoldDiacritics: true}, target: "Café Notes", fragment: "Über", want: "cafe-notes/#uber"},
+		{resolver: &SlugResolver{}, fragment: "Foo Bar", want: "#foo-bar"},
+		{
+			resolver: &SlugResolver{
+				Suffix:  "/",
+				Unicode: true,
+				Slugify: func(s string) string {
+					return strings.ReplaceAll(strings.ToLower(s), " ", "_")
+				},
+			},
+			target:   "Notes/Foo Bar.md",
+			fragment: "Some Heading",
+			want:     "notes/foo_bar/#some_heading",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		name := fmt.Sprintf("%+v %v#%v", *tt.resolver, tt.target, tt.fragment)
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tt.resolver.ResolveWikilink(&Node{
+				Target:   []byte(tt.target),
+				Fragment: []byte(tt.fragment),
+			})
+			require.NoError(t, err, "resolve failed")
+			assert.Equal(t, tt.want, string(got), "result mismatch")
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package wikilink
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+
+	"github.com/yuin/goldmark/util"
+)
+
+// InlineSVG is an EmbedHandler that reads embedded SVG files
+// and places their markup directly into the document
+// instead of referencing them with an <img> tag.
+// This lets CSS from the page, including currentColor and
+// custom properties, style the diagram.
+//
+//	&wikilink.Extender{
+//		EmbedHandlers: map[string]wikilink.EmbedHandler{
+//			".svg": &wikilink.InlineSVG{FS: os.DirFS("public")},
+//		},
+//	}
+//
+// The markup is sanitized before it's inlined:
+// only SVG elements and attributes known to be safe are kept,
+// so <script>, <style>, <foreignObject>, HTML elements,
+// and event handler attributes are removed.
+// Links may only point to web pages and email addresses,
+// images only to web images,
+// and all other references, like those of <use>, only inside the SVG.
+// Files whose root element isn't <svg> fail to render.
+type InlineSVG struct {
+	// FS holds the SVG files.
+	//
+	// Files are opened at the destination returned by the Resolver,
+	// without its query, fragment, or leading "/".
+	FS fs.FS
+}
+
+var _ EmbedHandler = (*InlineSVG)(nil)
+
+// RenderEmbed writes the sanitized contents of the SVG file for n.
+func (s *InlineSVG) RenderEmbed(w util.BufWriter, _ []byte, n *Node, dest []byte) error {
+	name := embedPath(dest)
+	if !fs.ValidPath(name) {
+		return fmt.Errorf("invalid SVG path %q", name)
+	}
+
+	f, err := s.FS.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return sanitizeSVG(w, f)
+}
+
+// _svgElements are the elements kept in inlined SVGs, by lowercase name.
+// Everything else, including <script>, <style>, <foreignObject>,
+// and HTML elements, is removed with its contents.
+var _svgElements = wordSet(`
+	svg g defs desc title symbol use image switch a view
+	path rect circle ellipse line polyline polygon
+	text tspan textpath
+	lineargradient radialgradient stop pattern clippath mask marker
+	filter feblend fecolormatrix fecomponenttransfer fecomposite
+	feconvolvematrix fediffuselighting fedisplacementmap fedistantlight
+	fedropshadow feflood fefunca fefuncb fefuncg fefuncr fegaussianblur
+	feimage femerge femergenode femorphology feoffset fepointlight
+	fespecularlighting fespotlight fetile feturbulence
+	animate animatemotion animatetransform set mpath
+`)
+
+// _svgAttrs are the attributes without a namespace prefix
+// kept in inlined SVGs, by lowercase name.
+// href is checked separately.
+var _svgAttrs = wordSet(`
+	id class style lang role tabindex target
+
+	x y x1 y1 x2 y2 cx cy r rx ry fx fy fr dx dy width height
+	d points pathlength viewbox preserveaspectratio transform
+	transform-origin version
+	gradienttransform gradientunits spreadmethod offset
+	patternunits patterncontentunits patterntransform
+	markerwidth markerheight markerunits refx refy orient
+	maskunits maskcontentunits clippathunits
+	rotate textlength lengthadjust startoffset method spacing side
+
+	fill fill-opacity fill-rule stroke stroke-width stroke-opacity
+	stroke-linecap stroke-linejoin stroke-miterlimit stroke-dasharray
+	stroke-dashoffset opacity color display visibility overflow
+	clip-path clip-rule mask filter marker-start marker-mid marker-end
+	stop-color stop-opacity flood-color flood-opacity lighting-color
+	font-family font-size font-size-adjust font-weight font-style
+	font-variant font-stretch letter-spacing word-spacing
+	text-anchor text-decoration dominant-baseline alignment-baseline
+	baseline-shift writing-mode direction unicode-bidi
+	shape-rendering text-rendering image-rendering color-interpolation
+	color-interpolation-filters vector-effect paint-order pointer-events
+
+	filterunits primitiveunits in in2 result stddeviation operator
+	k1 k2 k3 k4 mode type values tablevalues slope intercept amplitude
+	exponent scale xchannelselector ychannelselector basefrequency
+	numoctaves seed stitchtiles radius kernelmatrix kernelunitlength
+	order divisor bias targetx targety edgemode preservealpha
+	surfacescale specularconstant specularexponent diffuseconstant
+	azimuth elevation pointsatx pointsaty pointsatz limitingconeangle
+
+	attributename attributetype begin dur end min max restart
+	repeatcount repeatdur calcmode keytimes keysplines keypoints
+	from to by additive accumulate path
+`)
+
+// _svgNamespaces are the namespaces that inlined SVGs may declare.
+var _svgNamespaces = map[string]string{
+	"xmlns":       "http://www.w3.org/2000/svg",
+	"xmlns:xlink": "http://www.w3.org/1999/xlink",
+}
+
+func wordSet(words string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, w := range strings.Fields(words) {
+		set[w] = struct{}{}
+	}
+	return set
+}
+
+// sanitizeSVG copies the SVG in r to w, dropping the XML prolog,
+// comments, and every element and attribute that isn't known to be safe.
+// It fails if the root element isn't <svg>.
+func sanitizeSVG(w io.Writer, r io.Reader) error {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+
+	// open holds the names of the elements we're inside of,
+	// or "" for those that were dropped,
+	// and skip is the depth of nesting inside a dropped element.
+	var open []string
+	var skip int
+	var done bool // whether the root element was closed
+	for {
+		tok, err := d.RawToken()
+		if errors.Is(err, io.EOF) {
+			// Close elements left open by a truncated document.
+			for i := len(open) - 1; i >= 0; i-- {
+				if len(open[i]) > 0 {
+					_, _ = io.WriteString(w, "</"+open[i]+">")
+				}
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("parse SVG: %w", err)
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if len(open) == 0 && !done && (len(tok.Name.Space) > 0 || tok.Name.Local != "svg") {
+				return fmt.Errorf("parse SVG: root element is <%v>, not <svg>", xmlName(tok.Name))
+			}
+			if skip > 0 || done || !safeSVGElement(tok) {
+				skip++
+				open = append(open, "")
+				continue
+			}
+
+			name := xmlName(tok.Name)
+			open = append(open, name)
+			_, _ = io.WriteString(w, "<"+name)
+			for _, attr := range tok.Attr {
+				if !safeSVGAttr(tok.Name.Local, attr) {
+					continue
+				}
+				_, _ = io.WriteString(w, " "+xmlName(attr.Name)+`="`)
+				_, _ = w.Write(util.EscapeHTML([]byte(attr.Value)))
+				_, _ = io.WriteString(w, `"`)
+			}
+			_, _ = io.WriteString(w, ">")
+
+		case xml.EndElement:
+			// The decoder doesn't match end tags with start tags,
+			// so close the element we're in, whatever the tag says.
+			if len(open) == 0 {
+				return fmt.Errorf("parse SVG: unexpected </%v>", xmlName(tok.Name))
+			}
+			name := open[len(open)-1]
+			open = open[:len(open)-1]
+			if len(name) == 0 {
+				skip--
+				continue
+			}
+			_, _ = io.WriteString(w, "</"+name+">")
+			if len(open) == 0 {
+				done = true
+			}
+
+		case xml.CharData:
+			// Text outside the root element is only whitespace.
+			if len(open) > 0 && skip == 0 {
+				_, _ = w.Write(util.EscapeHTML(tok))
+			}
+		}
+	}
+}
+
+func xmlName(n xml.Name) string {
+	if len(n.Space) > 0 {
+		return n.Space + ":" + n.Local
+	}
+	return n.Local
+}
+
+// safeSVGElement reports whether the element may be kept
+// in an inlined SVG.
+func safeSVGElement(el xml.StartElement) bool {
+	name := strings.ToLower(el.Name.Local)
+	if _, ok := _svgElements[name]; !ok || len(el.Name.Space) > 0 {
+		return false
+	}
+
+	switch name {
+	case "animate", "set":
+		// <set attributeName="href" to="javascript:..."> bypasses
+		// the checks on href, so don't animate links at all.
+		for _, attr := range el.Attr {
+			if strings.EqualFold(attr.Name.Local, "attributeName") {
+				v := strings.ToLower(strings.TrimSpace(attr.Value))
+				if v == "href" || strings.HasSuffix(v, ":href") {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// safeSVGAttr reports whether attr of the element el
+// may be kept in an inlined SVG.
+func safeSVGAttr(el string, attr xml.Attr) bool {
+	name := strings.ToLower(attr.Name.Local)
+	switch strings.ToLower(attr.Name.Space) {
+	case "":
+		if ns, ok := _svgNamespaces[name]; ok {
+			return attr.Value == ns
+		}
+		if name == "href" {
+			return safeSVGHref(el, attr.Value)
+		}
+		if _, ok := _svgAttrs[name]; !ok && !strings.HasPrefix(name, "aria-") {
+			return false
+		}
+	case "xmlns":
+		return attr.Value == _svgNamespaces["xmlns:"+name]
+	case "xlink":
+		return name == "href" && safeSVGHref(el, attr.Value)
+	case "xml":
+		if name != "space" && name != "lang" {
+			return false
+		}
+	default:
+		return false // inkscape:label and the like
+	}
+
+	return safeCSSValue(attr.Value)
+}
+
+// safeSVGHref reports whether href is a safe link for the element el.
+//
+// Links (<a>) may point to web pages and email addresses,
+// and images to web images, but all other references,
+// like those of <use> and gradients, must point inside the document.
+func safeSVGHref(el, href string) bool {
+	href = stripURLSpace(href)
+	if strings.HasPrefix(href, "#") {
+		return true
+	}
+
+	var scheme string
+	if i := strings.IndexAny(href, ":/?#"); i >= 0 && href[i] == ':' {
+		scheme = strings.ToLower(href[:i])
+	}
+
+	switch strings.ToLower(el) {
+	case "a":
+		switch scheme {
+		case "", "http", "https", "mailto":
+			return true
+		}
+	case "image", "feimage":
+		switch scheme {
+		case "", "http", "https":
+			return true
+		case "data":
+			lower := strings.ToLower(href)
+			for _, mime := range []string{"png", "jpeg", "gif", "webp"} {
+				if strings.HasPrefix(lower, "data:image/"+mime) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// safeCSSValue reports whether v, the value of an attribute that
+// may hold CSS, like style or fill, is safe to keep.
+// References with url(...) must point inside the document.
+func safeCSSValue(v string) bool {
+	v = strings.ToLower(stripURLSpace(v))
+	for _, bad := range []string{"javascript:", "vbscript:", "expression(", "@import", "behavior:", "-moz-binding"} {
+		if strings.Contains(v, bad) {
+			return false
+		}
+	}
+
+	for rest := v; ; {
+		i := strings.Index(rest, "url(")
+		if i < 0 {
+			return true
+		}
+		rest = strings.TrimLeft(rest[i+len("url("):], `"'`)
+		if !strings.HasPrefix(rest, "#") {
+			return false
+		}
+	}
+}
+
+// stripURLSpace removes whitespace and control characters from s,
+// which browsers ignore inside URLs: "java\tscript:" is "javascript:".
+func stripURLSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,196 @@
+package wikilink
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestInlineSVG(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"diagram.svg": {Data: []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<!-- Generated by hand -->
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 10 10"><path fill="currentColor" d="M0 0h10v10z"/><text>a &lt; b</text></svg>`)},
+		"img/evil.svg": {Data: []byte(`<svg onload="alert(1)">` +
+			`<script>alert(2)</script>` +
+			`<foreignObject><div><script>alert(3)</script></div></foreignObject>` +
+			`<a xlink:href="java&#9;script:alert(4)"><rect/></a>` +
+			`<a href="https://example.com"><rect onclick="alert(5)"/></a>` +
+			`<set attributeName="href" to="javascript:alert(6)"/>` +
+			`</svg>`)},
+		"html.svg": {Data: []byte(`<svg><p><iframe srcdoc="&lt;script&gt;alert(document.domain)&lt;/script&gt;"></iframe></p>` +
+			`<base href="https://evil.example/"/><meta http-equiv="refresh" content="0;url=https://evil.example/"/>` +
+			`<style>@import url(https://evil.example/x.css);</style><div>text</div></svg>`)},
+		"refs.svg": {Data: []byte(`<svg>` +
+			`<defs><linearGradient id="g"><stop offset="0" stop-color="red"/></linearGradient></defs>` +
+			`<rect fill="url(#g)"/>` +
+			`<rect fill="url(https://evil.example/track)"/>` +
+			`<rect style="fill: red"/>` +
+			`<rect style="background: url('https://evil.example/track')"/>` +
+			`<use href="#g"/>` +
+			`<use href="https://evil.example/sprite.svg#icon"/>` +
+			`<use xlink:href="data:image/svg+xml;base64,PHN2Zz4="/>` +
+			`<image href="cat.png"/>` +
+			`<image href="data:image/png;base64,AAAA"/>` +
+			`<image href="data:text/html;base64,PHNjcmlwdD4="/>` +
+			`<a href="mailto:a@example.com"/>` +
+			`<a href="vbscript:msgbox(1)"/>` +
+			`<a href=" JaVaScRiPt:alert(1)"/>` +
+			`<animate attributeName="x" to="10"/>` +
+			`<animate attributeName="xlink:href" values="javascript:alert(1)"/>` +
+			`</svg>`)},
+		"inkscape.svg": {Data: []byte(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:inkscape="http://www.inkscape.org/namespaces/inkscape" width="10">` +
+			`<sodipodi:namedview pagecolor="#fff"/>` +
+			`<g inkscape:label="Layer 1" xml:space="preserve" aria-label="box"><rect/></g></svg>`)},
+		"notsvg.svg": {Data: []byte(`<html><body><script>alert(1)</script></body></html>`)},
+	}
+
+	tests := []struct {
+		desc string
+		give string
+		want string
+	}{
+		{
+			desc: "inline",
+			give: "![[diagram.svg]]",
+			want: `<p><svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 10 10"><path fill="currentColor" d="M0 0h10v10z"></path><text>a &lt; b</text></svg></p>`,
+		},
+		{
+			desc: "sanitized",
+			give: "![[/img/evil.svg#frag]]",
+			want: `<p><svg><a><rect></rect></a><a href="https://example.com"><rect></rect></a></svg></p>`,
+		},
+		{
+			desc: "html",
+			give: "![[html.svg]]",
+			want: `<p><svg></svg></p>`,
+		},
+		{
+			desc: "references",
+			give: "![[refs.svg]]",
+			want: `<p><svg><defs><linearGradient id="g"><stop offset="0" stop-color="red"></stop></linearGradient></defs>` +
+				`<rect fill="url(#g)"></rect><rect></rect><rect style="fill: red"></rect><rect></rect>` +
+				`<use href="#g"></use><use></use><use></use>` +
+				`<image href="cat.png"></image><image href="data:image/png;base64,AAAA"></image><image></image>` +
+				`<a href="mailto:a@example.com"></a><a></a><a></a>` +
+				`<animate attributeName="x" to="10"></animate></svg></p>`,
+		},
+		{
+			desc: "metadata",
+			give: "![[inkscape.svg]]",
+			want: `<p><svg xmlns="http://www.w3.org/2000/svg" width="10"><g xml:space="preserve" aria-label="box"><rect></rect></g></svg></p>`,
+		},
+		{
+			desc: "link",
+			give: "[[diagram.svg]]",
+			want: `<p><a href="diagram.svg">diagram.svg</a></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			md := goldmark.New(goldmark.WithExtensions(&Extender{
+				EmbedHandlers: map[string]EmbedHandler{
+					".svg": &InlineSVG{FS: files},
+				},
+			}))
+
+			var buf bytes.Buffer
+			require.NoError(t, md.Convert([]byte(tt.give), &buf))
+			assert.Equal(t, tt.want, strings.TrimSpace(buf.String()))
+		})
+	}
+
+	t.Run("not an SVG", func(t *testing.T) {
+		t.Parallel()
+
+		md := goldmark.New(goldmark.WithExtensions(&Extender{
+			EmbedHandlers: map[string]EmbedHandler{
+				".svg": &InlineSVG{FS: files},
+			},
+		}))
+		var buf bytes.Buffer
+		err := md.Convert([]byte("![[notsvg.svg]]"), &buf)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "root element is <html>")
+		assert.NotContains(t, buf.String(), "script")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+
+		md := goldmark.New(goldmark.WithExtensions(&Extender{
+			EmbedHandlers: map[string]EmbedHandler{
+				".svg": &InlineSVG{FS: files},
+			},
+		}))
+		err := md.Convert([]byte("![[missing.svg]]"), new(bytes.Buffer))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "missing.svg")
+	})
+}
+
+func TestSanitizeSVG(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc    string
+		give    string
+		want    string
+		wantErr string
+	}{
+		{
+			desc: "mismatched end tags",
+			give: `<svg><g><script>alert(1)</g></script></svg>`,
+			want: `<svg><g></g></svg>`,
+		},
+		{
+			desc: "end tag of dropped element",
+			give: `<svg><foreignObject></svg></foreignObject><rect/>`,
+			want: `<svg></svg>`,
+		},
+		{
+			desc: "truncated",
+			give: `<svg><g><rect>`,
+			want: `<svg><g><rect></rect></g></svg>`,
+		},
+		{
+			desc:    "leading end tag",
+			give:    `</x><script>alert(1)</script>`,
+			wantErr: "unexpected </x>",
+		},
+		{
+			desc:    "end tag after root",
+			give:    `<svg></svg></svg><script>alert(1)</script>`,
+			wantErr: "unexpected </svg>",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var buf bytes.Buffer
+			err := sanitizeSVG(&buf, strings.NewReader(tt.give))
+			if len(tt.wantErr) > 0 {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.NotContains(t, buf.String(), "script")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package wikilink
+
+import "fmt"
+
+// resolveTrace collects the steps taken to resolve a wikilink.
+type resolveTrace struct {
+	steps []string
+}
+
+// Tracef records a step taken while resolving this node,
+// like a cache hit or a fallback to another resolver,
+// if the Renderer's Trace option is set. It does nothing otherwise.
+//
+// Custom resolvers can call this to explain their decisions.
+//
+//	if dest, ok := r.aliases[string(n.Target)]; ok {
+//		n.Tracef("alias %q", dest)
+//		return dest, nil
+//	}
+//	n.Tracef("no alias, falling back")
+func (n *Node) Tracef(format string, args ...any) {
+	if n.trace == nil {
+		return
+	}
+	n.trace.steps = append(n.trace.steps, fmt.Sprintf(format, args...))
+}
+
+// Trace returns the steps recorded with Tracef while this node was last
+// resolved, in order, or nil if the Renderer's Trace option isn't set.
+//
+// Use it from an Observer to see why a wikilink resolved the way it did.
+func (n *Node) Trace() []string {
+	if n.trace == nil {
+		return nil
+	}
+	return n.trace.steps
+}
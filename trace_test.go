@@ -0,0 +1,87 @@
+package wikilink
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/yuin/goldmark"
+)
+
+func TestTrace(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc  string
+		trace bool
+		give  string
+		want  [][]string
+	}{
+		{
+			desc: "disabled",
+			give: "[[关于]]",
+			want: [][]string{nil},
+		},
+		{
+			desc:  "chain",
+			trace: true,
+			give:  "[[关于]] [[关于]] [[other]] [[https://example.com]]",
+			want: [][]string{
+				{
+					"cache miss",
+					`translated "关于" to "about"`,
+					`matched Notion export "about 3fa9c0d1e2f34a5b6c7d8e9f0a1b2c3d"`,
+				},
+				{"cache hit"},
+				{
+					"cache miss",
+					`no translation for "other"`,
+					`no Notion export for "other"`,
+				},
+				{"URL resolver"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var (
+				mu     sync.Mutex
+				traces [][]string
+			)
+			md := goldmark.New(goldmark.WithExtensions(&Extender{
+				Resolver: NewCachedResolver(8, NewTranslationResolver(
+					map[string]string{"关于": "about"},
+					NewNotionResolver([]string{"about 3fa9c0d1e2f34a5b6c7d8e9f0a1b2c3d.md"}, DefaultResolver),
+				)),
+				Trace: tt.trace,
+				Observer: func(n *Node, _ []byte, _ error) {
+					mu.Lock()
+					defer mu.Unlock()
+					traces = append(traces, n.Trace())
+				},
+			}))
+
+			require.NoError(t, md.Convert([]byte(tt.give), new(bytes.Buffer)))
+			assert.Equal(t, tt.want, traces)
+		})
+	}
+}
+
+func TestNode_Tracef(t *testing.T) {
+	t.Parallel()
+
+	var n Node
+	n.Tracef("ignored %d", 1)
+	assert.Nil(t, n.Trace())
+
+	n.trace = new(resolveTrace)
+	n.Tracef("step %d", 1)
+	n.withTarget([]byte("copy")).Tracef("step %d", 2)
+	assert.Equal(t, []string{"step 1", "step 2"}, n.Trace())
+}
@@ -0,0 +1,61 @@
+package wikilink
+
+import "bytes"
+
+// DefaultURLSchemes is the default list of URL schemes that mark the target
+// of a wikilink as an absolute URL.
+//
+//	[[https://example.com|Example]]
+//	[[mailto:someone@example.com]]
+var DefaultURLSchemes = []string{"http", "https", "ftp", "mailto", "tel"}
+
+// hasURLScheme reports whether the target starts with one of the provided
+// URL schemes followed by a ":".
+func hasURLScheme(target []byte, schemes []string) bool {
+	idx := bytes.IndexByte(target, ':')
+	if idx <= 0 {
+		return false
+	}
+
+	scheme := target[:idx]
+	for _, s := range schemes {
+		if bytes.EqualFold(scheme, []byte(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSchemeOrAuthority reports whether dest is an absolute URL,
+// like "https://example.com/" or "obsidian://open",
+// or a network-path reference, like "//cdn.example.com/".
+func hasSchemeOrAuthority(dest []byte) bool {
+	if bytes.HasPrefix(dest, []byte("//")) && !bytes.HasPrefix(dest, []byte("///")) {
+		return true
+	}
+
+	// scheme = ALPHA *( ALPHA / DIGIT / "+" / "-" / "." ) ":"
+	for i, c := range dest {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && ('0' <= c && c <= '9' || c == '+' || c == '-' || c == '.'):
+		case i > 0 && c == ':':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// urlResolver resolves wikilinks to their targets as-is.
+// This is used for targets that are already absolute URLs.
+type urlResolver struct{}
+
+func (urlResolver) ResolveWikilink(n *Node) ([]byte, error) {
+	return DestBuilder{
+		Path:     n.Target,
+		Query:    n.Query,
+		Fragment: n.Fragment,
+	}.Build(), nil
+}
@@ -0,0 +1,47 @@
+package wikilink
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasURLScheme(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		give string
+		want bool
+	}{
+		{give: "https://example.com", want: true},
+		{give: "HTTP://example.com", want: true},
+		{give: "mailto:someone@example.com", want: true},
+		{give: "tel:+15555555555", want: true},
+		{give: "foo"},
+		{give: "Note: foo"},
+		{give: ":foo"},
+		{give: "javascript:alert(1)"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.give, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, hasURLScheme([]byte(tt.give), DefaultURLSchemes))
+		})
+	}
+}
+
+func TestURLResolver(t *testing.T) {
+	t.Parallel()
+
+	got, err := urlResolver{}.ResolveWikilink(&Node{
+		Target:   []byte("https://example.com/foo"),
+		Query:    []byte("bar=baz"),
+		Fragment: []byte("qux"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/foo?bar=baz#qux", string(got))
+}
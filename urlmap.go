@@ -0,0 +1,69 @@
+package wikilink
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// URLMap maps the identifiers of notes to their canonical URLs:
+// the destinations that wikilinks to them resolve to.
+// Identifiers are the paths of the notes without the ".md" extension,
+// the way they're written as wikilink targets.
+//
+// Use it to share URLs with other parts of a build,
+// like RSS feeds or OpenGraph tags,
+// so that they match the wikilinks exactly.
+//
+//	urls, err := wikilink.BuildURLMap(os.DirFS("content"), resolver)
+//	feedItem.Link = urls["posts/hello"]
+type URLMap map[string]string
+
+// BuildURLMap resolves every Markdown file in fsys with r,
+// or DefaultResolver if r is nil, and returns the URLMap of the results.
+// Notes that don't resolve to a destination, like drafts with
+// UnpublishedResolver, are left out.
+func BuildURLMap(fsys fs.FS, r Resolver) (URLMap, error) {
+	urls := make(URLMap)
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(path.Ext(p), ".md") {
+			return nil
+		}
+
+		id := p[:len(p)-len(".md")]
+		dest, err := Resolve(id, "", r)
+		if err != nil {
+			return fmt.Errorf("%v: %w", p, err)
+		}
+		if len(dest) > 0 {
+			urls[id] = dest
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return urls, nil
+}
+
+// Lookup returns the URL of the note with the given identifier,
+// with or without a ".md" extension.
+func (m URLMap) Lookup(id string) (string, bool) {
+	url, ok := m[strings.TrimSuffix(id, ".md")]
+	return url, ok
+}
+
+// WriteJSON writes the map to w as a JSON object
+// with its identifiers in lexical order.
+func (m URLMap) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(map[string]string(m))
+}
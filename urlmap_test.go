@@ -0,0 +1,65 @@
+package wikilink
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildURLMap(t *testing.T) {
+	t.Parallel()
+
+	files := fstest.MapFS{
+		"index.md":       {},
+		"posts/hello.md": {},
+		"posts/draft.md": {},
+		"posts/cat.png":  {},
+	}
+
+	urls, err := BuildURLMap(files, &UnpublishedResolver{
+		Pages: map[string]PageMeta{"posts/draft": {Draft: true}},
+		Next:  NewRootResolver("/blog/"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, URLMap{
+		"index":       "/blog/index/",
+		"posts/hello": "/blog/posts/hello/",
+	}, urls)
+
+	url, ok := urls.Lookup("posts/hello.md")
+	assert.True(t, ok)
+	assert.Equal(t, "/blog/posts/hello/", url)
+
+	_, ok = urls.Lookup("posts/draft")
+	assert.False(t, ok)
+
+	var buf bytes.Buffer
+	require.NoError(t, urls.WriteJSON(&buf))
+	assert.Equal(t, `{
+  "index": "/blog/index/",
+  "posts/hello": "/blog/posts/hello/"
+}
+`, buf.String())
+}
+
+func TestBuildURLMap_Default(t *testing.T) {
+	t.Parallel()
+
+	urls, err := BuildURLMap(fstest.MapFS{"a & b.md": {}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, URLMap{"a & b": "a & b.html"}, urls)
+}
+
+func TestBuildURLMap_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := BuildURLMap(fstest.MapFS{"foo.md": {}}, resolverFunc(func(n *Node) ([]byte, error) {
+		return nil, &TargetNotFoundError{Node: n}
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "foo.md")
+	assert.ErrorIs(t, err, ErrTargetNotFound)
+}